@@ -0,0 +1,119 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/linode/linode-cosi-driver/pkg/audit"
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/pkg/logutils"
+	"github.com/linode/linode-cosi-driver/pkg/version"
+)
+
+// cmdAudit is the subcommand auditing the object storage keys of the account.
+const cmdAudit = "audit"
+
+var ErrAuditUsage = errors.New("usage: linode-cosi-driver audit [-remediate] [-live-grants id,...]")
+
+// auditKeys audits the object storage keys of the account, and writes the JSON report to out.
+// The live grants are the account IDs of the BucketAccess objects, separated by commas or spaces.
+func auditKeys(ctx context.Context, log *slog.Logger, opts mainOptions, out io.Writer, args []string) error {
+	var (
+		remediate  bool
+		liveGrants string
+	)
+
+	flags := flag.NewFlagSet(cmdAudit, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.BoolVar(&remediate, "remediate", opts.keyAuditRemediate, "delete orphaned and stale keys")
+	flags.StringVar(&liveGrants, "live-grants", "", "account IDs of the live BucketAccess objects")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %w", ErrAuditUsage, err)
+	}
+	if flags.NArg() != 0 {
+		return ErrAuditUsage
+	}
+
+	auditOpts := []audit.Option{audit.WithLogger(log), audit.WithRemediation(remediate)}
+
+	if flagSet(flags, "live-grants") {
+		ids, err := parseKeyIDs(liveGrants)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrAuditUsage, err)
+		}
+
+		// An empty list would have every key granted by the driver deleted as stale.
+		if len(ids) == 0 && remediate {
+			return fmt.Errorf("%w: -live-grants must not be empty with -remediate", ErrAuditUsage)
+		}
+
+		auditOpts = append(auditOpts, audit.WithLiveGrants(ids...))
+	}
+
+	client, err := linodeclient.NewLinodeClient(fmt.Sprintf("LinodeCOSI/%s", version.Version))
+	if err != nil {
+		return fmt.Errorf("unable to create new client: %w", err)
+	}
+
+	client.SetLogger(logutils.ForResty(log))
+
+	report, err := audit.New(client, auditOpts...).Audit(ctx)
+	if report.Findings != nil {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+
+		if eerr := enc.Encode(report); eerr != nil {
+			return errors.Join(err, eerr)
+		}
+	}
+
+	return err
+}
+
+// parseKeyIDs parses key IDs separated by commas or spaces.
+func parseKeyIDs(value string) ([]int, error) {
+	fields := strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' })
+
+	ids := make([]int, 0, len(fields))
+	for _, field := range fields {
+		id, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key ID %q", field)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func flagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+
+	return set
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+const (
+	// cmdFreeze is the subcommand cutting off all access to buckets.
+	cmdFreeze = "freeze"
+	// cmdUnfreeze is the subcommand lifting the freeze of buckets.
+	cmdUnfreeze = "unfreeze"
+)
+
+var (
+	ErrFreezeUsage   = errors.New("usage: linode-cosi-driver freeze <bucket-id>...")
+	ErrUnfreezeUsage = errors.New("usage: linode-cosi-driver unfreeze <bucket-id>...")
+)
+
+// freeze cuts off all access to the buckets given as arguments, by bucket ID or in any of the
+// forms accepted for imported buckets, and writes a JSON report of the removed keys for every
+// bucket to out. All buckets are frozen, even if freezing one of them fails.
+func freeze(ctx context.Context, log *slog.Logger, opts mainOptions, out io.Writer, args []string) error {
+	if len(args) == 0 {
+		return ErrFreezeUsage
+	}
+
+	prvSrv, err := commandProvisioner(ctx, log, opts)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	var errs error
+
+	for _, id := range args {
+		report, err := prvSrv.FreezeBucket(ctx, id)
+		if report.Label != "" {
+			if eerr := enc.Encode(report); eerr != nil {
+				errs = errors.Join(errs, eerr)
+			}
+		}
+
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("unable to freeze bucket %q: %w", id, err))
+		}
+	}
+
+	return errs
+}
+
+// unfreeze lifts the freeze of the buckets given as arguments.
+func unfreeze(ctx context.Context, log *slog.Logger, opts mainOptions, args []string) error {
+	if len(args) == 0 {
+		return ErrUnfreezeUsage
+	}
+
+	prvSrv, err := commandProvisioner(ctx, log, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range args {
+		if err := prvSrv.UnfreezeBucket(ctx, id); err != nil {
+			return fmt.Errorf("unable to unfreeze bucket %q: %w", id, err)
+		}
+	}
+
+	return nil
+}
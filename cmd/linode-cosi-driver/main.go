@@ -29,15 +29,19 @@ import (
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
+	"github.com/linode/linodego/v2"
 	"go.uber.org/automaxprocs/maxprocs"
 	"google.golang.org/grpc"
 	cosi "sigs.k8s.io/container-object-storage-interface-spec"
 
+	"github.com/linode/linode-cosi-driver/pkg/deletion"
 	"github.com/linode/linode-cosi-driver/pkg/envflag"
 	grpchandlers "github.com/linode/linode-cosi-driver/pkg/grpc"
 	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
 	"github.com/linode/linode-cosi-driver/pkg/linodeclient/cache"
 	"github.com/linode/linode-cosi-driver/pkg/logutils"
+	"github.com/linode/linode-cosi-driver/pkg/metrics"
+	"github.com/linode/linode-cosi-driver/pkg/rotation"
 	"github.com/linode/linode-cosi-driver/pkg/s3"
 	"github.com/linode/linode-cosi-driver/pkg/servers/identity"
 	"github.com/linode/linode-cosi-driver/pkg/servers/provisioner"
@@ -47,34 +51,123 @@ import (
 const (
 	driverName  = "objectstorage.cosi.linode.com"
 	gracePeriod = 5 * time.Second
+
+	defaultKeyLifetime = 15 * time.Minute
+	keyRevokeTimeout   = 30 * time.Second
 )
 
 var ErrNoKeySpecified = errors.New("no S3 policy credentials, " +
 	"when S3_CLIENT_EPHEMERAL_CREDENTIALS is not set or false " +
-	"you need to provide S3_ACCESS_KEY and S3_SECRET_KEY")
+	"you need to provide S3_ACCESS_KEY and S3_SECRET_KEY, " +
+	"or S3_ACCESS_KEY_<TYPE> and S3_SECRET_KEY_<TYPE> for endpoint types")
+
+var ErrIncompleteKey = errors.New("incomplete S3 credentials, " +
+	"S3_ACCESS_KEY_<TYPE> and S3_SECRET_KEY_<TYPE> must be provided together")
+
+const minSigningKeyLength = 32
+
+var ErrSigningKeyTooShort = fmt.Errorf("BUCKET_ID_SIGNING_KEY must be at least %d bytes long", minSigningKeyLength)
 
 func main() {
 	var (
 		cosiEndpoint           = envflag.String("COSI_ENDPOINT", "unix:///var/lib/cosi/cosi.sock")
 		cacheTTL               = envflag.Duration("LINODE_OBJECT_STORAGE_ENDPOINT_CACHE_TTL", cache.DefaultTTL)
+		cacheFile              = envflag.String("LINODE_OBJECT_STORAGE_ENDPOINT_CACHE_FILE", "")
+		cacheMaxStaleness      = envflag.Duration("LINODE_OBJECT_STORAGE_ENDPOINT_CACHE_MAX_STALENESS", cache.DefaultMaxStaleness)
+		metadataCacheTTL       = envflag.Duration("LINODE_OBJECT_STORAGE_METADATA_CACHE_TTL", cache.DefaultMetadataTTL)
+		metadataCacheSize      = envflag.Int("LINODE_OBJECT_STORAGE_METADATA_CACHE_SIZE", cache.DefaultMetadataSize)
+		metricsAddress         = envflag.String("METRICS_ADDRESS", "")
 		s3SSL                  = envflag.Bool("S3_CLIENT_SSL_ENABLED", true)
 		s3EphemeralCredentials = envflag.Bool("S3_CLIENT_EPHEMERAL_CREDENTIALS", true)
+		s3KeyLifetime          = envflag.Duration("S3_CLIENT_EPHEMERAL_CREDENTIALS_LIFETIME", defaultKeyLifetime)
 		s3AccessKey            = envflag.String("S3_ACCESS_KEY", "")
 		s3SecretKey            = envflag.String("S3_SECRET_KEY", "")
+		s3PruneWorkers         = envflag.Int("S3_CLIENT_PRUNE_WORKERS", s3.DefaultPruneWorkers)
+		account                = envflag.String("LINODE_ACCOUNT", "")
+		signingKey             = envflag.String("BUCKET_ID_SIGNING_KEY", "")
+		bucketAllowlist        = envflag.Strings("BUCKET_ID_ALLOWLIST", nil)
+		importedBucketDeletion = envflag.Bool("IMPORTED_BUCKET_DELETION", false)
+		clusterID              = envflag.String("CLUSTER_ID", "")
+		adoptUnmarkedBuckets   = envflag.Bool("ADOPT_UNMARKED_BUCKETS", false)
+		deletionQueueFile      = envflag.String("DELETION_QUEUE_FILE", "")
+		deletionWorkers        = envflag.Int("DELETION_WORKERS", deletion.DefaultWorkers)
+		deletionMaxObjects     = envflag.Int("DELETION_MAX_OBJECTS", 0)
+		deletionMaxBytes       = envflag.Int("DELETION_MAX_BYTES", 0)
+		deletionLimitOverrides = envflag.Strings("DELETION_LIMIT_OVERRIDES", nil)
+		keyAuditInterval       = envflag.Duration("KEY_AUDIT_INTERVAL", 0)
+		keyAuditRemediate      = envflag.Bool("KEY_AUDIT_REMEDIATE", false)
+		keyReapInterval        = envflag.Duration("KEY_REAP_INTERVAL", time.Minute)
+		rotationPeriod         = envflag.Duration("KEY_ROTATION_PERIOD", 0)
+		rotationOverlap        = envflag.Duration("KEY_ROTATION_OVERLAP", rotation.DefaultOverlap)
+		rotationInterval       = envflag.Duration("KEY_ROTATION_INTERVAL", time.Hour)
 	)
 
+	// static credentials of clusters with separate keys, e.g. S3_ACCESS_KEY_E2 and S3_SECRET_KEY_E2
+	s3TypedCredentials := make(map[linodego.ObjectStorageEndpointType]s3.Credentials)
+	for _, endpointType := range cache.DefaultEndpointTypeOrder {
+		creds := s3.Credentials{
+			AccessKey: envflag.String("S3_ACCESS_KEY_"+string(endpointType), ""),
+			SecretKey: envflag.String("S3_SECRET_KEY_"+string(endpointType), ""),
+		}
+		if creds != (s3.Credentials{}) {
+			s3TypedCredentials[endpointType] = creds
+		}
+	}
+
 	// TODO: any logger settup must be done here, before first log call.
 	log := slog.Default()
 
-	if err := run(context.Background(), log, mainOptions{
+	opts := mainOptions{
 		cosiEndpoint:           cosiEndpoint,
 		cacheTTL:               cacheTTL,
+		cacheFile:              cacheFile,
+		cacheMaxStaleness:      cacheMaxStaleness,
+		metadataCacheTTL:       metadataCacheTTL,
+		metadataCacheSize:      metadataCacheSize,
+		metricsAddress:         metricsAddress,
 		s3SSL:                  s3SSL,
 		s3EphemeralCredentials: s3EphemeralCredentials,
+		s3KeyLifetime:          s3KeyLifetime,
 		s3AccessKey:            s3AccessKey,
 		s3SecretKey:            s3SecretKey,
-	},
-	); err != nil {
+		s3TypedCredentials:     s3TypedCredentials,
+		s3PruneWorkers:         s3PruneWorkers,
+		account:                account,
+		signingKey:             signingKey,
+		bucketAllowlist:        bucketAllowlist,
+		importedBucketDeletion: importedBucketDeletion,
+		clusterID:              clusterID,
+		adoptUnmarkedBuckets:   adoptUnmarkedBuckets,
+		deletionQueueFile:      deletionQueueFile,
+		deletionWorkers:        deletionWorkers,
+		deletionMaxObjects:     deletionMaxObjects,
+		deletionMaxBytes:       deletionMaxBytes,
+		deletionLimitOverrides: deletionLimitOverrides,
+		keyAuditInterval:       keyAuditInterval,
+		keyAuditRemediate:      keyAuditRemediate,
+		keyReapInterval:        keyReapInterval,
+		rotationPeriod:         rotationPeriod,
+		rotationOverlap:        rotationOverlap,
+		rotationInterval:       rotationInterval,
+	}
+
+	var err error
+	switch args := os.Args[1:]; {
+	case len(args) > 0 && args[0] == cmdRestore:
+		err = restore(context.Background(), log, opts, args[1:])
+	case len(args) > 0 && args[0] == cmdMigrate:
+		err = migrate(context.Background(), log, opts, os.Stdout, args[1:])
+	case len(args) > 0 && args[0] == cmdFreeze:
+		err = freeze(context.Background(), log, opts, os.Stdout, args[1:])
+	case len(args) > 0 && args[0] == cmdUnfreeze:
+		err = unfreeze(context.Background(), log, opts, args[1:])
+	case len(args) > 0 && args[0] == cmdAudit:
+		err = auditKeys(context.Background(), log, opts, os.Stdout, args[1:])
+	default:
+		err = run(context.Background(), log, opts)
+	}
+
+	if err != nil {
 		slog.Error("Critical failure", "error", err)
 		os.Exit(1)
 	}
@@ -83,10 +176,35 @@ func main() {
 type mainOptions struct {
 	cosiEndpoint           string
 	cacheTTL               time.Duration
+	cacheFile              string
+	cacheMaxStaleness      time.Duration
+	metadataCacheTTL       time.Duration
+	metadataCacheSize      int
+	metricsAddress         string
 	s3SSL                  bool
 	s3EphemeralCredentials bool
+	s3KeyLifetime          time.Duration
 	s3AccessKey            string
 	s3SecretKey            string
+	s3TypedCredentials     map[linodego.ObjectStorageEndpointType]s3.Credentials
+	s3PruneWorkers         int
+	account                string
+	signingKey             string
+	bucketAllowlist        []string
+	importedBucketDeletion bool
+	clusterID              string
+	adoptUnmarkedBuckets   bool
+	deletionQueueFile      string
+	deletionWorkers        int
+	deletionMaxObjects     int
+	deletionMaxBytes       int
+	deletionLimitOverrides []string
+	keyAuditInterval       time.Duration
+	keyAuditRemediate      bool
+	keyReapInterval        time.Duration
+	rotationPeriod         time.Duration
+	rotationOverlap        time.Duration
+	rotationInterval       time.Duration
 }
 
 func run(ctx context.Context, log *slog.Logger, opts mainOptions) error {
@@ -102,6 +220,10 @@ func run(ctx context.Context, log *slog.Logger, opts mainOptions) error {
 	)
 	defer stop()
 
+	if opts.signingKey != "" && len(opts.signingKey) < minSigningKeyLength {
+		return ErrSigningKeyTooShort
+	}
+
 	// create identity server
 	idSrv, err := identity.New(driverName)
 	if err != nil {
@@ -116,7 +238,29 @@ func run(ctx context.Context, log *slog.Logger, opts mainOptions) error {
 
 	client.SetLogger(logutils.ForResty(log))
 
-	epc := cache.New(log, client, opts.cacheTTL)
+	if opts.metricsAddress != "" {
+		go func() {
+			if err := metrics.Serve(ctx, opts.metricsAddress); err != nil {
+				if !errors.Is(err, context.Canceled) {
+					log.Error("Metrics server failure", "error", err)
+				}
+			}
+		}()
+	}
+
+	var cacheOpts []cache.Option
+	if opts.cacheFile != "" {
+		cacheOpts = append(cacheOpts, cache.WithPersistence(opts.cacheFile, opts.cacheMaxStaleness))
+	}
+
+	epc := cache.New(log, client, opts.cacheTTL, cacheOpts...)
+	if opts.cacheFile != "" {
+		// Load the persisted catalog before serving, so the first requests can be served from it.
+		if err := epc.Load(ctx); err != nil {
+			log.WarnContext(ctx, "Failed to load persisted cache", "error", err, "file", opts.cacheFile)
+		}
+	}
+
 	go func() {
 		if err := epc.Start(ctx); err != nil {
 			if !errors.Is(err, context.Canceled) {
@@ -125,31 +269,133 @@ func run(ctx context.Context, log *slog.Logger, opts mainOptions) error {
 		}
 	}()
 
-	var s3cli s3.Client
-	if !opts.s3EphemeralCredentials {
-		if opts.s3AccessKey == "" || opts.s3SecretKey == "" {
-			return ErrNoKeySpecified
+	regions := cache.NewRegionCache(log, client, epc, opts.cacheTTL)
+	go func() {
+		if err := regions.Start(ctx); err != nil {
+			if !errors.Is(err, context.Canceled) {
+				log.Error("Region cache failure", "error", err)
+			}
+		}
+	}()
+
+	s3cli, err := staticS3Client(log, opts, epc)
+	if err != nil {
+		return err
+	}
+
+	// bucket metadata is cached only for the provisioner, caches above list endpoints and regions
+	var prvClient linodeclient.Client = client
+	if opts.metadataCacheTTL > 0 {
+		prvClient = cache.NewMetadataCache(client, opts.metadataCacheTTL, opts.metadataCacheSize)
+	}
+
+	// ephemeral S3 credentials are shared by operations, and revoked at shutdown
+	keys := linodeclient.NewKeyPool(log, prvClient, opts.s3KeyLifetime)
+	go func() {
+		if err := keys.Start(ctx); err != nil {
+			if !errors.Is(err, context.Canceled) {
+				log.Error("Key pool failure", "error", err)
+			}
+		}
+	}()
+	defer func() {
+		rctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), keyRevokeTimeout)
+		defer cancel()
+
+		if err := keys.Close(rctx); err != nil {
+			log.Error("Failed to revoke ephemeral S3 credentials", "error", err)
 		}
+	}()
 
-		s3cli = s3.New(
-			epc,
-			opts.s3AccessKey, opts.s3SecretKey,
-			opts.s3SSL,
-		)
+	prvOpts := []provisioner.Option{
+		provisioner.WithAccount(opts.account),
+		provisioner.WithRegions(regions),
+		provisioner.WithSigningKey([]byte(opts.signingKey)),
+		provisioner.WithBucketAllowlist(opts.bucketAllowlist...),
+		provisioner.WithImportedBucketDeletion(opts.importedBucketDeletion),
+		provisioner.WithClusterID(opts.clusterID),
+		provisioner.WithUnmarkedBucketAdoption(opts.adoptUnmarkedBuckets),
+		provisioner.WithKeyPool(keys),
+		provisioner.WithS3ClientOptions(s3.WithPruneWorkers(opts.s3PruneWorkers)),
+		provisioner.WithDeletionLimits(opts.deletionMaxObjects, opts.deletionMaxBytes),
+		provisioner.WithDeletionLimitOverrides(opts.deletionLimitOverrides...),
+		provisioner.WithKeyRotation(opts.rotationPeriod > 0),
+	}
+
+	// buckets with cleanup are deleted in the background when the queue is persisted, so queued
+	// deletions survive restarts
+	var deletions *deletion.Queue
+	if opts.deletionQueueFile != "" {
+		deletions = deletion.New(log, deletion.NewFileStore(opts.deletionQueueFile), deletion.WithWorkers(opts.deletionWorkers))
+
+		// the stored deletions are loaded before serving, as queuing a deletion replaces the file
+		if err := deletions.Load(); err != nil {
+			return fmt.Errorf("failed to load deletion queue: %w", err)
+		}
+
+		prvOpts = append(prvOpts, provisioner.WithDeletionQueue(deletions))
 	}
 
 	// create provisioner server
-	prvSrv, err := provisioner.New(
-		log,
-		client,
-		epc,
-		s3cli,
-		opts.s3SSL,
-	)
+	prvSrv, err := provisioner.New(log, prvClient, epc, s3cli, opts.s3SSL, prvOpts...)
 	if err != nil {
 		return fmt.Errorf("failed to create provisioner server: %w", err)
 	}
 
+	if deletions != nil {
+		go func() {
+			if err := deletions.Start(ctx, prvSrv.ProcessDeletion); err != nil {
+				if !errors.Is(err, context.Canceled) {
+					log.Error("Deletion queue failure", "error", err)
+				}
+			}
+		}()
+	}
+
+	// keys of the account are audited periodically when enabled, live grants are listed through the Kubernetes API
+	if opts.keyAuditInterval > 0 {
+		auditor, err := newAuditor(client, log, opts)
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			if err := auditor.Start(ctx, opts.keyAuditInterval); err != nil {
+				if !errors.Is(err, context.Canceled) {
+					log.Error("Key audit failure", "error", err)
+				}
+			}
+		}()
+	}
+
+	// time-limited keys are deleted once they expired
+	if opts.keyReapInterval > 0 {
+		reaper := linodeclient.NewKeyReaper(log, client)
+		go func() {
+			if err := reaper.Start(ctx, opts.keyReapInterval); err != nil {
+				if !errors.Is(err, context.Canceled) {
+					log.Error("Key reaper failure", "error", err)
+				}
+			}
+		}()
+	}
+
+	// keys of BucketAccess objects are rotated when enabled, the Secrets are updated through the Kubernetes API
+	if opts.rotationPeriod > 0 {
+		rotator, err := newRotationController(client, log, opts)
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			if err := rotator.Start(ctx, opts.rotationInterval); err != nil {
+				if !errors.Is(err, context.Canceled) {
+					log.Error("Key rotation failure", "error", err)
+				}
+			}
+		}()
+	}
+
 	// parse endpoint
 	endpointURL, err := url.Parse(opts.cosiEndpoint)
 	if err != nil {
@@ -253,3 +499,46 @@ func shutdown(ctx context.Context,
 		}
 	}
 }
+
+// staticS3Client returns the client using static S3 credentials, or nil when ephemeral credentials are used.
+func staticS3Client(log *slog.Logger, opts mainOptions, epc *cache.EndpointCache) (s3.Client, error) {
+	if opts.s3EphemeralCredentials {
+		return nil, nil //nolint:nilnil // no static client
+	}
+
+	s3Opts, err := staticCredentialOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	s3Opts = append(s3Opts, s3.WithLogger(log), s3.WithPruneWorkers(opts.s3PruneWorkers))
+
+	return s3.New(
+		epc,
+		opts.s3AccessKey, opts.s3SecretKey,
+		opts.s3SSL,
+		s3Opts...,
+	), nil
+}
+
+// staticCredentialOptions validates the static S3 credentials. Default credentials are only
+// required when no credentials of an endpoint type are provided.
+func staticCredentialOptions(opts mainOptions) ([]s3.Option, error) {
+	if (opts.s3AccessKey == "") != (opts.s3SecretKey == "") {
+		return nil, ErrNoKeySpecified
+	}
+
+	if opts.s3AccessKey == "" && len(opts.s3TypedCredentials) == 0 {
+		return nil, ErrNoKeySpecified
+	}
+
+	s3Opts := make([]s3.Option, 0, len(opts.s3TypedCredentials))
+	for endpointType, creds := range opts.s3TypedCredentials {
+		if creds.AccessKey == "" || creds.SecretKey == "" {
+			return nil, fmt.Errorf("%w: %s", ErrIncompleteKey, endpointType)
+		}
+
+		s3Opts = append(s3Opts, s3.WithEndpointTypeCredentials(endpointType, creds))
+	}
+
+	return s3Opts, nil
+}
@@ -21,6 +21,10 @@ import (
 	"log/slog"
 	"testing"
 	"time"
+
+	"github.com/linode/linodego/v2"
+
+	"github.com/linode/linode-cosi-driver/pkg/s3"
 )
 
 func TestRun(t *testing.T) {
@@ -37,6 +41,42 @@ func TestRun(t *testing.T) {
 				func(*mainOptions) { /* noop */ },
 			},
 		},
+		{
+			testName: "short signing key",
+			options: []func(*mainOptions){
+				func(opts *mainOptions) { opts.signingKey = "short" },
+			},
+			expectedError: ErrSigningKeyTooShort,
+		},
+		{
+			testName: "no static credentials",
+			options: []func(*mainOptions){
+				func(opts *mainOptions) { opts.s3AccessKey, opts.s3SecretKey = "", "" },
+			},
+			expectedError: ErrNoKeySpecified,
+		},
+		{
+			testName: "endpoint type credentials only",
+			options: []func(*mainOptions){
+				func(opts *mainOptions) {
+					opts.s3AccessKey, opts.s3SecretKey = "", ""
+					opts.s3TypedCredentials = map[linodego.ObjectStorageEndpointType]s3.Credentials{
+						linodego.ObjectStorageEndpointE2: {AccessKey: "test", SecretKey: "test"},
+					}
+				},
+			},
+		},
+		{
+			testName: "incomplete endpoint type credentials",
+			options: []func(*mainOptions){
+				func(opts *mainOptions) {
+					opts.s3TypedCredentials = map[linodego.ObjectStorageEndpointType]s3.Credentials{
+						linodego.ObjectStorageEndpointE3: {AccessKey: "test"},
+					}
+				},
+			},
+			expectedError: ErrIncompleteKey,
+		},
 	} {
 		tc := tc
 
@@ -73,3 +113,62 @@ func TestRun(t *testing.T) {
 		})
 	}
 }
+
+func TestRestoreUsage(t *testing.T) {
+	t.Parallel()
+
+	noopLog := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	if err := restore(t.Context(), noopLog, mainOptions{}, nil); !errors.Is(err, ErrRestoreUsage) {
+		t.Errorf("expected error: %v, but got: %v", ErrRestoreUsage, err)
+	}
+}
+
+func TestFreezeUsage(t *testing.T) {
+	t.Parallel()
+
+	noopLog := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	if err := freeze(t.Context(), noopLog, mainOptions{}, io.Discard, nil); !errors.Is(err, ErrFreezeUsage) {
+		t.Errorf("expected error: %v, but got: %v", ErrFreezeUsage, err)
+	}
+
+	if err := unfreeze(t.Context(), noopLog, mainOptions{}, nil); !errors.Is(err, ErrUnfreezeUsage) {
+		t.Errorf("expected error: %v, but got: %v", ErrUnfreezeUsage, err)
+	}
+}
+
+func TestAuditUsage(t *testing.T) {
+	t.Parallel()
+
+	noopLog := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, args := range [][]string{
+		{"unexpected"},
+		{"-unknown"},
+		{"-live-grants", "1,abc"},
+		{"-live-grants", "", "-remediate"},
+	} {
+		if err := auditKeys(t.Context(), noopLog, mainOptions{}, io.Discard, args); !errors.Is(err, ErrAuditUsage) {
+			t.Errorf("expected error for %v: %v, but got: %v", args, ErrAuditUsage, err)
+		}
+	}
+}
+
+func TestMigrateUsage(t *testing.T) {
+	t.Parallel()
+
+	noopLog := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, args := range [][]string{
+		nil,
+		{"-region", "us-ord"},
+		{"v2:label=test-bucket&region=us-east"},
+		{"-region", "us-ord", "v2:label=a&region=us-east", "v2:label=b&region=us-east"},
+		{"-unknown", "v2:label=test-bucket&region=us-east"},
+	} {
+		if err := migrate(t.Context(), noopLog, mainOptions{}, io.Discard, args); !errors.Is(err, ErrMigrateUsage) {
+			t.Errorf("expected error for %v: %v, but got: %v", args, ErrMigrateUsage, err)
+		}
+	}
+}
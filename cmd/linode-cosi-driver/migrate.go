@@ -0,0 +1,73 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/linode/linodego/v2"
+
+	"github.com/linode/linode-cosi-driver/pkg/servers/provisioner"
+)
+
+// cmdMigrate is the subcommand migrating buckets to another region, endpoint type or label.
+const cmdMigrate = "migrate"
+
+var ErrMigrateUsage = errors.New(
+	"usage: linode-cosi-driver migrate [-region region] [-endpoint-type type] [-label label] <bucket-id>")
+
+// migrate copies the bucket given as argument, by bucket ID or in any of the forms accepted for
+// imported buckets, to a bucket with the region, endpoint type and label of the flags, and
+// writes the ID of the new bucket to out.
+func migrate(ctx context.Context, log *slog.Logger, opts mainOptions, out io.Writer, args []string) error {
+	var (
+		migrateOpts  provisioner.MigrateOptions
+		endpointType string
+	)
+
+	flags := flag.NewFlagSet(cmdMigrate, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&migrateOpts.Region, "region", "", "region of the new bucket")
+	flags.StringVar(&endpointType, "endpoint-type", "", "endpoint type of the new bucket")
+	flags.StringVar(&migrateOpts.Label, "label", "", "label of the new bucket")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %w", ErrMigrateUsage, err)
+	}
+	if flags.NArg() != 1 || (migrateOpts.Region == "" && endpointType == "" && migrateOpts.Label == "") {
+		return ErrMigrateUsage
+	}
+
+	migrateOpts.EndpointType = linodego.ObjectStorageEndpointType(endpointType)
+
+	prvSrv, err := commandProvisioner(ctx, log, opts)
+	if err != nil {
+		return err
+	}
+
+	id, err := prvSrv.MigrateBucket(ctx, flags.Arg(0), migrateOpts)
+	if err != nil {
+		return fmt.Errorf("unable to migrate bucket %q: %w", flags.Arg(0), err)
+	}
+
+	_, err = fmt.Fprintln(out, id)
+
+	return err
+}
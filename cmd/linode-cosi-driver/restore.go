@@ -0,0 +1,92 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient/cache"
+	"github.com/linode/linode-cosi-driver/pkg/logutils"
+	"github.com/linode/linode-cosi-driver/pkg/servers/provisioner"
+	"github.com/linode/linode-cosi-driver/pkg/version"
+)
+
+// cmdRestore is the subcommand restoring soft-deleted buckets.
+const cmdRestore = "restore"
+
+var ErrRestoreUsage = errors.New("usage: linode-cosi-driver restore <bucket-id>...")
+
+// restore cancels the pending deletion of the soft-deleted buckets given as arguments, by
+// bucket ID or in any of the forms accepted for imported buckets.
+func restore(ctx context.Context, log *slog.Logger, opts mainOptions, args []string) error {
+	if len(args) == 0 {
+		return ErrRestoreUsage
+	}
+
+	prvSrv, err := commandProvisioner(ctx, log, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range args {
+		if err := prvSrv.RestoreBucket(ctx, id); err != nil {
+			return fmt.Errorf("unable to restore bucket %q: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+// commandProvisioner returns the provisioner server used by subcommands. It talks to S3 with
+// ephemeral keys scoped to each bucket, or with the static S3 credentials when ephemeral
+// credentials are disabled, and is not serving gRPC requests.
+func commandProvisioner(ctx context.Context, log *slog.Logger, opts mainOptions) (*provisioner.Server, error) {
+	client, err := linodeclient.NewLinodeClient(fmt.Sprintf("LinodeCOSI/%s", version.Version))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create new client: %w", err)
+	}
+
+	client.SetLogger(logutils.ForResty(log))
+
+	epc := cache.New(log, client, opts.cacheTTL)
+	if err := epc.Refresh(ctx); err != nil {
+		return nil, fmt.Errorf("unable to list object storage endpoints: %w", err)
+	}
+
+	s3cli, err := staticS3Client(log, opts, epc)
+	if err != nil {
+		return nil, err
+	}
+
+	prvSrv, err := provisioner.New(
+		log,
+		client,
+		epc,
+		s3cli,
+		opts.s3SSL,
+		provisioner.WithAccount(opts.account),
+		provisioner.WithClusterID(opts.clusterID),
+		provisioner.WithUnmarkedBucketAdoption(opts.adoptUnmarkedBuckets),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provisioner server: %w", err)
+	}
+
+	return prvSrv, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/linode/linode-cosi-driver/pkg/audit"
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/pkg/rotation"
+)
+
+// newRotationController returns the key rotation controller, using the in-cluster configuration
+// of the service account of the driver.
+func newRotationController(client linodeclient.Client, log *slog.Logger, opts mainOptions) (*rotation.Controller, error) {
+	kube, dyn, err := kubeClients()
+	if err != nil {
+		return nil, fmt.Errorf("key rotation: %w", err)
+	}
+
+	return rotation.New(client, kube, dyn, driverName, opts.rotationPeriod,
+		rotation.WithLogger(log),
+		rotation.WithOverlap(opts.rotationOverlap),
+	), nil
+}
+
+// newAuditor returns the periodic key auditor. The live grants are listed from the BucketAccess
+// objects of the driver, using the in-cluster configuration of the service account of the driver.
+func newAuditor(client linodeclient.Client, log *slog.Logger, opts mainOptions) (*audit.Auditor, error) {
+	kube, dyn, err := kubeClients()
+	if err != nil {
+		return nil, fmt.Errorf("key audit: %w", err)
+	}
+
+	return audit.New(client,
+		audit.WithLogger(log),
+		audit.WithRemediation(opts.keyAuditRemediate),
+		audit.WithLiveGrantsFunc(func(ctx context.Context) ([]int, error) {
+			return rotation.LiveKeyIDs(ctx, kube, dyn, driverName)
+		}),
+	), nil
+}
+
+// kubeClients returns the Kubernetes clients, using the in-cluster configuration.
+func kubeClients() (kubernetes.Interface, dynamic.Interface, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to load in-cluster configuration: %w", err)
+	}
+
+	kube, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create Kubernetes client: %w", err)
+	}
+
+	dyn, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create Kubernetes dynamic client: %w", err)
+	}
+
+	return kube, dyn, nil
+}
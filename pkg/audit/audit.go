@@ -0,0 +1,336 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audit implements the audit of the object storage keys of the account.
+package audit
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/linode/linodego/v2"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/pkg/metrics"
+)
+
+// GrantKeyLabelPrefix is the label prefix of keys granted for BucketAccess objects.
+const GrantKeyLabelPrefix = linodeclient.GrantKeyLabelPrefix
+
+// Kind is the kind of problem found with a key.
+type Kind string
+
+const (
+	// KindUnlimited keys have access to every bucket of the account.
+	KindUnlimited Kind = "unlimited"
+	// KindOrphaned keys have access to buckets that no longer exist.
+	KindOrphaned Kind = "orphaned"
+	// KindDuplicate keys were granted for the same BucketAccess as another key.
+	KindDuplicate Kind = "duplicate"
+	// KindStale keys were granted for a BucketAccess, but are not the account of any live one.
+	KindStale Kind = "stale"
+)
+
+// Kinds are all kinds of findings.
+var Kinds = []Kind{KindUnlimited, KindOrphaned, KindDuplicate, KindStale}
+
+// Finding is a problem found with a key.
+type Finding struct {
+	Kind      Kind   `json:"kind"`
+	KeyID     int    `json:"keyId"`
+	Label     string `json:"label"`
+	AccessKey string `json:"accessKey"`
+	Detail    string `json:"detail"`
+	// Remediated is set when the key was deleted.
+	Remediated bool `json:"remediated,omitempty"`
+}
+
+// Report is the result of an audit.
+type Report struct {
+	Time     time.Time `json:"time"`
+	Keys     int       `json:"keys"`
+	Findings []Finding `json:"findings"`
+}
+
+// LiveGrantsFunc returns the IDs of the keys granted for live BucketAccess objects.
+type LiveGrantsFunc func(ctx context.Context) ([]int, error)
+
+// Auditor audits the object storage keys of the account. Audits must not run concurrently.
+type Auditor struct {
+	log        *slog.Logger
+	client     linodeclient.Client
+	remediate  bool
+	liveGrants LiveGrantsFunc
+
+	// stale are the keys found stale by the previous audit, when the live grants are listed.
+	stale map[int]struct{}
+}
+
+// Option configures the auditor.
+type Option func(*Auditor)
+
+// WithLogger sets the logger of the auditor.
+func WithLogger(log *slog.Logger) Option {
+	return func(a *Auditor) {
+		a.log = log
+	}
+}
+
+// WithRemediation deletes orphaned keys with access to no existing bucket, and stale keys.
+// Unlimited keys are never deleted, and duplicate keys only when they are stale.
+func WithRemediation(remediate bool) Option {
+	return func(a *Auditor) {
+		a.remediate = remediate
+	}
+}
+
+// WithLiveGrants sets the IDs of the keys granted for live BucketAccess objects, i.e. their
+// account IDs. Keys granted for BucketAccess objects are only reported as stale when the
+// live grants are known.
+func WithLiveGrants(ids ...int) Option {
+	return func(a *Auditor) {
+		a.liveGrants = func(context.Context) ([]int, error) {
+			return ids, nil
+		}
+	}
+}
+
+// WithLiveGrantsFunc lists the live grants on every audit, after the keys were listed, see
+// WithLiveGrants. Keys granted while the audit runs may not be the account of their BucketAccess
+// yet, so stale keys are only deleted when the previous audit found them stale too.
+func WithLiveGrantsFunc(fn LiveGrantsFunc) Option {
+	return func(a *Auditor) {
+		a.liveGrants = fn
+		a.stale = make(map[int]struct{})
+	}
+}
+
+// New returns an auditor of the keys of the account of the client.
+func New(client linodeclient.Client, opts ...Option) *Auditor {
+	a := &Auditor{client: client}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	if a.log == nil {
+		a.log = slog.Default()
+	}
+
+	return a
+}
+
+// Start audits the keys every interval until the context is canceled. Findings are logged and
+// reported as metrics.
+func (a *Auditor) Start(ctx context.Context, interval time.Duration) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			report, err := a.Audit(ctx)
+			if err != nil {
+				a.log.ErrorContext(ctx, "Failed to audit object storage keys", "error", err)
+			}
+
+			for _, finding := range report.Findings {
+				a.log.WarnContext(ctx, "Object storage key audit finding",
+					slog.String("kind", string(finding.Kind)),
+					slog.Int("key_id", finding.KeyID),
+					slog.String("key_label", finding.Label),
+					slog.String("detail", finding.Detail),
+					slog.Bool("remediated", finding.Remediated),
+				)
+			}
+
+			timer.Reset(interval)
+		}
+	}
+}
+
+// Audit lists the keys and buckets of the account, and reports the problem keys. Keys are
+// listed before buckets, so that buckets created after the listing of keys cannot be missed.
+// With remediation, the report is returned along with the errors deleting keys.
+func (a *Auditor) Audit(ctx context.Context) (Report, error) {
+	keys, err := a.client.ListObjectStorageKeys(ctx, nil)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to list object storage keys: %w", err)
+	}
+
+	buckets, err := a.client.ListObjectStorageBuckets(ctx, nil)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to list object storage buckets: %w", err)
+	}
+
+	existing := make(map[string]struct{}, len(buckets))
+	for _, bucket := range buckets {
+		existing[bucket.Region+"/"+bucket.Label] = struct{}{}
+	}
+
+	// Live grants are listed after the keys, so that keys granted after the listing of live
+	// grants cannot be found stale.
+	var live map[int]struct{}
+
+	if a.liveGrants != nil {
+		ids, err := a.liveGrants(ctx)
+		if err != nil {
+			return Report{}, fmt.Errorf("failed to list live grants: %w", err)
+		}
+
+		live = make(map[int]struct{}, len(ids))
+		for _, id := range ids {
+			live[id] = struct{}{}
+		}
+	}
+
+	report := Report{Time: time.Now().UTC(), Keys: len(keys), Findings: []Finding{}}
+
+	// Keys are sorted by ID, so the newest key of duplicates comes last.
+	slices.SortFunc(keys, func(a, b linodego.ObjectStorageKey) int { return cmp.Compare(a.ID, b.ID) })
+
+	// Time-limited keys are grouped by name, as their labels also hold the expiry.
+	names := make(map[string][]int)
+	for _, key := range keys {
+		if name := grantName(key.Label); name != "" {
+			names[name] = append(names[name], key.ID)
+		}
+	}
+
+	var errs error
+
+	stale := make(map[int]struct{})
+
+	for _, key := range keys {
+		findings, remediate := a.audit(key, existing, live, names[grantName(key.Label)])
+		if len(findings) == 0 {
+			continue
+		}
+
+		if slices.ContainsFunc(findings, func(f Finding) bool { return f.Kind == KindStale }) {
+			stale[key.ID] = struct{}{}
+
+			if _, confirmed := a.stale[key.ID]; a.stale != nil && !confirmed {
+				remediate = false
+			}
+		}
+
+		if remediate && a.remediate {
+			if err := a.client.DeleteObjectStorageKey(ctx, key.ID); err != nil && !linodego.IsNotFound(err) {
+				errs = errors.Join(errs, fmt.Errorf("failed to delete key %d: %w", key.ID, err))
+			} else {
+				a.log.InfoContext(ctx, "Object storage key deleted by audit", slog.Int("key_id", key.ID), slog.String("key_label", key.Label))
+				metrics.KeyAuditRemediations.Inc()
+
+				for i := range findings {
+					findings[i].Remediated = true
+				}
+			}
+		}
+
+		report.Findings = append(report.Findings, findings...)
+	}
+
+	if a.stale != nil {
+		a.stale = stale
+	}
+
+	for _, kind := range Kinds {
+		count := 0
+		for _, finding := range report.Findings {
+			if finding.Kind == kind && !finding.Remediated {
+				count++
+			}
+		}
+
+		metrics.KeyAuditFindings.WithLabelValues(string(kind)).Set(float64(count))
+	}
+
+	return report, errs
+}
+
+// audit returns the findings of the key, and whether the key should be deleted when
+// remediating. Live are the live grants, nil when unknown. Duplicates are the IDs of the keys
+// granted for the same BucketAccess.
+func (a *Auditor) audit(
+	key linodego.ObjectStorageKey,
+	existing map[string]struct{},
+	live map[int]struct{},
+	duplicates []int,
+) ([]Finding, bool) {
+	var (
+		findings  []Finding
+		remediate bool
+	)
+
+	finding := func(kind Kind, detail string) {
+		findings = append(findings, Finding{
+			Kind:      kind,
+			KeyID:     key.ID,
+			Label:     key.Label,
+			AccessKey: key.AccessKey,
+			Detail:    detail,
+		})
+	}
+
+	if !key.Limited {
+		finding(KindUnlimited, "key has access to every bucket of the account")
+		return findings, false
+	}
+
+	if key.BucketAccess != nil {
+		var missing []string
+		for _, access := range *key.BucketAccess {
+			if _, ok := existing[access.Region+"/"+access.BucketName]; !ok {
+				missing = append(missing, access.Region+"/"+access.BucketName)
+			}
+		}
+
+		if len(missing) > 0 {
+			finding(KindOrphaned, "buckets no longer exist: "+strings.Join(missing, ", "))
+			remediate = len(missing) == len(*key.BucketAccess)
+		}
+	}
+
+	if len(duplicates) > 1 {
+		finding(KindDuplicate, fmt.Sprintf("%d keys were granted for the BucketAccess, newest is %d", len(duplicates), duplicates[len(duplicates)-1]))
+	}
+
+	if live != nil && grantName(key.Label) != "" {
+		if _, ok := live[key.ID]; !ok {
+			finding(KindStale, "key is not the account of any live BucketAccess")
+			remediate = true
+		}
+	}
+
+	return findings, remediate
+}
+
+// grantName returns the name of the BucketAccess a key was granted for, or an empty string
+// for other keys.
+func grantName(label string) string {
+	name, _, _ := linodeclient.KeyExpiry(label)
+	if !strings.HasPrefix(name, GrantKeyLabelPrefix) {
+		return ""
+	}
+
+	return name
+}
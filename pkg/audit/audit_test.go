@@ -0,0 +1,200 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/mock/gomock"
+
+	"github.com/linode/linode-cosi-driver/pkg/audit"
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/pkg/metrics"
+	"github.com/linode/linode-cosi-driver/testing/mock"
+)
+
+const testRegion = "us-east"
+
+var discardLog = slog.New(slog.DiscardHandler)
+
+func limitedKey(id int, label string, buckets ...string) linodego.ObjectStorageKey {
+	access := make([]linodego.ObjectStorageKeyBucketAccess, 0, len(buckets))
+	for _, bucket := range buckets {
+		access = append(access, linodego.ObjectStorageKeyBucketAccess{Region: testRegion, BucketName: bucket})
+	}
+
+	return linodego.ObjectStorageKey{ID: id, Label: label, Limited: true, BucketAccess: &access}
+}
+
+// TestAudit is not parallel, as it reads the global finding metrics.
+func TestAudit(t *testing.T) { //nolint:paralleltest // reads global metrics
+	keys := []linodego.ObjectStorageKey{
+		{ID: 1, Label: "admin", Limited: false},
+		limitedKey(2, "ba-live", "live-bucket"),
+		limitedKey(3, "ba-gone", "gone-bucket"),
+		limitedKey(4, "ba-partial", "live-bucket", "gone-bucket"),
+		limitedKey(5, "ba-retried", "live-bucket"),
+		// Time-limited keys are duplicates of keys granted for the same BucketAccess.
+		limitedKey(6, linodeclient.KeyLabelWithExpiry("ba-retried", time.Now().Add(time.Hour)), "live-bucket"),
+		limitedKey(7, "ba-revoked", "live-bucket"),
+		limitedKey(8, "manual", "live-bucket"),
+	}
+	buckets := []linodego.ObjectStorageBucket{{Region: testRegion, Label: "live-bucket"}}
+
+	for _, tc := range []struct {
+		testName   string
+		opts       []audit.Option
+		expected   []string
+		remediated []int
+	}{
+		{
+			testName: "reports problem keys",
+			expected: []string{"unlimited/1", "orphaned/3", "orphaned/4", "duplicate/5", "duplicate/6"},
+		},
+		{
+			testName: "reports stale keys with live grants",
+			opts:     []audit.Option{audit.WithLiveGrants(2, 4, 6)},
+			expected: []string{
+				"unlimited/1", "orphaned/3", "stale/3", "orphaned/4", "duplicate/5", "stale/5", "duplicate/6", "stale/7",
+			},
+		},
+		{
+			testName:   "remediates orphaned and stale keys",
+			opts:       []audit.Option{audit.WithLiveGrants(2, 4, 6), audit.WithRemediation(true)},
+			expected:   []string{"unlimited/1", "orphaned/3", "stale/3", "orphaned/4", "duplicate/5", "stale/5", "duplicate/6", "stale/7"},
+			remediated: []int{3, 5, 7},
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockLinode := mock.NewMockLinodeClient(ctrl)
+			mockLinode.EXPECT().
+				ListObjectStorageKeys(gomock.Any(), gomock.Any()).
+				Return(slices.Clone(keys), nil)
+			mockLinode.EXPECT().
+				ListObjectStorageBuckets(gomock.Any(), gomock.Any()).
+				Return(buckets, nil)
+			for _, id := range tc.remediated {
+				mockLinode.EXPECT().DeleteObjectStorageKey(gomock.Any(), gomock.Eq(id)).Return(nil)
+			}
+
+			report, err := audit.New(mockLinode, append(tc.opts, audit.WithLogger(discardLog))...).Audit(t.Context())
+			if err != nil {
+				t.Fatalf("failed to audit keys: %v", err)
+			}
+
+			if report.Keys != len(keys) {
+				t.Errorf("expected %d audited keys, got %d", len(keys), report.Keys)
+			}
+
+			var (
+				findings   []string
+				remediated []int
+			)
+
+			for _, finding := range report.Findings {
+				findings = append(findings, fmt.Sprintf("%s/%d", finding.Kind, finding.KeyID))
+				if finding.Remediated && !slices.Contains(remediated, finding.KeyID) {
+					remediated = append(remediated, finding.KeyID)
+				}
+			}
+
+			if !slices.Equal(findings, tc.expected) {
+				t.Errorf("expected findings %v, got %v", tc.expected, findings)
+			}
+			if !slices.Equal(remediated, tc.remediated) {
+				t.Errorf("expected remediated keys %v, got %v", tc.remediated, remediated)
+			}
+
+			if got := testutil.ToFloat64(metrics.KeyAuditFindings.WithLabelValues(string(audit.KindUnlimited))); got != 1 {
+				t.Errorf("expected 1 unlimited key reported as metric, got %v", got)
+			}
+		})
+	}
+}
+
+// TestAuditListedLiveGrants is not parallel, as it sets the global finding metrics.
+func TestAuditListedLiveGrants(t *testing.T) { //nolint:paralleltest // sets global metrics
+	keys := []linodego.ObjectStorageKey{
+		limitedKey(2, "ba-live", "live-bucket"),
+		limitedKey(3, "ba-revoked", "live-bucket"),
+		// Key 4 is granted while the first audit runs, its BucketAccess is only updated later.
+		limitedKey(4, "ba-granted", "live-bucket"),
+	}
+	buckets := []linodego.ObjectStorageBucket{{Region: testRegion, Label: "live-bucket"}}
+
+	ctrl := gomock.NewController(t)
+	mockLinode := mock.NewMockLinodeClient(ctrl)
+	mockLinode.EXPECT().
+		ListObjectStorageKeys(gomock.Any(), gomock.Any()).
+		Return(slices.Clone(keys), nil).
+		Times(3)
+	mockLinode.EXPECT().
+		ListObjectStorageBuckets(gomock.Any(), gomock.Any()).
+		Return(buckets, nil).
+		Times(3)
+	// Only the key found stale by consecutive audits is deleted.
+	mockLinode.EXPECT().DeleteObjectStorageKey(gomock.Any(), gomock.Eq(3)).Return(nil)
+
+	live := [][]int{{2}, {2, 4}}
+	liveErr := errors.New("unavailable")
+
+	auditor := audit.New(mockLinode,
+		audit.WithLogger(discardLog),
+		audit.WithRemediation(true),
+		audit.WithLiveGrantsFunc(func(context.Context) ([]int, error) {
+			if len(live) == 0 {
+				return nil, liveErr
+			}
+
+			ids := live[0]
+			live = live[1:]
+
+			return ids, nil
+		}),
+	)
+
+	for i, expected := range [][]string{{"stale/3", "stale/4"}, {"stale/3"}} {
+		report, err := auditor.Audit(t.Context())
+		if err != nil {
+			t.Fatalf("audit %d: failed to audit keys: %v", i, err)
+		}
+
+		var findings []string
+		for _, finding := range report.Findings {
+			findings = append(findings, fmt.Sprintf("%s/%d", finding.Kind, finding.KeyID))
+			if finding.Remediated != (i == 1 && finding.KeyID == 3) {
+				t.Errorf("audit %d: unexpected remediation of key %d: %t", i, finding.KeyID, finding.Remediated)
+			}
+		}
+
+		if !slices.Equal(findings, expected) {
+			t.Errorf("audit %d: expected findings %v, got %v", i, expected, findings)
+		}
+	}
+
+	// Without the live grants, the audit fails rather than reporting every key as stale.
+	if _, err := auditor.Audit(t.Context()); !errors.Is(err, liveErr) {
+		t.Errorf("expected error %v, got %v", liveErr, err)
+	}
+}
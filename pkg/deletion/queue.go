@@ -0,0 +1,382 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package deletion implements the queue of buckets deleted in the background.
+package deletion
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/linode/linodego/v2"
+)
+
+// DefaultWorkers is the default number of buckets deleted concurrently.
+const DefaultWorkers = 2
+
+const (
+	// minRetryInterval is the delay before retrying a failed task. The delay doubles
+	// with every failed attempt, up to maxRetryInterval.
+	minRetryInterval = time.Second
+	maxRetryInterval = 5 * time.Minute
+	// idleInterval is how long idle workers wait before looking for due tasks.
+	idleInterval = time.Minute
+)
+
+// State is the stage of a task.
+type State string
+
+const (
+	// StatePending is the state of tasks that have not been started yet.
+	StatePending State = "pending"
+	// StateArchiving is the state of tasks copying the objects of the bucket to a backup bucket.
+	StateArchiving State = "archiving"
+	// StatePruning is the state of tasks removing the objects of the bucket.
+	StatePruning State = "pruning"
+	// StateDeleting is the state of tasks deleting the emptied bucket.
+	StateDeleting State = "deleting"
+)
+
+// Task is the deletion of a bucket.
+type Task struct {
+	Region       string                             `json:"region"`
+	Label        string                             `json:"label"`
+	EndpointType linodego.ObjectStorageEndpointType `json:"endpointType,omitempty"`
+	// Cleanup is set for buckets whose objects are removed before deletion.
+	Cleanup bool `json:"cleanup"`
+	// ArchiveTo is the backup bucket, in the form "region/label", that the objects of the
+	// bucket are copied to before it is pruned.
+	ArchiveTo string `json:"archiveTo,omitempty"`
+	State     State  `json:"state"`
+
+	// Objects and Bytes count the objects removed from the bucket over all attempts.
+	Objects int64 `json:"objects"`
+	Bytes   int64 `json:"bytes"`
+	// Archived counts the objects copied to the backup bucket.
+	Archived int64 `json:"archived,omitempty"`
+
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"lastError,omitempty"`
+	// NotBefore delays the retry of a failed attempt.
+	NotBefore time.Time `json:"notBefore,omitzero"`
+	// DeleteAfter is the end of the grace period of soft-deleted buckets. The task is
+	// not started before.
+	DeleteAfter time.Time `json:"deleteAfter,omitzero"`
+
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// Key returns the key identifying the bucket of the task.
+func (t Task) Key() string {
+	return t.Region + "/" + t.Label
+}
+
+// Handler processes a task. Progress is recorded by passing the updated task to update.
+// Tasks are removed from the queue once the handler succeeds, and retried otherwise.
+type Handler func(ctx context.Context, task Task, update func(Task)) error
+
+// Queue is a persistent queue of bucket deletions, processed by a bounded number of workers.
+// Each bucket is queued at most once.
+type Queue struct {
+	log     *slog.Logger
+	store   Store
+	workers int
+
+	mu      sync.Mutex
+	tasks   []*Task
+	running map[string]struct{}
+	wake    chan struct{}
+}
+
+// Option configures the Queue.
+type Option func(*Queue)
+
+// WithWorkers sets the number of buckets deleted concurrently.
+func WithWorkers(workers int) Option {
+	return func(q *Queue) {
+		q.workers = workers
+	}
+}
+
+func New(logger *slog.Logger, store Store, opts ...Option) *Queue {
+	q := &Queue{
+		log:     logger,
+		store:   store,
+		workers: DefaultWorkers,
+		running: make(map[string]struct{}),
+		wake:    make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	q.workers = max(q.workers, 1)
+
+	return q
+}
+
+// Load adds the stored tasks to the queue.
+func (q *Queue) Load() error {
+	tasks, err := q.store.Load()
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	for _, task := range tasks {
+		if q.find(task.Key()) < 0 {
+			q.tasks = append(q.tasks, &task)
+		}
+	}
+	q.mu.Unlock()
+
+	q.notify()
+
+	return nil
+}
+
+// Enqueue queues the task, unless the bucket is queued already. It returns the queued
+// task, and whether it was added by the call.
+func (q *Queue) Enqueue(task Task) (Task, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if i := q.find(task.Key()); i >= 0 {
+		return *q.tasks[i], false, nil
+	}
+
+	now := time.Now()
+	task.State = StatePending
+	task.NotBefore = task.DeleteAfter
+	task.EnqueuedAt, task.UpdatedAt = now, now
+
+	q.tasks = append(q.tasks, &task)
+
+	if err := q.save(); err != nil {
+		q.tasks = q.tasks[:len(q.tasks)-1]
+		return Task{}, false, err
+	}
+
+	q.notify()
+
+	return task, true, nil
+}
+
+// Get returns the queued task of the bucket.
+func (q *Queue) Get(region, label string) (Task, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := q.find(Task{Region: region, Label: label}.Key())
+	if i < 0 {
+		return Task{}, false
+	}
+
+	return *q.tasks[i], true
+}
+
+// Remove drops the queued task of the bucket, unless it is running. It reports whether
+// the bucket is no longer queued.
+func (q *Queue) Remove(region, label string) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := Task{Region: region, Label: label}.Key()
+	if _, ok := q.running[key]; ok {
+		return false, nil
+	}
+
+	i := q.find(key)
+	if i < 0 {
+		return true, nil
+	}
+
+	removed := q.tasks[i]
+	q.tasks = slices.Delete(q.tasks, i, i+1)
+
+	if err := q.save(); err != nil {
+		q.tasks = slices.Insert(q.tasks, i, removed)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Start processes the queue until the context is canceled. The stored tasks must be loaded
+// with Load before, and before any task is queued, as saving replaces the stored tasks.
+func (q *Queue) Start(ctx context.Context, handler Handler) error {
+	var wg sync.WaitGroup
+	for range q.workers {
+		wg.Go(func() {
+			q.work(ctx, handler)
+		})
+	}
+
+	wg.Wait()
+
+	return ctx.Err()
+}
+
+func (q *Queue) work(ctx context.Context, handler Handler) {
+	for {
+		task, wait := q.next()
+		if task != nil {
+			q.run(ctx, handler, *task)
+			continue
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-q.wake:
+		case <-timer.C:
+		}
+
+		timer.Stop()
+	}
+}
+
+// next claims the first due task. Without due tasks, it returns how long to wait for the next one.
+func (q *Queue) next() (*Task, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	wait := idleInterval
+
+	for _, task := range q.tasks {
+		if _, ok := q.running[task.Key()]; ok {
+			continue
+		}
+
+		if now.Before(task.NotBefore) {
+			wait = min(wait, task.NotBefore.Sub(now))
+			continue
+		}
+
+		q.running[task.Key()] = struct{}{}
+		claimed := *task
+
+		return &claimed, 0
+	}
+
+	return nil, wait
+}
+
+func (q *Queue) run(ctx context.Context, handler Handler, task Task) {
+	log := q.log.With(slog.String("bucket", task.Key()), slog.Int("attempt", task.Attempts+1))
+	log.InfoContext(ctx, "Processing bucket deletion", slog.String("state", string(task.State)))
+
+	err := handler(ctx, task, q.update)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	defer q.notify()
+
+	delete(q.running, task.Key())
+
+	i := q.find(task.Key())
+	if i < 0 {
+		return
+	}
+
+	switch {
+	case err == nil:
+		q.tasks = slices.Delete(q.tasks, i, i+1)
+		log.InfoContext(ctx, "Bucket deletion finished")
+
+	case ctx.Err() != nil:
+		// Interrupted by shutdown, the task is resumed after the restart.
+		return
+
+	default:
+		current := q.tasks[i]
+		current.Attempts++
+		current.LastError = err.Error()
+		current.NotBefore = time.Now().Add(retryInterval(current.Attempts))
+		current.UpdatedAt = time.Now()
+
+		log.ErrorContext(ctx, "Bucket deletion failed", "error", err, "retry_at", current.NotBefore)
+	}
+
+	if err := q.save(); err != nil {
+		log.ErrorContext(ctx, "Failed to persist deletion queue", "error", err)
+	}
+}
+
+// update records the progress of a running task.
+func (q *Queue) update(task Task) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := q.find(task.Key())
+	if i < 0 {
+		return
+	}
+
+	current := q.tasks[i]
+	current.State = task.State
+	current.Objects, current.Bytes = task.Objects, task.Bytes
+	current.Archived = task.Archived
+	current.UpdatedAt = time.Now()
+
+	if err := q.save(); err != nil {
+		q.log.Error("Failed to persist deletion queue", "error", err, "bucket", task.Key())
+	}
+}
+
+// save stores the tasks. It must be called with the lock held.
+func (q *Queue) save() error {
+	tasks := make([]Task, 0, len(q.tasks))
+	for _, task := range q.tasks {
+		tasks = append(tasks, *task)
+	}
+
+	if err := q.store.Save(tasks); err != nil {
+		return fmt.Errorf("unable to save deletion queue: %w", err)
+	}
+
+	return nil
+}
+
+// find returns the index of the task with the key, or -1. It must be called with the lock held.
+func (q *Queue) find(key string) int {
+	return slices.IndexFunc(q.tasks, func(task *Task) bool {
+		return task.Key() == key
+	})
+}
+
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func retryInterval(attempts int) time.Duration {
+	interval := minRetryInterval
+	for i := 1; i < attempts && interval < maxRetryInterval; i++ {
+		interval *= 2
+	}
+
+	return min(interval, maxRetryInterval)
+}
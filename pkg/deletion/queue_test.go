@@ -0,0 +1,254 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deletion
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var discardLog = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+const waitTimeout = 5 * time.Second
+
+// waitFor polls the condition until it holds or the timeout expires.
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(waitTimeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met within %s", waitTimeout)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func startQueue(t *testing.T, q *Queue, handler Handler) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.Start(ctx, handler) //nolint:errcheck // canceled on cleanup
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestQueueEnqueueDeduplicates(t *testing.T) {
+	t.Parallel()
+
+	q := New(discardLog, &MemoryStore{})
+
+	first, created, err := q.Enqueue(Task{Region: "us-ord", Label: "bucket", Cleanup: true})
+	if err != nil || !created {
+		t.Fatalf("expected task to be queued, got created=%t, err=%v", created, err)
+	}
+
+	if first.State != StatePending {
+		t.Errorf("expected pending task, got %s", first.State)
+	}
+
+	second, created, err := q.Enqueue(Task{Region: "us-ord", Label: "bucket"})
+	if err != nil || created {
+		t.Fatalf("expected queued task to be returned, got created=%t, err=%v", created, err)
+	}
+
+	if second != first {
+		t.Errorf("expected %+v, got %+v", first, second)
+	}
+}
+
+func TestQueueProcessesTasks(t *testing.T) {
+	t.Parallel()
+
+	q := New(discardLog, &MemoryStore{})
+
+	if _, _, err := q.Enqueue(Task{Region: "us-ord", Label: "bucket", Cleanup: true}); err != nil {
+		t.Fatalf("failed to queue task: %v", err)
+	}
+
+	progress := make(chan Task, 1)
+
+	startQueue(t, q, func(_ context.Context, task Task, update func(Task)) error {
+		task.State, task.Objects = StatePruning, 42
+		update(task)
+
+		progress <- task
+
+		return nil
+	})
+
+	select {
+	case <-progress:
+	case <-time.After(waitTimeout):
+		t.Fatalf("task was not processed")
+	}
+
+	waitFor(t, func() bool {
+		_, ok := q.Get("us-ord", "bucket")
+		return !ok
+	})
+}
+
+func TestQueueRetriesFailedTasks(t *testing.T) {
+	t.Parallel()
+
+	q := New(discardLog, &MemoryStore{})
+
+	if _, _, err := q.Enqueue(Task{Region: "us-ord", Label: "bucket"}); err != nil {
+		t.Fatalf("failed to queue task: %v", err)
+	}
+
+	errTransient := errors.New("transient failure")
+	attempts := make(chan Task, 2)
+
+	startQueue(t, q, func(_ context.Context, task Task, _ func(Task)) error {
+		attempts <- task
+
+		if task.Attempts == 0 {
+			return errTransient
+		}
+
+		return nil
+	})
+
+	<-attempts
+
+	waitFor(t, func() bool {
+		task, ok := q.Get("us-ord", "bucket")
+		return ok && task.Attempts == 1
+	})
+
+	task, _ := q.Get("us-ord", "bucket")
+	if task.LastError != errTransient.Error() || task.NotBefore.IsZero() {
+		t.Errorf("expected failure to be recorded, got %+v", task)
+	}
+
+	select {
+	case retried := <-attempts:
+		if retried.Attempts != 1 {
+			t.Errorf("expected retry to see 1 failed attempt, got %d", retried.Attempts)
+		}
+	case <-time.After(waitTimeout):
+		t.Fatalf("task was not retried")
+	}
+
+	waitFor(t, func() bool {
+		_, ok := q.Get("us-ord", "bucket")
+		return !ok
+	})
+}
+
+func TestQueueDelaysSoftDeletedTasks(t *testing.T) {
+	t.Parallel()
+
+	q := New(discardLog, &MemoryStore{})
+
+	started := make(chan Task, 1)
+
+	startQueue(t, q, func(_ context.Context, task Task, _ func(Task)) error {
+		started <- task
+		return nil
+	})
+
+	if _, _, err := q.Enqueue(Task{Region: "us-ord", Label: "bucket", DeleteAfter: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("failed to queue task: %v", err)
+	}
+
+	select {
+	case task := <-started:
+		t.Fatalf("task started before the grace period ended: %+v", task)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	removed, err := q.Remove("us-ord", "bucket")
+	if err != nil || !removed {
+		t.Fatalf("expected task to be removed, got removed=%t, err=%v", removed, err)
+	}
+
+	if _, ok := q.Get("us-ord", "bucket"); ok {
+		t.Errorf("expected task to be removed")
+	}
+}
+
+func TestQueueResumesStoredTasks(t *testing.T) {
+	t.Parallel()
+
+	store := NewFileStore(filepath.Join(t.TempDir(), "deletions.json"))
+
+	if err := store.Save([]Task{{Region: "us-ord", Label: "bucket", Cleanup: true, State: StatePruning, Objects: 10}}); err != nil {
+		t.Fatalf("failed to save tasks: %v", err)
+	}
+
+	q := New(discardLog, store)
+	if err := q.Load(); err != nil {
+		t.Fatalf("failed to load tasks: %v", err)
+	}
+
+	resumed := make(chan Task, 1)
+
+	startQueue(t, q, func(_ context.Context, task Task, _ func(Task)) error {
+		resumed <- task
+		return nil
+	})
+
+	select {
+	case task := <-resumed:
+		if task.State != StatePruning || task.Objects != 10 {
+			t.Errorf("expected task to resume while pruning with 10 objects removed, got %+v", task)
+		}
+	case <-time.After(waitTimeout):
+		t.Fatalf("stored task was not resumed")
+	}
+
+	waitFor(t, func() bool {
+		tasks, err := store.Load()
+		return err == nil && len(tasks) == 0
+	})
+}
+
+func TestFileStore(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "deletions.json")
+	store := NewFileStore(file)
+
+	tasks, err := store.Load()
+	if err != nil || len(tasks) != 0 {
+		t.Fatalf("expected missing file to hold no tasks, got %v, %v", tasks, err)
+	}
+
+	if err := os.WriteFile(file, []byte(`{"version":2,"tasks":[]}`), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	if _, err := store.Load(); !errors.Is(err, ErrStoredQueueVersion) {
+		t.Errorf("expected version error, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deletion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/linode/linode-cosi-driver/pkg/fileutils"
+)
+
+const storedQueueVersion = 1
+
+// ErrStoredQueueVersion is returned for stored queues written in an unknown format.
+var ErrStoredQueueVersion = errors.New("unsupported stored deletion queue version")
+
+// Store persists the tasks of the queue. Implementations backed by other storage,
+// e.g. Kubernetes objects, can be used in place of the FileStore.
+type Store interface {
+	// Load returns the stored tasks.
+	Load() ([]Task, error)
+	// Save replaces the stored tasks.
+	Save(tasks []Task) error
+}
+
+// MemoryStore keeps the tasks in memory. Tasks are lost when the driver restarts.
+type MemoryStore struct {
+	mu    sync.Mutex
+	tasks []Task
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func (s *MemoryStore) Load() ([]Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.tasks), nil
+}
+
+func (s *MemoryStore) Save(tasks []Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tasks = slices.Clone(tasks)
+
+	return nil
+}
+
+// FileStore keeps the tasks in a JSON file, replaced atomically on every save.
+type FileStore struct {
+	file string
+}
+
+var _ Store = (*FileStore)(nil)
+
+// storedQueue is the on-disk format of the queue.
+type storedQueue struct {
+	Version int    `json:"version"`
+	Tasks   []Task `json:"tasks"`
+}
+
+func NewFileStore(file string) *FileStore {
+	return &FileStore{file: file}
+}
+
+// Load returns the stored tasks. A missing file holds no tasks.
+func (s *FileStore) Load() ([]Task, error) {
+	raw, err := os.ReadFile(filepath.Clean(s.file))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read deletion queue: %w", err)
+	}
+
+	var queue storedQueue
+	if err := json.Unmarshal(raw, &queue); err != nil {
+		return nil, fmt.Errorf("unable to decode deletion queue: %w", err)
+	}
+
+	if queue.Version != storedQueueVersion {
+		return nil, fmt.Errorf("%w: %d", ErrStoredQueueVersion, queue.Version)
+	}
+
+	return queue.Tasks, nil
+}
+
+func (s *FileStore) Save(tasks []Task) error {
+	raw, err := json.Marshal(storedQueue{Version: storedQueueVersion, Tasks: tasks})
+	if err != nil {
+		return fmt.Errorf("unable to encode deletion queue: %w", err)
+	}
+
+	if err := fileutils.WriteFileAtomic(s.file, raw); err != nil {
+		return fmt.Errorf("unable to save deletion queue: %w", err)
+	}
+
+	return nil
+}
@@ -17,6 +17,7 @@ package envflag
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -77,3 +78,20 @@ func Duration(envKey string, defaultValue time.Duration) time.Duration {
 
 	return defaultValue
 }
+
+// Strings returns the comma separated values of the environment variable. Empty values are skipped.
+func Strings(envKey string, defaultValue []string) []string {
+	val, ok := os.LookupEnv(envKey)
+	if !ok {
+		return defaultValue
+	}
+
+	var values []string
+	for _, v := range strings.Split(val, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
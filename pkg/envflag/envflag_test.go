@@ -17,6 +17,7 @@ package envflag_test
 import (
 	"fmt"
 	"math/rand"
+	"slices"
 	"testing"
 	"time"
 
@@ -310,3 +311,61 @@ func TestDurations(t *testing.T) {
 		})
 	}
 }
+
+func TestStrings(t *testing.T) {
+	const Key = "KEY"
+
+	DefaultValue := []string{"default"}
+
+	for _, tc := range []struct {
+		name          string // required
+		key           string
+		value         string
+		defaultValue  []string
+		expectedValue []string
+	}{
+		{
+			name: "simple",
+		},
+		{
+			name:          "with default value",
+			defaultValue:  DefaultValue,
+			expectedValue: DefaultValue,
+		},
+		{
+			name:          "single value",
+			key:           Key,
+			value:         "a",
+			defaultValue:  DefaultValue,
+			expectedValue: []string{"a"},
+		},
+		{
+			name:          "multiple values",
+			key:           Key,
+			value:         "a, b,,c ",
+			defaultValue:  DefaultValue,
+			expectedValue: []string{"a", "b", "c"},
+		},
+		{
+			name:         "empty value",
+			key:          Key,
+			value:        "",
+			defaultValue: DefaultValue,
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.key != "" {
+				tc.key = fmt.Sprintf("TEST_%d_%s", rand.Intn(256), tc.key) // #nosec G404
+
+				t.Setenv(tc.key, tc.value)
+			}
+
+			actual := envflag.Strings(tc.key, tc.defaultValue)
+			if !slices.Equal(actual, tc.expectedValue) {
+				t.Errorf("expected: %v, got: %v", tc.expectedValue, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileutils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic replaces the file with data. The data is written to a temporary file in
+// the same directory, synced and renamed over the file, so readers never see partial writes.
+func WriteFileAtomic(file string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // the file is gone after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close() //nolint:errcheck // the write error is returned
+		return fmt.Errorf("unable to write %s: %w", file, err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close() //nolint:errcheck // the sync error is returned
+		return fmt.Errorf("unable to sync %s: %w", file, err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to close %s: %w", file, err)
+	}
+
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return fmt.Errorf("unable to replace %s: %w", file, err)
+	}
+
+	return nil
+}
@@ -20,7 +20,10 @@ import (
 	"iter"
 	"log/slog"
 	"maps"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/linode/linodego/v2"
@@ -31,10 +34,63 @@ import (
 const (
 	DefaultTTL     = time.Second * 30
 	defaultTimeout = time.Second * 15
+
+	// minRetryInterval is the initial delay before retrying a failed refresh.
+	// The delay doubles with every failure, up to the TTL.
+	minRetryInterval = time.Second
+	// missRefreshInterval limits how often cache misses trigger a refresh.
+	missRefreshInterval = time.Second * 5
+	// missRefreshTimeout bounds the refresh triggered by a miss.
+	missRefreshTimeout = time.Second * 5
 )
 
+// DefaultEndpointTypeOrder is the order in which the default endpoint of a region is chosen.
+// Endpoints without a type are treated as E0.
+var DefaultEndpointTypeOrder = []linodego.ObjectStorageEndpointType{
+	linodego.ObjectStorageEndpointE0,
+	linodego.ObjectStorageEndpointE1,
+	linodego.ObjectStorageEndpointE2,
+	linodego.ObjectStorageEndpointE3,
+}
+
 type Cache interface {
 	Get(key string) (string, bool)
+	// Endpoints returns the full endpoint catalog, in the order returned by the Linode API.
+	Endpoints() []Endpoint
+	// Endpoint returns the endpoint of the given type in the region. Without a type,
+	// the default endpoint of the region is returned, see DefaultEndpointTypeOrder.
+	Endpoint(region string, endpointType linodego.ObjectStorageEndpointType) (Endpoint, bool)
+}
+
+// Endpoint is an Object Storage endpoint along with its capabilities.
+type Endpoint struct {
+	linodego.ObjectStorageEndpoint
+
+	// CORS reports whether buckets served by the endpoint support CORS.
+	CORS bool
+}
+
+// NewEndpoint returns the endpoint with capabilities derived from its type.
+func NewEndpoint(ep linodego.ObjectStorageEndpoint) Endpoint {
+	return Endpoint{
+		ObjectStorageEndpoint: ep,
+		CORS:                  SupportsCORS(ep.EndpointType),
+	}
+}
+
+// Hostname returns the S3 hostname of the endpoint.
+func (e Endpoint) Hostname() string {
+	if e.S3Endpoint == nil {
+		return ""
+	}
+
+	return *e.S3Endpoint
+}
+
+// SupportsCORS reports whether buckets of the endpoint type support CORS.
+func SupportsCORS(endpointType linodego.ObjectStorageEndpointType) bool {
+	return endpointType != linodego.ObjectStorageEndpointE2 &&
+		endpointType != linodego.ObjectStorageEndpointE3
 }
 
 // Key returns the cache key for a region and endpoint type.
@@ -47,51 +103,180 @@ func Key(region string, endpointType linodego.ObjectStorageEndpointType) string
 }
 
 type EndpointCache struct {
-	sync.RWMutex
-
 	log    *slog.Logger
 	ttl    time.Duration
 	client linodeclient.Client
-	data   map[string]string
+
+	// file is the path the catalog is persisted to, persistence is disabled when empty.
+	file         string
+	maxStaleness time.Duration
+
+	// mu serializes writers, readers load the snapshot without locking.
+	mu   sync.Mutex
+	snap atomic.Pointer[snapshot]
+
+	refreshes refreshGroup
+}
+
+// snapshot is an immutable view of the endpoint catalog.
+type snapshot struct {
+	data    map[string]string
+	catalog []Endpoint
+	index   map[string]Endpoint
+
+	// refreshedAt is the time the catalog was listed by the Linode API.
+	refreshedAt time.Time
+	// stale is set for catalogs loaded from disk, until the first live refresh.
+	stale bool
 }
 
-func New(logger *slog.Logger, client linodeclient.Client, cacheTTL time.Duration) *EndpointCache {
+type refreshCall struct {
+	done chan struct{}
+	err  error
+}
+
+// refreshGroup shares a single refresh between concurrent callers.
+type refreshGroup struct {
+	mu       sync.Mutex
+	inflight *refreshCall
+	// last is the time the last successful refresh finished. Failed refreshes are not
+	// recorded, so that the next miss retries.
+	last time.Time
+}
+
+// do calls refresh, or waits for the refresh in flight and returns its result.
+func (g *refreshGroup) do(ctx context.Context, refresh func(context.Context) error) error {
+	g.mu.Lock()
+	if call := g.inflight; call != nil {
+		g.mu.Unlock()
+
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	call := &refreshCall{done: make(chan struct{})}
+	g.inflight = call
+	g.mu.Unlock()
+
+	g.finish(call, refresh(ctx))
+
+	return call.err
+}
+
+// doAsync starts refresh in the background, unless a refresh is in flight or the last refresh
+// finished less than interval ago.
+func (g *refreshGroup) doAsync(interval, timeout time.Duration, refresh func(context.Context) error) {
+	g.mu.Lock()
+	if g.inflight != nil || time.Since(g.last) < interval {
+		g.mu.Unlock()
+		return
+	}
+
+	call := &refreshCall{done: make(chan struct{})}
+	g.inflight = call
+	g.mu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		g.finish(call, refresh(ctx))
+	}()
+}
+
+func (g *refreshGroup) finish(call *refreshCall, err error) {
+	call.err = err
+
+	g.mu.Lock()
+	g.inflight = nil
+	if err == nil {
+		g.last = time.Now()
+	}
+	g.mu.Unlock()
+
+	close(call.done)
+}
+
+// recent reports whether no refresh is in flight, and the last successful refresh finished
+// less than interval ago.
+func (g *refreshGroup) recent(interval time.Duration) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.inflight == nil && time.Since(g.last) < interval
+}
+
+// Option configures the EndpointCache.
+type Option func(*EndpointCache)
+
+func New(logger *slog.Logger, client linodeclient.Client, cacheTTL time.Duration, opts ...Option) *EndpointCache {
 	if cacheTTL == 0 || cacheTTL < DefaultTTL {
 		cacheTTL = DefaultTTL
 	}
 
-	return &EndpointCache{
+	c := &EndpointCache{
 		log:    logger,
 		ttl:    cacheTTL,
 		client: client,
-		data:   make(map[string]string),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.snap.Store(&snapshot{
+		data:  make(map[string]string),
+		index: make(map[string]Endpoint),
+	})
+
+	return c
 }
 
+// Start refreshes the cache every TTL. Failed refreshes are retried with exponential backoff.
+// When persistence is enabled, call Load before Start, so lookups are served from the persisted
+// catalog until the first refresh.
 func (c *EndpointCache) Start(ctx context.Context) error {
-	ticker := time.NewTicker(c.ttl)
-	defer ticker.Stop()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
 
-	if err := c.Refresh(ctx); err != nil {
-		c.log.ErrorContext(ctx, "Failed to refresh cache", "error", err)
-	}
+	backoff := minRetryInterval
 
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-ticker.C:
-			ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
-			defer cancel()
+		case <-timer.C:
+			if err := c.refreshWithTimeout(ctx); err != nil {
+				c.log.ErrorContext(ctx, "Failed to refresh cache", "error", err, "retry_in", backoff)
+				timer.Reset(backoff)
+				backoff = min(backoff*2, c.ttl)
 
-			if err := c.Refresh(ctx); err != nil {
-				c.log.ErrorContext(ctx, "Failed to refresh cache", "error", err)
+				continue
 			}
+
+			backoff = minRetryInterval
+			timer.Reset(c.ttl)
 		}
 	}
 }
 
+func (c *EndpointCache) refreshWithTimeout(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	return c.Refresh(ctx)
+}
+
+// Refresh replaces the cached catalog with the endpoints listed by the Linode API.
+// Concurrent calls share a single refresh.
 func (c *EndpointCache) Refresh(ctx context.Context) error {
+	return c.refreshes.do(ctx, c.refresh)
+}
+
+func (c *EndpointCache) refresh(ctx context.Context) error {
 	c.log.DebugContext(ctx, "Syncing cache")
 
 	eps, err := c.client.ListObjectStorageEndpoints(ctx, nil)
@@ -99,38 +284,149 @@ func (c *EndpointCache) Refresh(ctx context.Context) error {
 		return fmt.Errorf("unable to list ObjectStorage endpoints: %w", err)
 	}
 
-	defaults := make(map[string]struct{})
-	for _, ep := range eps {
-		if ep.S3Endpoint != nil {
-			c.Set(Key(ep.Region, ep.EndpointType), *ep.S3Endpoint)
-			if _, ok := defaults[ep.Region]; !ok {
-				c.Set(ep.Region, *ep.S3Endpoint)
-				defaults[ep.Region] = struct{}{}
-			}
+	snap := newSnapshot(eps, time.Now(), false)
+
+	c.mu.Lock()
+	c.snap.Store(snap)
+	c.mu.Unlock()
+
+	if c.file != "" {
+		if err := c.save(snap); err != nil {
+			c.log.WarnContext(ctx, "Failed to persist cache", "error", err, "file", c.file)
 		}
 	}
 
 	return nil
 }
 
-func (c *EndpointCache) Insert(iter iter.Seq2[string, string]) {
-	c.Lock()
-	defer c.Unlock()
+func newSnapshot(eps []linodego.ObjectStorageEndpoint, refreshedAt time.Time, stale bool) *snapshot {
+	snap := &snapshot{
+		data:        make(map[string]string, len(eps)),
+		catalog:     make([]Endpoint, 0, len(eps)),
+		index:       make(map[string]Endpoint, len(eps)),
+		refreshedAt: refreshedAt,
+		stale:       stale,
+	}
+
+	for _, ep := range eps {
+		if ep.S3Endpoint == nil || *ep.S3Endpoint == "" {
+			continue
+		}
+
+		endpoint := NewEndpoint(ep)
+		snap.catalog = append(snap.catalog, endpoint)
+
+		if _, ok := snap.index[Key(ep.Region, ep.EndpointType)]; !ok {
+			snap.index[Key(ep.Region, ep.EndpointType)] = endpoint
+		}
+
+		if current, ok := snap.index[ep.Region]; !ok || endpointTypeRank(ep.EndpointType) < endpointTypeRank(current.EndpointType) {
+			snap.index[ep.Region] = endpoint
+		}
+	}
+
+	for key, endpoint := range snap.index {
+		snap.data[key] = endpoint.Hostname()
+	}
+
+	return snap
+}
+
+func endpointTypeRank(endpointType linodego.ObjectStorageEndpointType) int {
+	if endpointType == "" {
+		return 0
+	}
+
+	if i := slices.Index(DefaultEndpointTypeOrder, endpointType); i >= 0 {
+		return i
+	}
 
-	maps.Insert(c.data, iter)
+	return len(DefaultEndpointTypeOrder)
+}
+
+// refreshOnMiss refreshes the cache in the background after a miss, unless it was refreshed
+// recently. The lookup reporting the miss does not wait for the refresh.
+func (c *EndpointCache) refreshOnMiss() {
+	c.refreshes.doAsync(missRefreshInterval, missRefreshTimeout, func(ctx context.Context) error {
+		err := c.refresh(ctx)
+		if err != nil {
+			c.log.ErrorContext(ctx, "Failed to refresh cache after miss", "error", err)
+		}
+
+		return err
+	})
+}
+
+// update applies fn to a copy of the current snapshot and stores the copy.
+func (c *EndpointCache) update(fn func(data map[string]string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current := c.snap.Load()
+	next := &snapshot{
+		data:        maps.Clone(current.data),
+		catalog:     current.catalog,
+		index:       current.index,
+		refreshedAt: current.refreshedAt,
+		stale:       current.stale,
+	}
+	fn(next.data)
+
+	c.snap.Store(next)
+}
+
+func (c *EndpointCache) Insert(iter iter.Seq2[string, string]) {
+	c.update(func(data map[string]string) {
+		maps.Insert(data, iter)
+	})
 }
 
 func (c *EndpointCache) Set(key, val string) {
-	c.Lock()
-	defer c.Unlock()
-	c.data[key] = val
+	c.update(func(data map[string]string) {
+		data[key] = val
+	})
 }
 
+// Get returns the S3 hostname for the key. A miss triggers a background refresh of the cache.
 func (c *EndpointCache) Get(key string) (string, bool) {
-	c.RLock()
-	defer c.RUnlock()
-
-	val, ok := c.data[key]
+	val, ok := c.current().data[key]
+	if !ok {
+		c.refreshOnMiss()
+	}
 
 	return val, ok
 }
+
+func (c *EndpointCache) Endpoints() []Endpoint {
+	return slices.Clone(c.current().catalog)
+}
+
+// Endpoint returns the endpoint of the given type in the region. A miss triggers a background
+// refresh of the cache.
+func (c *EndpointCache) Endpoint(region string, endpointType linodego.ObjectStorageEndpointType) (Endpoint, bool) {
+	endpoint, ok := c.current().index[Key(region, endpointType)]
+	if !ok {
+		c.refreshOnMiss()
+	}
+
+	return endpoint, ok
+}
+
+// ClusterEndpoint returns the endpoint of a legacy Object Storage cluster. The cluster ID is
+// the first part of the endpoint hostname, e.g. "us-east-1" for "us-east-1.linodeobjects.com".
+// Known regions never match, so region based IDs keep their meaning.
+func ClusterEndpoint(endpoints []Endpoint, cluster string) (Endpoint, bool) {
+	for _, endpoint := range endpoints {
+		if endpoint.Region == cluster {
+			return Endpoint{}, false
+		}
+	}
+
+	for _, endpoint := range endpoints {
+		if host, _, _ := strings.Cut(endpoint.Hostname(), "."); host == cluster {
+			return endpoint, true
+		}
+	}
+
+	return Endpoint{}, false
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"sync"
 	"testing"
 	"time"
 
@@ -145,3 +146,244 @@ func TestCacheStart(t *testing.T) {
 		t.Fatal("cache did not stop within expected time")
 	}
 }
+
+func TestCacheEndpoints(t *testing.T) {
+	t.Parallel()
+
+	e0 := linodego.ObjectStorageEndpoint{
+		Region:       "us-test",
+		S3Endpoint:   ptr("us-test-1.linodeobjects.com"),
+		EndpointType: linodego.ObjectStorageEndpointE0,
+	}
+	e3 := linodego.ObjectStorageEndpoint{
+		Region:       "us-test",
+		S3Endpoint:   ptr("us-test-3.linodeobjects.com"),
+		EndpointType: linodego.ObjectStorageEndpointE3,
+	}
+	missing := linodego.ObjectStorageEndpoint{
+		Region:       "us-test",
+		EndpointType: linodego.ObjectStorageEndpointE1,
+	}
+
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockLinodeClient(ctrl)
+	mockClient.EXPECT().
+		ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageEndpoint{e0, missing, e3}, nil)
+
+	cache := New(discardLog, mockClient, DefaultTTL)
+	if err := cache.Refresh(t.Context()); err != nil {
+		t.Fatalf("cache refresh failed: %v", err)
+	}
+
+	endpoints := cache.Endpoints()
+	if len(endpoints) != 2 || endpoints[0].Hostname() != *e0.S3Endpoint || endpoints[1].Hostname() != *e3.S3Endpoint {
+		t.Fatalf("expected catalog of E0 and E3 endpoints in API order, got %+v", endpoints)
+	}
+
+	endpoint, ok := cache.Endpoint("us-test", linodego.ObjectStorageEndpointE3)
+	if !ok || endpoint.Hostname() != *e3.S3Endpoint || endpoint.CORS {
+		t.Errorf("expected E3 endpoint without CORS support, got %+v", endpoint)
+	}
+
+	endpoint, ok = cache.Endpoint("us-test", "")
+	if !ok || endpoint.Hostname() != *e0.S3Endpoint || !endpoint.CORS {
+		t.Errorf("expected default E0 endpoint with CORS support, got %+v", endpoint)
+	}
+
+	if _, ok := cache.Endpoint("us-test", linodego.ObjectStorageEndpointE1); ok {
+		t.Errorf("expected endpoint without S3 hostname to be skipped")
+	}
+}
+
+func TestCacheRefreshEvictsRemovedEndpoints(t *testing.T) {
+	t.Parallel()
+
+	kept := linodego.ObjectStorageEndpoint{
+		Region:     "us-test",
+		S3Endpoint: ptr("us-test-1.linodeobjects.com"),
+	}
+	removed := linodego.ObjectStorageEndpoint{
+		Region:     "de-test",
+		S3Endpoint: ptr("de-test-1.linodeobjects.com"),
+	}
+
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockLinodeClient(ctrl)
+	gomock.InOrder(
+		mockClient.EXPECT().
+			ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+			Return([]linodego.ObjectStorageEndpoint{kept, removed}, nil),
+		mockClient.EXPECT().
+			ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+			Return([]linodego.ObjectStorageEndpoint{kept}, nil),
+	)
+
+	cache := New(discardLog, mockClient, DefaultTTL)
+	for range 2 {
+		if err := cache.Refresh(t.Context()); err != nil {
+			t.Fatalf("cache refresh failed: %v", err)
+		}
+	}
+
+	if _, ok := cache.Get("de-test"); ok {
+		t.Errorf("expected removed endpoint to be evicted")
+	}
+
+	if _, ok := cache.Endpoint("de-test", ""); ok {
+		t.Errorf("expected removed endpoint to be evicted from the index")
+	}
+
+	if endpoints := cache.Endpoints(); len(endpoints) != 1 || endpoints[0].Region != "us-test" {
+		t.Errorf("expected catalog with only the kept endpoint, got %+v", endpoints)
+	}
+}
+
+func TestCacheDefaultEndpoint(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		eps      []linodego.ObjectStorageEndpoint
+		expected string
+	}{
+		{
+			name: "lowest endpoint type",
+			eps: []linodego.ObjectStorageEndpoint{
+				{Region: "us-test", S3Endpoint: ptr("us-test-3.linodeobjects.com"), EndpointType: linodego.ObjectStorageEndpointE3},
+				{Region: "us-test", S3Endpoint: ptr("us-test-1.linodeobjects.com"), EndpointType: linodego.ObjectStorageEndpointE1},
+				{Region: "us-test", S3Endpoint: ptr("us-test-2.linodeobjects.com"), EndpointType: linodego.ObjectStorageEndpointE2},
+			},
+			expected: "us-test-1.linodeobjects.com",
+		},
+		{
+			name: "untyped as E0",
+			eps: []linodego.ObjectStorageEndpoint{
+				{Region: "us-test", S3Endpoint: ptr("us-test-1.linodeobjects.com"), EndpointType: linodego.ObjectStorageEndpointE1},
+				{Region: "us-test", S3Endpoint: ptr("us-test-0.linodeobjects.com")},
+			},
+			expected: "us-test-0.linodeobjects.com",
+		},
+		{
+			name: "ties in API order",
+			eps: []linodego.ObjectStorageEndpoint{
+				{Region: "us-test", S3Endpoint: ptr("us-test-a.linodeobjects.com"), EndpointType: linodego.ObjectStorageEndpointE1},
+				{Region: "us-test", S3Endpoint: ptr("us-test-b.linodeobjects.com"), EndpointType: linodego.ObjectStorageEndpointE1},
+			},
+			expected: "us-test-a.linodeobjects.com",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockClient := mock.NewMockLinodeClient(ctrl)
+			mockClient.EXPECT().
+				ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+				Return(tc.eps, nil)
+
+			cache := New(discardLog, mockClient, DefaultTTL)
+			if err := cache.Refresh(t.Context()); err != nil {
+				t.Fatalf("cache refresh failed: %v", err)
+			}
+
+			if s3Endpoint, ok := cache.Get("us-test"); !ok || s3Endpoint != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, s3Endpoint)
+			}
+		})
+	}
+}
+
+func TestCacheRefreshOnMiss(t *testing.T) {
+	t.Parallel()
+
+	testRegion := linodego.ObjectStorageEndpoint{
+		Region:     "us-test",
+		S3Endpoint: ptr("us-test-1.linodeobjects.com"),
+	}
+
+	release := make(chan struct{})
+
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockLinodeClient(ctrl)
+	mockClient.EXPECT().
+		ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, *linodego.ListOptions) ([]linodego.ObjectStorageEndpoint, error) {
+			// Keep the refresh in flight until all lookups missed.
+			<-release
+			return []linodego.ObjectStorageEndpoint{testRegion}, nil
+		}).
+		Times(1)
+
+	cache := New(discardLog, mockClient, DefaultTTL)
+
+	// Misses return without waiting for the refresh they trigger.
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if _, ok := cache.Get("us-test"); ok {
+				t.Errorf("expected miss before the refresh")
+			}
+		})
+	}
+	wg.Wait()
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		s3Endpoint, ok := cache.Get("us-test")
+		if ok && s3Endpoint == *testRegion.S3Endpoint {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %s after the background refresh, got %s", *testRegion.S3Endpoint, s3Endpoint)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Misses right after a refresh do not trigger another one.
+	if _, ok := cache.Get("de-test"); ok {
+		t.Errorf("expected miss for unknown region")
+	}
+}
+
+func TestCacheStartRetriesFailedRefresh(t *testing.T) {
+	t.Parallel()
+
+	testRegion := linodego.ObjectStorageEndpoint{
+		Region:     "us-test",
+		S3Endpoint: ptr("us-test-1.linodeobjects.com"),
+	}
+
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockLinodeClient(ctrl)
+	gomock.InOrder(
+		mockClient.EXPECT().
+			ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("unavailable")),
+		mockClient.EXPECT().
+			ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+			Return([]linodego.ObjectStorageEndpoint{testRegion}, nil),
+	)
+
+	// The TTL is long enough that only the retry can populate the cache.
+	cache := New(discardLog, mockClient, time.Hour)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	go func() {
+		_ = cache.Start(ctx)
+	}()
+
+	deadline := time.Now().Add(3 * minRetryInterval)
+	for time.Now().Before(deadline) {
+		if len(cache.Endpoints()) > 0 {
+			return
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	t.Fatal("cache was not populated by the retried refresh")
+}
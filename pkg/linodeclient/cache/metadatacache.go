@@ -0,0 +1,273 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/linode/linodego/v2"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/pkg/metrics"
+)
+
+const (
+	DefaultMetadataTTL  = time.Second * 5
+	DefaultMetadataSize = 1024
+
+	metadataCacheName = "metadata"
+)
+
+// Cached resources, used as key prefixes and metric labels.
+const (
+	resourceBucket       = "bucket"
+	resourceBucketAccess = "bucket_access"
+)
+
+// MetadataCache is a linodeclient.Client caching bucket and bucket access reads for a short
+// time. Concurrent lookups of the same resource share a single API call. Creating, deleting
+// and updating buckets through the cache invalidates the cached entries of the bucket.
+type MetadataCache struct {
+	linodeclient.Client
+
+	ttl  time.Duration
+	size int
+
+	mu       sync.Mutex
+	entries  map[string]*list.Element
+	lru      *list.List
+	inflight map[string]*lookupCall
+}
+
+var _ linodeclient.Client = (*MetadataCache)(nil)
+
+type metadataEntry struct {
+	key     string
+	value   any
+	expires time.Time
+}
+
+type lookupCall struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+// NewMetadataCache wraps the client with a cache holding at most size entries for the TTL.
+func NewMetadataCache(client linodeclient.Client, ttl time.Duration, size int) *MetadataCache {
+	if ttl <= 0 {
+		ttl = DefaultMetadataTTL
+	}
+
+	if size <= 0 {
+		size = DefaultMetadataSize
+	}
+
+	return &MetadataCache{
+		Client:   client,
+		ttl:      ttl,
+		size:     size,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		inflight: make(map[string]*lookupCall),
+	}
+}
+
+func (c *MetadataCache) GetObjectStorageBucket(ctx context.Context, region, label string) (*linodego.ObjectStorageBucket, error) {
+	value, err := c.lookup(ctx, resourceBucket, region, label, func(ctx context.Context) (any, error) {
+		return c.Client.GetObjectStorageBucket(ctx, region, label)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bucket := *value.(*linodego.ObjectStorageBucket) //nolint:forcetypeassert,errcheck //only buckets are stored under the key
+
+	return &bucket, nil
+}
+
+func (c *MetadataCache) GetObjectStorageBucketAccess(
+	ctx context.Context,
+	region, label string,
+) (*linodego.ObjectStorageBucketAccess, error) {
+	value, err := c.lookup(ctx, resourceBucketAccess, region, label, func(ctx context.Context) (any, error) {
+		return c.Client.GetObjectStorageBucketAccess(ctx, region, label)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	access := *value.(*linodego.ObjectStorageBucketAccess) //nolint:forcetypeassert,errcheck //only bucket access is stored under the key
+
+	return &access, nil
+}
+
+func (c *MetadataCache) CreateObjectStorageBucket(
+	ctx context.Context,
+	opts linodego.ObjectStorageBucketCreateOptions,
+) (*linodego.ObjectStorageBucket, error) {
+	defer c.Invalidate(opts.Region, opts.Label)
+
+	return c.Client.CreateObjectStorageBucket(ctx, opts)
+}
+
+func (c *MetadataCache) DeleteObjectStorageBucket(ctx context.Context, region, label string) error {
+	defer c.Invalidate(region, label)
+
+	return c.Client.DeleteObjectStorageBucket(ctx, region, label)
+}
+
+func (c *MetadataCache) UpdateObjectStorageBucketAccess(
+	ctx context.Context,
+	region, label string,
+	opts linodego.ObjectStorageBucketUpdateAccessOptions,
+) error {
+	defer c.Invalidate(region, label)
+
+	return c.Client.UpdateObjectStorageBucketAccess(ctx, region, label, opts)
+}
+
+// Invalidate drops the cached entries of the bucket. Lookups in flight are not cached.
+func (c *MetadataCache) Invalidate(region, label string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, resource := range []string{resourceBucket, resourceBucketAccess} {
+		key := metadataKey(resource, region, label)
+
+		if elem, ok := c.entries[key]; ok {
+			c.lru.Remove(elem)
+			delete(c.entries, key)
+		}
+
+		delete(c.inflight, key)
+	}
+}
+
+// lookup returns the cached value of the resource, or fetches it. Only successful lookups are cached.
+func (c *MetadataCache) lookup(
+	ctx context.Context,
+	resource, region, label string,
+	fetch func(context.Context) (any, error),
+) (any, error) {
+	key := metadataKey(resource, region, label)
+
+	for {
+		c.mu.Lock()
+
+		if value, ok := c.get(key); ok {
+			c.mu.Unlock()
+			metrics.CacheLookups.WithLabelValues(metadataCacheName, resource, metrics.ResultHit).Inc()
+
+			return value, nil
+		}
+
+		call, shared := c.inflight[key]
+		if !shared {
+			call = &lookupCall{done: make(chan struct{})}
+			c.inflight[key] = call
+		}
+
+		c.mu.Unlock()
+
+		if !shared {
+			metrics.CacheLookups.WithLabelValues(metadataCacheName, resource, metrics.ResultMiss).Inc()
+
+			call.value, call.err = fetch(ctx)
+			c.complete(key, call)
+
+			return call.value, call.err
+		}
+
+		metrics.CacheLookups.WithLabelValues(metadataCacheName, resource, metrics.ResultShared).Inc()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-call.done:
+		}
+
+		// Lookups canceled by the caller that started them are retried.
+		if errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded) {
+			continue
+		}
+
+		return call.value, call.err
+	}
+}
+
+// complete stores the result of the call, unless the key was invalidated in the meantime.
+func (c *MetadataCache) complete(key string, call *lookupCall) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	defer close(call.done)
+
+	if c.inflight[key] != call {
+		return
+	}
+
+	delete(c.inflight, key)
+
+	if call.err == nil {
+		c.set(key, call.value)
+	}
+}
+
+func (c *MetadataCache) get(key string) (any, bool) {
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*metadataEntry) //nolint:forcetypeassert,errcheck //only entries are stored in the list
+	if time.Now().After(entry.expires) {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+
+	return entry.value, true
+}
+
+func (c *MetadataCache) set(key string, value any) {
+	entry := &metadataEntry{key: key, value: value, expires: time.Now().Add(c.ttl)}
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*metadataEntry).key) //nolint:forcetypeassert,errcheck //only entries are stored in the list
+	}
+}
+
+func metadataKey(resource, region, label string) string {
+	return resource + "/" + region + "/" + label
+}
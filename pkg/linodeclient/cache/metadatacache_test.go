@@ -0,0 +1,250 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/mock/gomock"
+
+	"github.com/linode/linode-cosi-driver/pkg/metrics"
+	"github.com/linode/linode-cosi-driver/testing/mock"
+)
+
+const (
+	testMetadataRegion = "us-test"
+	testMetadataLabel  = "test-bucket"
+)
+
+var testMetadataBucket = &linodego.ObjectStorageBucket{
+	Region: testMetadataRegion,
+	Label:  testMetadataLabel,
+}
+
+// TestMetadataCacheMetrics is not parallel, as metrics are shared by all caches.
+func TestMetadataCacheMetrics(t *testing.T) { //nolint:paralleltest // reads global metrics
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockLinodeClient(ctrl)
+	mockClient.EXPECT().
+		GetObjectStorageBucket(gomock.Any(), testMetadataRegion, testMetadataLabel).
+		Return(testMetadataBucket, nil)
+
+	hits := metrics.CacheLookups.WithLabelValues(metadataCacheName, resourceBucket, metrics.ResultHit)
+	misses := metrics.CacheLookups.WithLabelValues(metadataCacheName, resourceBucket, metrics.ResultMiss)
+	hitsBefore, missesBefore := testutil.ToFloat64(hits), testutil.ToFloat64(misses)
+
+	cache := NewMetadataCache(mockClient, time.Minute, 0)
+	for range 3 {
+		if _, err := cache.GetObjectStorageBucket(t.Context(), testMetadataRegion, testMetadataLabel); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if got := testutil.ToFloat64(misses) - missesBefore; got != 1 {
+		t.Errorf("expected 1 miss, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(hits) - hitsBefore; got != 2 {
+		t.Errorf("expected 2 hits, got %v", got)
+	}
+}
+
+func TestMetadataCacheInvalidation(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name  string
+		write func(context.Context, *MetadataCache) error
+	}{
+		{
+			name: "create",
+			write: func(ctx context.Context, c *MetadataCache) error {
+				_, err := c.CreateObjectStorageBucket(ctx, linodego.ObjectStorageBucketCreateOptions{
+					Region: testMetadataRegion,
+					Label:  testMetadataLabel,
+				})
+				return err
+			},
+		},
+		{
+			name: "delete",
+			write: func(ctx context.Context, c *MetadataCache) error {
+				return c.DeleteObjectStorageBucket(ctx, testMetadataRegion, testMetadataLabel)
+			},
+		},
+		{
+			name: "update access",
+			write: func(ctx context.Context, c *MetadataCache) error {
+				return c.UpdateObjectStorageBucketAccess(ctx, testMetadataRegion, testMetadataLabel,
+					linodego.ObjectStorageBucketUpdateAccessOptions{ACL: linodego.ACLPrivate})
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockClient := mock.NewMockLinodeClient(ctrl)
+			mockClient.EXPECT().
+				GetObjectStorageBucket(gomock.Any(), testMetadataRegion, testMetadataLabel).
+				Return(testMetadataBucket, nil).
+				Times(2)
+			mockClient.EXPECT().
+				GetObjectStorageBucketAccess(gomock.Any(), testMetadataRegion, testMetadataLabel).
+				Return(&linodego.ObjectStorageBucketAccess{ACL: linodego.ACLPrivate}, nil).
+				Times(2)
+			mockClient.EXPECT().
+				CreateObjectStorageBucket(gomock.Any(), gomock.Any()).
+				Return(testMetadataBucket, nil).
+				AnyTimes()
+			mockClient.EXPECT().
+				DeleteObjectStorageBucket(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+			mockClient.EXPECT().
+				UpdateObjectStorageBucketAccess(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(nil).
+				AnyTimes()
+
+			cache := NewMetadataCache(mockClient, time.Minute, 0)
+
+			read := func() {
+				for range 2 {
+					if _, err := cache.GetObjectStorageBucket(t.Context(), testMetadataRegion, testMetadataLabel); err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					if _, err := cache.GetObjectStorageBucketAccess(t.Context(), testMetadataRegion, testMetadataLabel); err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+				}
+			}
+
+			read()
+
+			if err := tc.write(t.Context(), cache); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			read()
+		})
+	}
+}
+
+func TestMetadataCacheBounds(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockLinodeClient(ctrl)
+	mockClient.EXPECT().
+		GetObjectStorageBucket(gomock.Any(), testMetadataRegion, gomock.Any()).
+		DoAndReturn(func(_ context.Context, region, label string) (*linodego.ObjectStorageBucket, error) {
+			return &linodego.ObjectStorageBucket{Region: region, Label: label}, nil
+		}).
+		Times(4)
+
+	cache := NewMetadataCache(mockClient, time.Minute, 2)
+
+	// The first bucket is evicted by the third and fetched again.
+	for _, label := range []string{"bucket-1", "bucket-2", "bucket-3", "bucket-1", "bucket-3"} {
+		bucket, err := cache.GetObjectStorageBucket(t.Context(), testMetadataRegion, label)
+		if err != nil || bucket.Label != label {
+			t.Fatalf("expected bucket %s, got %+v (error: %v)", label, bucket, err)
+		}
+	}
+}
+
+func TestMetadataCacheExpiry(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockLinodeClient(ctrl)
+	mockClient.EXPECT().
+		GetObjectStorageBucket(gomock.Any(), testMetadataRegion, testMetadataLabel).
+		Return(testMetadataBucket, nil).
+		Times(2)
+
+	cache := NewMetadataCache(mockClient, time.Millisecond, 0)
+	for range 2 {
+		if _, err := cache.GetObjectStorageBucket(t.Context(), testMetadataRegion, testMetadataLabel); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestMetadataCacheErrorsAreNotCached(t *testing.T) {
+	t.Parallel()
+
+	errNotFound := errors.New("not found")
+
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockLinodeClient(ctrl)
+	gomock.InOrder(
+		mockClient.EXPECT().
+			GetObjectStorageBucket(gomock.Any(), testMetadataRegion, testMetadataLabel).
+			Return(nil, errNotFound),
+		mockClient.EXPECT().
+			GetObjectStorageBucket(gomock.Any(), testMetadataRegion, testMetadataLabel).
+			Return(testMetadataBucket, nil),
+	)
+
+	cache := NewMetadataCache(mockClient, time.Minute, 0)
+
+	if _, err := cache.GetObjectStorageBucket(t.Context(), testMetadataRegion, testMetadataLabel); !errors.Is(err, errNotFound) {
+		t.Fatalf("expected error %v, got %v", errNotFound, err)
+	}
+
+	if _, err := cache.GetObjectStorageBucket(t.Context(), testMetadataRegion, testMetadataLabel); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMetadataCacheDeduplicatesLookups(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockLinodeClient(ctrl)
+	mockClient.EXPECT().
+		GetObjectStorageBucket(gomock.Any(), testMetadataRegion, testMetadataLabel).
+		DoAndReturn(func(context.Context, string, string) (*linodego.ObjectStorageBucket, error) {
+			// Keep the lookup in flight long enough for all lookups to join it.
+			time.Sleep(100 * time.Millisecond)
+			return testMetadataBucket, nil
+		})
+
+	cache := NewMetadataCache(mockClient, time.Minute, 0)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			bucket, err := cache.GetObjectStorageBucket(t.Context(), testMetadataRegion, testMetadataLabel)
+			if err != nil || bucket.Label != testMetadataLabel {
+				t.Errorf("expected bucket %s, got %+v (error: %v)", testMetadataLabel, bucket, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/linode/linodego/v2"
+
+	"github.com/linode/linode-cosi-driver/pkg/fileutils"
+)
+
+// DefaultMaxStaleness is the default age after which a persisted catalog is ignored.
+const DefaultMaxStaleness = time.Hour * 24
+
+const persistedCatalogVersion = 1
+
+var (
+	// ErrPersistedCatalogExpired is returned when the persisted catalog is older than the maximum staleness.
+	ErrPersistedCatalogExpired = errors.New("persisted endpoint catalog expired")
+	// ErrPersistedCatalogVersion is returned for persisted catalogs written in an unknown format.
+	ErrPersistedCatalogVersion = errors.New("unsupported persisted endpoint catalog version")
+)
+
+// persistedCatalog is the on-disk format of the endpoint catalog.
+type persistedCatalog struct {
+	Version     int                              `json:"version"`
+	RefreshedAt time.Time                        `json:"refreshedAt"`
+	Endpoints   []linodego.ObjectStorageEndpoint `json:"endpoints"`
+}
+
+// WithPersistence persists the endpoint catalog to the file after every successful refresh.
+// The persisted catalog is loaded with Load, and its entries are served as stale until the
+// first live refresh. Catalogs older than maxStaleness are ignored, and a loaded catalog is
+// dropped once it gets older than maxStaleness without being refreshed. A zero maxStaleness
+// never expires the persisted catalog.
+func WithPersistence(file string, maxStaleness time.Duration) Option {
+	return func(c *EndpointCache) {
+		c.file = file
+		c.maxStaleness = maxStaleness
+	}
+}
+
+// Load loads the persisted catalog, unless the cache was already refreshed.
+func (c *EndpointCache) Load(ctx context.Context) error {
+	raw, err := os.ReadFile(filepath.Clean(c.file))
+	if err != nil {
+		return fmt.Errorf("unable to read persisted catalog: %w", err)
+	}
+
+	var catalog persistedCatalog
+	if err := json.Unmarshal(raw, &catalog); err != nil {
+		return fmt.Errorf("unable to decode persisted catalog: %w", err)
+	}
+
+	if catalog.Version != persistedCatalogVersion {
+		return fmt.Errorf("%w: %d", ErrPersistedCatalogVersion, catalog.Version)
+	}
+
+	if c.expired(catalog.RefreshedAt) {
+		return fmt.Errorf("%w: refreshed at %s", ErrPersistedCatalogExpired, catalog.RefreshedAt.Format(time.RFC3339))
+	}
+
+	snap := newSnapshot(catalog.Endpoints, catalog.RefreshedAt, true)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.snap.Load().refreshedAt.IsZero() {
+		return nil
+	}
+
+	c.snap.Store(snap)
+	c.log.InfoContext(ctx, "Loaded persisted cache", "file", c.file, "endpoints", len(snap.catalog), "refreshed_at", catalog.RefreshedAt)
+
+	return nil
+}
+
+// save atomically replaces the persisted catalog with the snapshot.
+func (c *EndpointCache) save(snap *snapshot) error {
+	catalog := persistedCatalog{
+		Version:     persistedCatalogVersion,
+		RefreshedAt: snap.refreshedAt.UTC(),
+		Endpoints:   make([]linodego.ObjectStorageEndpoint, 0, len(snap.catalog)),
+	}
+	for _, endpoint := range snap.catalog {
+		catalog.Endpoints = append(catalog.Endpoints, endpoint.ObjectStorageEndpoint)
+	}
+
+	raw, err := json.Marshal(catalog)
+	if err != nil {
+		return fmt.Errorf("unable to encode catalog: %w", err)
+	}
+
+	if err := fileutils.WriteFileAtomic(c.file, raw); err != nil {
+		return fmt.Errorf("unable to save catalog: %w", err)
+	}
+
+	return nil
+}
+
+// current returns the snapshot to serve lookups from. Stale snapshots past the maximum
+// staleness are not served.
+func (c *EndpointCache) current() *snapshot {
+	snap := c.snap.Load()
+	if snap.stale && c.expired(snap.refreshedAt) {
+		return &snapshot{}
+	}
+
+	return snap
+}
+
+func (c *EndpointCache) expired(refreshedAt time.Time) bool {
+	return c.maxStaleness > 0 && time.Since(refreshedAt) > c.maxStaleness
+}
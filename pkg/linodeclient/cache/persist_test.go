@@ -0,0 +1,216 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego/v2"
+	"go.uber.org/mock/gomock"
+
+	"github.com/linode/linode-cosi-driver/testing/mock"
+)
+
+func TestCachePersistence(t *testing.T) {
+	t.Parallel()
+
+	testRegion := linodego.ObjectStorageEndpoint{
+		Region:       "us-test",
+		S3Endpoint:   ptr("us-test-1.linodeobjects.com"),
+		EndpointType: linodego.ObjectStorageEndpointE1,
+	}
+	file := filepath.Join(t.TempDir(), "endpoints.json")
+
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockLinodeClient(ctrl)
+	mockClient.EXPECT().
+		ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageEndpoint{testRegion}, nil)
+
+	live := New(discardLog, mockClient, DefaultTTL, WithPersistence(file, DefaultMaxStaleness))
+	if err := live.Refresh(t.Context()); err != nil {
+		t.Fatalf("cache refresh failed: %v", err)
+	}
+
+	// The restarted cache has no working client, it is served from the persisted catalog only.
+	restarted := New(discardLog, nil, DefaultTTL, WithPersistence(file, DefaultMaxStaleness))
+	if err := restarted.Load(t.Context()); err != nil {
+		t.Fatalf("loading persisted cache failed: %v", err)
+	}
+
+	endpoint, ok := restarted.Endpoint("us-test", linodego.ObjectStorageEndpointE1)
+	if !ok || endpoint.Hostname() != *testRegion.S3Endpoint || !restarted.current().stale {
+		t.Errorf("expected stale endpoint %s, got %+v", *testRegion.S3Endpoint, endpoint)
+	}
+
+	if s3Endpoint, ok := restarted.Get("us-test"); !ok || s3Endpoint != *testRegion.S3Endpoint {
+		t.Errorf("expected %s, got %s", *testRegion.S3Endpoint, s3Endpoint)
+	}
+}
+
+func TestCachePersistenceLiveRefreshWins(t *testing.T) {
+	t.Parallel()
+
+	persisted := linodego.ObjectStorageEndpoint{
+		Region:     "us-test",
+		S3Endpoint: ptr("us-test-old.linodeobjects.com"),
+	}
+	live := linodego.ObjectStorageEndpoint{
+		Region:     "us-test",
+		S3Endpoint: ptr("us-test-1.linodeobjects.com"),
+	}
+	file := filepath.Join(t.TempDir(), "endpoints.json")
+	writeCatalog(t, file, persistedCatalog{
+		Version:     persistedCatalogVersion,
+		RefreshedAt: time.Now(),
+		Endpoints:   []linodego.ObjectStorageEndpoint{persisted},
+	})
+
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockLinodeClient(ctrl)
+	mockClient.EXPECT().
+		ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageEndpoint{live}, nil)
+
+	cache := New(discardLog, mockClient, DefaultTTL, WithPersistence(file, DefaultMaxStaleness))
+	if err := cache.Refresh(t.Context()); err != nil {
+		t.Fatalf("cache refresh failed: %v", err)
+	}
+
+	if err := cache.Load(t.Context()); err != nil {
+		t.Fatalf("loading persisted cache failed: %v", err)
+	}
+
+	endpoint, ok := cache.Endpoint("us-test", "")
+	if !ok || endpoint.Hostname() != *live.S3Endpoint || cache.current().stale {
+		t.Errorf("expected live endpoint %s, got %+v", *live.S3Endpoint, endpoint)
+	}
+}
+
+func TestCachePersistenceExpiry(t *testing.T) {
+	t.Parallel()
+
+	testRegion := linodego.ObjectStorageEndpoint{
+		Region:     "us-test",
+		S3Endpoint: ptr("us-test-1.linodeobjects.com"),
+	}
+
+	for _, tc := range []struct {
+		name         string
+		catalog      persistedCatalog
+		maxStaleness time.Duration
+		expectedErr  error
+	}{
+		{
+			name: "fresh",
+			catalog: persistedCatalog{
+				Version:     persistedCatalogVersion,
+				RefreshedAt: time.Now().Add(-time.Hour),
+				Endpoints:   []linodego.ObjectStorageEndpoint{testRegion},
+			},
+			maxStaleness: DefaultMaxStaleness,
+		},
+		{
+			name: "expired",
+			catalog: persistedCatalog{
+				Version:     persistedCatalogVersion,
+				RefreshedAt: time.Now().Add(-2 * DefaultMaxStaleness),
+				Endpoints:   []linodego.ObjectStorageEndpoint{testRegion},
+			},
+			maxStaleness: DefaultMaxStaleness,
+			expectedErr:  ErrPersistedCatalogExpired,
+		},
+		{
+			name: "never expires",
+			catalog: persistedCatalog{
+				Version:     persistedCatalogVersion,
+				RefreshedAt: time.Now().Add(-2 * DefaultMaxStaleness),
+				Endpoints:   []linodego.ObjectStorageEndpoint{testRegion},
+			},
+		},
+		{
+			name: "unknown version",
+			catalog: persistedCatalog{
+				Version:     persistedCatalogVersion + 1,
+				RefreshedAt: time.Now(),
+				Endpoints:   []linodego.ObjectStorageEndpoint{testRegion},
+			},
+			maxStaleness: DefaultMaxStaleness,
+			expectedErr:  ErrPersistedCatalogVersion,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			file := filepath.Join(t.TempDir(), "endpoints.json")
+			writeCatalog(t, file, tc.catalog)
+
+			cache := New(discardLog, nil, DefaultTTL, WithPersistence(file, tc.maxStaleness))
+
+			err := cache.Load(t.Context())
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+			}
+
+			if tc.expectedErr == nil && len(cache.Endpoints()) != 1 {
+				t.Errorf("expected persisted endpoint to be loaded, got %+v", cache.Endpoints())
+			}
+		})
+	}
+}
+
+func TestCachePersistenceDropsExpiredStaleCatalog(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "endpoints.json")
+	writeCatalog(t, file, persistedCatalog{
+		Version:     persistedCatalogVersion,
+		RefreshedAt: time.Now().Add(-time.Hour),
+		Endpoints: []linodego.ObjectStorageEndpoint{{
+			Region:     "us-test",
+			S3Endpoint: ptr("us-test-1.linodeobjects.com"),
+		}},
+	})
+
+	cache := New(discardLog, nil, DefaultTTL, WithPersistence(file, 2*time.Hour))
+	if err := cache.Load(t.Context()); err != nil {
+		t.Fatalf("loading persisted cache failed: %v", err)
+	}
+
+	// Age the loaded catalog past the maximum staleness.
+	cache.maxStaleness = time.Minute
+
+	if endpoints := cache.Endpoints(); len(endpoints) != 0 {
+		t.Errorf("expected expired stale catalog to be dropped, got %+v", endpoints)
+	}
+}
+
+func writeCatalog(t *testing.T, file string, catalog persistedCatalog) {
+	t.Helper()
+
+	raw, err := json.Marshal(catalog)
+	if err != nil {
+		t.Fatalf("failed to encode catalog: %v", err)
+	}
+
+	if err := os.WriteFile(file, raw, 0o600); err != nil {
+		t.Fatalf("failed to write catalog: %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/linode/linodego/v2"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+)
+
+// ErrUnknownRegion is returned for regions that do not support Object Storage.
+var ErrUnknownRegion = errors.New("region does not support object storage")
+
+// Regions resolves regions provided by users.
+type Regions interface {
+	Resolve(ctx context.Context, region string) (string, error)
+}
+
+// RegionCache caches the regions supporting Object Storage. Legacy Object Storage cluster IDs,
+// e.g. "us-east-1", are resolved to their regions with the endpoint catalog.
+type RegionCache struct {
+	sync.RWMutex
+
+	log       *slog.Logger
+	ttl       time.Duration
+	client    linodeclient.Client
+	endpoints Cache
+	regions   map[string]struct{}
+
+	refreshes refreshGroup
+}
+
+var _ Regions = (*RegionCache)(nil)
+
+func NewRegionCache(logger *slog.Logger, client linodeclient.Client, endpoints Cache, cacheTTL time.Duration) *RegionCache {
+	if cacheTTL == 0 || cacheTTL < DefaultTTL {
+		cacheTTL = DefaultTTL
+	}
+
+	return &RegionCache{
+		log:       logger,
+		ttl:       cacheTTL,
+		client:    client,
+		endpoints: endpoints,
+		regions:   make(map[string]struct{}),
+	}
+}
+
+func (c *RegionCache) Start(ctx context.Context) error {
+	ticker := time.NewTicker(c.ttl)
+	defer ticker.Stop()
+
+	if err := c.Refresh(ctx); err != nil {
+		c.log.ErrorContext(ctx, "Failed to refresh region cache", "error", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			c.refreshWithTimeout(ctx)
+		}
+	}
+}
+
+func (c *RegionCache) refreshWithTimeout(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	if err := c.Refresh(ctx); err != nil {
+		c.log.ErrorContext(ctx, "Failed to refresh region cache", "error", err)
+	}
+}
+
+// Refresh replaces the cached regions with the regions listed by the Linode API.
+// Concurrent calls share a single refresh.
+func (c *RegionCache) Refresh(ctx context.Context) error {
+	return c.refreshes.do(ctx, c.refresh)
+}
+
+func (c *RegionCache) refresh(ctx context.Context) error {
+	c.log.DebugContext(ctx, "Syncing region cache")
+
+	regions, err := c.client.ListRegions(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to list regions: %w", err)
+	}
+
+	supported := make(map[string]struct{}, len(regions))
+	for _, region := range regions {
+		if slices.Contains(region.Capabilities, string(linodego.CapabilityObjectStorage)) {
+			supported[region.ID] = struct{}{}
+		}
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.regions = supported
+
+	return nil
+}
+
+// Resolve returns the region supporting Object Storage for the region or the legacy
+// cluster ID. Unknown regions trigger a refresh, unless the regions were refreshed recently,
+// and return ErrUnknownRegion, suggesting the closest match.
+func (c *RegionCache) Resolve(ctx context.Context, region string) (string, error) {
+	if resolved, ok := c.resolve(region); ok {
+		return resolved, nil
+	}
+
+	if !c.refreshes.recent(missRefreshInterval) {
+		if err := c.Refresh(ctx); err != nil {
+			return "", err
+		}
+
+		if resolved, ok := c.resolve(region); ok {
+			return resolved, nil
+		}
+	}
+
+	c.RLock()
+	candidates := make([]string, 0, len(c.regions))
+	for candidate := range c.regions {
+		candidates = append(candidates, candidate)
+	}
+	c.RUnlock()
+
+	for _, endpoint := range c.endpoints.Endpoints() {
+		if cluster, _, _ := strings.Cut(endpoint.Hostname(), "."); cluster != "" && cluster != endpoint.Region {
+			candidates = append(candidates, cluster)
+		}
+	}
+
+	if suggestion := closest(region, candidates); suggestion != "" {
+		return "", fmt.Errorf("%w: %s, did you mean %s?", ErrUnknownRegion, region, suggestion)
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownRegion, region)
+}
+
+func (c *RegionCache) resolve(region string) (string, bool) {
+	c.RLock()
+	_, ok := c.regions[region]
+	c.RUnlock()
+
+	if ok {
+		return region, true
+	}
+
+	if endpoint, ok := ClusterEndpoint(c.endpoints.Endpoints(), region); ok {
+		return endpoint.Region, true
+	}
+
+	return "", false
+}
+
+// closest returns the candidate with the smallest edit distance to s, if the distance
+// is small enough for the candidate to be a likely typo. Ties resolve alphabetically.
+func closest(s string, candidates []string) string {
+	slices.Sort(candidates)
+
+	best, bestDistance := "", max(2, len(s)/3)+1
+	for _, candidate := range candidates {
+		if d := levenshtein(s, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,219 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego/v2"
+	"go.uber.org/mock/gomock"
+
+	"github.com/linode/linode-cosi-driver/testing/mock"
+)
+
+func TestRegionCacheResolve(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockLinodeClient(ctrl)
+	mockClient.EXPECT().
+		ListRegions(gomock.Any(), gomock.Any()).
+		Return([]linodego.Region{
+			{ID: "us-east", Capabilities: []string{"Linodes", string(linodego.CapabilityObjectStorage)}},
+			{ID: "us-ord", Capabilities: []string{string(linodego.CapabilityObjectStorage)}},
+			{ID: "ap-west", Capabilities: []string{"Linodes"}},
+		}, nil).
+		Times(1)
+
+	regions := NewRegionCache(discardLog, mockClient, testEndpoints(t), DefaultTTL)
+
+	for _, tc := range []struct {
+		region     string
+		expected   string
+		err        error
+		suggestion string
+	}{
+		{region: "us-east", expected: "us-east"},
+		{region: "us-east-1", expected: "us-east"},
+		{region: "us-ord-1", expected: "us-ord"},
+		{region: "us-oed", err: ErrUnknownRegion, suggestion: "did you mean us-ord?"},
+		{region: "ap-west", err: ErrUnknownRegion},
+		{region: "mars-north", err: ErrUnknownRegion},
+	} {
+		// Resolve refreshes the cache once, on first use. Later misses within
+		// missRefreshInterval do not refresh again.
+		actual, err := regions.Resolve(t.Context(), tc.region)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s: expected error %v, got: %v", tc.region, tc.err, err)
+		}
+		if actual != tc.expected {
+			t.Errorf("%s: expected region %q, got %q", tc.region, tc.expected, actual)
+		}
+		if tc.suggestion != "" && (err == nil || !strings.Contains(err.Error(), tc.suggestion)) {
+			t.Errorf("%s: expected error to suggest %q, got: %v", tc.region, tc.suggestion, err)
+		}
+	}
+}
+
+func TestRegionCacheResolveAddedRegion(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockLinodeClient(ctrl)
+	gomock.InOrder(
+		mockClient.EXPECT().
+			ListRegions(gomock.Any(), gomock.Any()).
+			Return([]linodego.Region{
+				{ID: "us-east", Capabilities: []string{string(linodego.CapabilityObjectStorage)}},
+			}, nil),
+		mockClient.EXPECT().
+			ListRegions(gomock.Any(), gomock.Any()).
+			Return([]linodego.Region{
+				{ID: "us-east", Capabilities: []string{string(linodego.CapabilityObjectStorage)}},
+				{ID: "us-ord", Capabilities: []string{string(linodego.CapabilityObjectStorage)}},
+			}, nil),
+	)
+
+	regions := NewRegionCache(discardLog, mockClient, testEndpoints(t), DefaultTTL)
+	if err := regions.Refresh(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Pretend the startup refresh happened a while ago.
+	regions.refreshes.mu.Lock()
+	regions.refreshes.last = time.Now().Add(-missRefreshInterval)
+	regions.refreshes.mu.Unlock()
+
+	actual, err := regions.Resolve(t.Context(), "us-ord")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actual != "us-ord" {
+		t.Errorf("expected region %q, got %q", "us-ord", actual)
+	}
+}
+
+func TestRegionCacheResolveAfterFailedRefresh(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockLinodeClient(ctrl)
+	gomock.InOrder(
+		mockClient.EXPECT().
+			ListRegions(gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("api unavailable")),
+		mockClient.EXPECT().
+			ListRegions(gomock.Any(), gomock.Any()).
+			Return([]linodego.Region{
+				{ID: "us-ord", Capabilities: []string{string(linodego.CapabilityObjectStorage)}},
+			}, nil),
+	)
+
+	regions := NewRegionCache(discardLog, mockClient, testEndpoints(t), DefaultTTL)
+	if err := regions.Refresh(t.Context()); err == nil {
+		t.Fatalf("expected startup refresh to fail")
+	}
+
+	// The failed refresh does not count as recent, so the miss refreshes again.
+	actual, err := regions.Resolve(t.Context(), "us-ord")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actual != "us-ord" {
+		t.Errorf("expected region %q, got %q", "us-ord", actual)
+	}
+}
+
+func TestRegionCacheResolveConcurrent(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockLinodeClient(ctrl)
+	mockClient.EXPECT().
+		ListRegions(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, *linodego.ListOptions) ([]linodego.Region, error) {
+			<-release
+			return []linodego.Region{
+				{ID: "us-east", Capabilities: []string{string(linodego.CapabilityObjectStorage)}},
+			}, nil
+		}).
+		Times(1)
+
+	regions := NewRegionCache(discardLog, mockClient, testEndpoints(t), DefaultTTL)
+
+	const callers = 8
+
+	var wg sync.WaitGroup
+	for range callers {
+		wg.Go(func() {
+			if _, err := regions.Resolve(t.Context(), "us-east"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	// Give the callers time to join the refresh in flight.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+}
+
+// testEndpoints returns an endpoint cache holding the endpoints of us-east and us-ord.
+func testEndpoints(t *testing.T) *EndpointCache {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	mockClient := mock.NewMockLinodeClient(ctrl)
+	mockClient.EXPECT().
+		ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageEndpoint{
+			{Region: "us-east", S3Endpoint: ptr("us-east-1.linodeobjects.com")},
+			{Region: "us-ord", S3Endpoint: ptr("us-ord-1.linodeobjects.com")},
+		}, nil).
+		Times(1)
+
+	endpoints := New(discardLog, mockClient, DefaultTTL)
+	if err := endpoints.Refresh(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return endpoints
+}
+
+func TestLevenshtein(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"us-ord", "us-ord", 0},
+		{"us-ord", "us-oed", 1},
+		{"us-ord", "us-or", 1},
+		{"kitten", "sitting", 3},
+	} {
+		if d := levenshtein(tc.a, tc.b); d != tc.expected {
+			t.Errorf("expected distance between %q and %q to be %d, got %d", tc.a, tc.b, tc.expected, d)
+		}
+	}
+}
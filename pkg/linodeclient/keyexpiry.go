@@ -0,0 +1,141 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linodeclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/linode/linodego/v2"
+
+	"github.com/linode/linode-cosi-driver/pkg/metrics"
+)
+
+// GrantKeyLabelPrefix is the label prefix of keys granted for BucketAccess objects. COSI names
+// the accounts it requests after the UID of the BucketAccess, e.g. "ba-<uid>".
+const GrantKeyLabelPrefix = "ba-"
+
+const (
+	// keyExpirySeparator separates the name of a time-limited key from its expiry in the label.
+	// The expiry is the Unix time in base 36, so that labels of BucketAccess UIDs fit the 50
+	// characters allowed for key labels.
+	keyExpirySeparator = "@"
+	// keyExpiryWidth is the width of the expiry, zero padded. Seven base 36 digits cover
+	// Unix times until the year 4453.
+	keyExpiryWidth = 7
+	// legacyKeyExpiryWidth is the width of the expiry of labels written by earlier drivers,
+	// which are still recognized so that their keys are deleted once they expire.
+	legacyKeyExpiryWidth = 6
+)
+
+// KeyLabelWithExpiry returns the label of a key named name, expiring at expiresAt.
+func KeyLabelWithExpiry(name string, expiresAt time.Time) string {
+	expiry := strconv.FormatInt(expiresAt.Unix(), 36)
+	if len(expiry) < keyExpiryWidth {
+		expiry = strings.Repeat("0", keyExpiryWidth-len(expiry)) + expiry
+	}
+
+	return name + keyExpirySeparator + expiry
+}
+
+// KeyExpiry returns the name and expiry recorded in the label of a key. Keys without
+// expiry are returned with their label as name. Only expiries of exactly keyExpiryWidth
+// or legacyKeyExpiryWidth digits are recognized, so that labels such as "ci@prod" are not
+// mistaken for expired keys.
+func KeyExpiry(label string) (string, time.Time, bool) {
+	name, expiry, found := strings.Cut(label, keyExpirySeparator)
+	if !found || (len(expiry) != keyExpiryWidth && len(expiry) != legacyKeyExpiryWidth) {
+		return label, time.Time{}, false
+	}
+
+	unix, err := strconv.ParseInt(expiry, 36, 64)
+	if err != nil {
+		return label, time.Time{}, false
+	}
+
+	return name, time.Unix(unix, 0).UTC(), true
+}
+
+// KeyReaper deletes time-limited keys granted for BucketAccess objects once they expired.
+// Other keys of the account are never deleted.
+type KeyReaper struct {
+	log    *slog.Logger
+	client Client
+}
+
+func NewKeyReaper(logger *slog.Logger, client Client) *KeyReaper {
+	return &KeyReaper{log: logger, client: client}
+}
+
+// Start deletes expired keys every interval until the context is canceled.
+func (r *KeyReaper) Start(ctx context.Context, interval time.Duration) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			if _, err := r.Reap(ctx); err != nil {
+				r.log.ErrorContext(ctx, "Failed to delete expired object storage keys", "error", err)
+			}
+
+			timer.Reset(interval)
+		}
+	}
+}
+
+// Reap deletes the expired keys granted by the driver, and returns the number of deleted keys.
+// Keys already deleted are not reported as errors.
+func (r *KeyReaper) Reap(ctx context.Context) (int, error) {
+	keys, err := r.client.ListObjectStorageKeys(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list object storage keys: %w", err)
+	}
+
+	now := time.Now()
+	deleted := 0
+
+	var errs error
+
+	for _, key := range keys {
+		name, expiresAt, ok := KeyExpiry(key.Label)
+		if !ok || !strings.HasPrefix(name, GrantKeyLabelPrefix) || now.Before(expiresAt) {
+			continue
+		}
+
+		if err := r.client.DeleteObjectStorageKey(ctx, key.ID); err != nil && !linodego.IsNotFound(err) {
+			errs = errors.Join(errs, fmt.Errorf("failed to delete key %d: %w", key.ID, err))
+			continue
+		}
+
+		r.log.InfoContext(ctx, "Expired object storage key deleted",
+			slog.Int("key_id", key.ID),
+			slog.String("key_label", key.Label),
+			slog.Time("expired_at", expiresAt),
+		)
+		metrics.ExpiredKeysDeleted.Inc()
+
+		deleted++
+	}
+
+	return deleted, errs
+}
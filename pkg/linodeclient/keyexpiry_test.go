@@ -0,0 +1,105 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linodeclient_test
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego/v2"
+	"go.uber.org/mock/gomock"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/testing/mock"
+)
+
+func TestKeyExpiry(t *testing.T) {
+	t.Parallel()
+
+	expiresAt := time.Date(2030, time.January, 2, 3, 4, 5, 0, time.UTC)
+	name := "ba-0f8fad5b-d9cb-469f-a165-70867728950e"
+
+	label := linodeclient.KeyLabelWithExpiry(name, expiresAt)
+	if len(label) > 50 {
+		t.Errorf("expected label to fit 50 characters, got %d: %q", len(label), label)
+	}
+
+	gotName, gotExpiry, ok := linodeclient.KeyExpiry(label)
+	if !ok || gotName != name || !gotExpiry.Equal(expiresAt) {
+		t.Errorf("expected %q expiring at %v, got %q expiring at %v (%t)", name, expiresAt, gotName, gotExpiry, ok)
+	}
+
+	// Expiries after 2038 need seven digits, labels of earlier drivers have six.
+	for _, expiresAt := range []time.Time{
+		time.Date(2040, time.March, 4, 5, 6, 7, 0, time.UTC),
+		time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		label := linodeclient.KeyLabelWithExpiry(name, expiresAt)
+		if gotName, gotExpiry, ok := linodeclient.KeyExpiry(label); !ok || gotName != name || !gotExpiry.Equal(expiresAt) {
+			t.Errorf("expected %q expiring at %v, got %q expiring at %v (%t)", name, expiresAt, gotName, gotExpiry, ok)
+		}
+	}
+
+	legacy := name + "@" + strconv.FormatInt(expiresAt.Unix(), 36)
+	if gotName, gotExpiry, ok := linodeclient.KeyExpiry(legacy); !ok || gotName != name || !gotExpiry.Equal(expiresAt) {
+		t.Errorf("expected legacy label %q to expire at %v, got %q expiring at %v (%t)", legacy, expiresAt, gotName, gotExpiry, ok)
+	}
+
+	for _, label := range []string{name, "cosi-bucket-1", "user@example.com", "ci@prod", "ba-x@zzzzzzzz"} {
+		if gotName, _, ok := linodeclient.KeyExpiry(label); ok || gotName != label {
+			t.Errorf("expected label %q without expiry, got %q (%t)", label, gotName, ok)
+		}
+	}
+}
+
+func TestKeyReaper(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	errDelete := errors.New("delete failed")
+
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockLinodeClient(ctrl)
+	client.EXPECT().
+		ListObjectStorageKeys(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageKey{
+			{ID: 1, Label: "ba-permanent"},
+			{ID: 2, Label: linodeclient.KeyLabelWithExpiry("ba-expired", now.Add(-time.Minute))},
+			{ID: 3, Label: linodeclient.KeyLabelWithExpiry("ba-valid", now.Add(time.Hour))},
+			{ID: 4, Label: linodeclient.KeyLabelWithExpiry("ba-revoked", now.Add(-time.Hour))},
+			{ID: 5, Label: linodeclient.KeyLabelWithExpiry("ba-failing", now.Add(-time.Hour))},
+			// Keys not granted by the driver are kept, even when their label looks like an expiry.
+			{ID: 6, Label: "ci@prod"},
+			{ID: 7, Label: "backup@nightly"},
+			{ID: 8, Label: linodeclient.KeyLabelWithExpiry("deploy", now.Add(-time.Hour))},
+		}, nil)
+	client.EXPECT().DeleteObjectStorageKey(gomock.Any(), gomock.Eq(2)).Return(nil)
+	// Keys revoked since the listing are not reported as errors.
+	client.EXPECT().DeleteObjectStorageKey(gomock.Any(), gomock.Eq(4)).Return(&linodego.Error{Code: http.StatusNotFound})
+	client.EXPECT().DeleteObjectStorageKey(gomock.Any(), gomock.Eq(5)).Return(errDelete)
+
+	deleted, err := linodeclient.NewKeyReaper(slog.New(slog.DiscardHandler), client).Reap(t.Context())
+	if !errors.Is(err, errDelete) {
+		t.Errorf("expected error %v, got %v", errDelete, err)
+	}
+
+	if deleted != 2 {
+		t.Errorf("expected 2 deleted keys, got %d", deleted)
+	}
+}
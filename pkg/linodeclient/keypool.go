@@ -0,0 +1,348 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linodeclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/linode/linodego/v2"
+)
+
+const (
+	// LeaseGrace is how long a lease may be held after its key expired, before
+	// the key is revoked regardless of the lease.
+	LeaseGrace = 5 * time.Minute
+
+	minSweepInterval = time.Second
+	revokeTimeout    = 30 * time.Second
+)
+
+// ErrKeyPoolClosed is returned when acquiring a lease from a closed pool.
+var ErrKeyPoolClosed = errors.New("key pool is closed")
+
+// KeyPool leases ephemeral Object Storage keys, scoped to single buckets. Leases of the same scope share a key for
+// the lifetime of the pool. Expired keys are not leased anymore, and are revoked once
+// all of their leases are released, or LeaseGrace after their expiry. Without a lifetime,
+// every lease gets its own key, revoked when the lease is released.
+type KeyPool struct {
+	log      *slog.Logger
+	client   Client
+	lifetime time.Duration
+
+	mu       sync.Mutex
+	closed   bool
+	current  map[KeyScope]*pooledKey
+	keys     map[*pooledKey]struct{}
+	onRevoke []func(*linodego.ObjectStorageKey)
+}
+
+type pooledKey struct {
+	scope   KeyScope
+	ready   chan struct{}
+	key     *linodego.ObjectStorageKey
+	err     error
+	expires time.Time
+	refs    int
+	revoked bool
+}
+
+// Lease is a reference to a pooled key. It must be released once the key is not used anymore.
+type Lease struct {
+	Key *linodego.ObjectStorageKey
+
+	pool *KeyPool
+	pk   *pooledKey
+	once sync.Once
+}
+
+func NewKeyPool(logger *slog.Logger, client Client, lifetime time.Duration) *KeyPool {
+	return &KeyPool{
+		log:      logger,
+		client:   client,
+		lifetime: lifetime,
+		current:  make(map[KeyScope]*pooledKey),
+		keys:     make(map[*pooledKey]struct{}),
+	}
+}
+
+// OnRevoke registers a function called after a key was revoked.
+func (p *KeyPool) OnRevoke(fn func(*linodego.ObjectStorageKey)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.onRevoke = append(p.onRevoke, fn)
+}
+
+// Acquire leases a key of the scope, creating it if no unexpired key exists.
+func (p *KeyPool) Acquire(ctx context.Context, scope KeyScope) (*Lease, error) {
+	opts, err := EphemeralKeyOptions(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, ErrKeyPoolClosed
+	}
+
+	pk, ok := p.current[scope]
+	if ok && pk.key != nil && !time.Now().Before(pk.expires) {
+		delete(p.current, scope)
+		ok = false
+	}
+
+	if !ok {
+		pk = &pooledKey{scope: scope, ready: make(chan struct{})}
+		p.keys[pk] = struct{}{}
+
+		if p.lifetime > 0 {
+			p.current[scope] = pk
+		}
+	}
+
+	pk.refs++
+	p.mu.Unlock()
+
+	if !ok {
+		p.create(ctx, pk, opts)
+	}
+
+	select {
+	case <-pk.ready:
+	case <-ctx.Done():
+		p.releaseFailed(context.WithoutCancel(ctx), pk)
+		return nil, ctx.Err()
+	}
+
+	if pk.err != nil {
+		p.releaseFailed(ctx, pk)
+		return nil, pk.err
+	}
+
+	return &Lease{Key: pk.key, pool: p, pk: pk}, nil
+}
+
+// Release returns the lease to the pool, revoking the key if it is not used anymore.
+// Releasing a lease more than once has no effect.
+func (l *Lease) Release(ctx context.Context) error {
+	var err error
+
+	l.once.Do(func() {
+		err = l.pool.release(ctx, l.pk)
+	})
+
+	return err
+}
+
+func (p *KeyPool) create(ctx context.Context, pk *pooledKey, opts linodego.ObjectStorageKeyCreateOptions) {
+	defer close(pk.ready)
+
+	p.log.Info(fmt.Sprintf("Generating new ephemeral key: %s", opts.Label))
+
+	key, err := p.client.CreateObjectStorageKey(ctx, opts)
+
+	p.mu.Lock()
+	closed := p.closed
+
+	if err == nil && !closed {
+		pk.key, pk.expires = key, time.Now().Add(p.lifetime)
+		p.mu.Unlock()
+
+		return
+	}
+
+	if err != nil {
+		pk.err = fmt.Errorf("unable to create object storage key: %w. requested region was: %s", err, pk.scope.Region)
+	} else {
+		pk.err = ErrKeyPoolClosed
+	}
+
+	pk.revoked = true
+
+	delete(p.keys, pk)
+	if p.current[pk.scope] == pk {
+		delete(p.current, pk.scope)
+	}
+	p.mu.Unlock()
+
+	if err == nil {
+		// The pool was closed while the key was created, so Close did not revoke it.
+		if err := p.revoke(context.WithoutCancel(ctx), key); err != nil {
+			p.log.ErrorContext(ctx, "Failed to revoke key created after close", "error", err)
+		}
+	}
+}
+
+func (p *KeyPool) release(ctx context.Context, pk *pooledKey) error {
+	p.mu.Lock()
+	pk.refs--
+	revoke := pk.refs == 0 && p.retire(pk)
+	p.mu.Unlock()
+
+	if !revoke {
+		return nil
+	}
+
+	return p.revoke(ctx, pk.key)
+}
+
+// Expire stops leasing the current keys of the bucket. The keys are revoked right away,
+// or once all of their leases are released.
+func (p *KeyPool) Expire(ctx context.Context, region, bucket string) error {
+	var expired []*pooledKey
+
+	p.mu.Lock()
+	for scope, pk := range p.current {
+		if scope.Region != region || scope.Bucket != bucket || pk.key == nil {
+			continue
+		}
+
+		delete(p.current, scope)
+
+		if pk.refs == 0 && p.retire(pk) {
+			expired = append(expired, pk)
+		}
+	}
+	p.mu.Unlock()
+
+	var errs error
+	for _, pk := range expired {
+		errs = errors.Join(errs, p.revoke(ctx, pk.key))
+	}
+
+	return errs
+}
+
+// releaseFailed releases the reference of a failed acquisition.
+func (p *KeyPool) releaseFailed(ctx context.Context, pk *pooledKey) {
+	if err := p.release(ctx, pk); err != nil {
+		p.log.ErrorContext(ctx, "Failed to revoke ephemeral key", "error", err)
+	}
+}
+
+// retire marks expired keys as revoked, reporting whether the caller has to revoke the key.
+// It must be called with the lock held.
+func (p *KeyPool) retire(pk *pooledKey) bool {
+	if pk.revoked || pk.key == nil || (p.current[pk.scope] == pk && time.Now().Before(pk.expires)) {
+		return false
+	}
+
+	pk.revoked = true
+
+	delete(p.keys, pk)
+	if p.current[pk.scope] == pk {
+		delete(p.current, pk.scope)
+	}
+
+	return true
+}
+
+func (p *KeyPool) revoke(ctx context.Context, key *linodego.ObjectStorageKey) error {
+	if err := p.client.DeleteObjectStorageKey(ctx, key.ID); err != nil && !linodego.IsNotFound(err) {
+		return fmt.Errorf("unable to revoke object storage key %s: %w", key.Label, err)
+	}
+
+	p.mu.Lock()
+	hooks := p.onRevoke
+	p.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook(key)
+	}
+
+	return nil
+}
+
+// Start revokes expired keys until the context is canceled.
+func (p *KeyPool) Start(ctx context.Context) error {
+	if p.lifetime <= 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	ticker := time.NewTicker(max(p.lifetime/4, minSweepInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			p.sweep(ctx)
+		}
+	}
+}
+
+// sweep revokes expired keys without leases, and keys leased for longer than LeaseGrace past their expiry.
+func (p *KeyPool) sweep(ctx context.Context) {
+	now := time.Now()
+
+	var expired []*pooledKey
+
+	p.mu.Lock()
+	for pk := range p.keys {
+		if pk.key == nil || now.Before(pk.expires) {
+			continue
+		}
+
+		if p.current[pk.scope] == pk {
+			delete(p.current, pk.scope)
+		}
+
+		if (pk.refs == 0 || now.After(pk.expires.Add(LeaseGrace))) && p.retire(pk) {
+			expired = append(expired, pk)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, pk := range expired {
+		rctx, cancel := context.WithTimeout(ctx, revokeTimeout)
+		if err := p.revoke(rctx, pk.key); err != nil {
+			p.log.ErrorContext(ctx, "Failed to revoke expired key", "error", err)
+		}
+		cancel()
+	}
+}
+
+// Close revokes all keys, leased or not, and refuses new leases.
+func (p *KeyPool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+
+	keys := make([]*pooledKey, 0, len(p.keys))
+	for pk := range p.keys {
+		if pk.key != nil && !pk.revoked {
+			pk.revoked = true
+			keys = append(keys, pk)
+		}
+	}
+
+	clear(p.keys)
+	clear(p.current)
+	p.mu.Unlock()
+
+	var errs error
+	for _, pk := range keys {
+		errs = errors.Join(errs, p.revoke(ctx, pk.key))
+	}
+
+	return errs
+}
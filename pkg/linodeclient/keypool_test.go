@@ -0,0 +1,251 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linodeclient_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego/v2"
+	"go.uber.org/mock/gomock"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/testing/mock"
+)
+
+var testScope = linodeclient.KeyScope{
+	Region:      "us-test",
+	Bucket:      "test-bucket",
+	Permissions: linodeclient.KeyPermissionsReadWrite,
+}
+
+// expectKeys returns a mock creating keys with increasing IDs.
+func expectKeys(t *testing.T, creates int) *mock.MockLinodeClient {
+	t.Helper()
+
+	var id atomic.Int64
+
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockLinodeClient(ctrl)
+	client.EXPECT().
+		CreateObjectStorageKey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, opts linodego.ObjectStorageKeyCreateOptions) (*linodego.ObjectStorageKey, error) {
+			n := int(id.Add(1))
+			return &linodego.ObjectStorageKey{ID: n, Label: opts.Label, AccessKey: opts.Label}, nil
+		}).
+		Times(creates)
+
+	return client
+}
+
+func TestKeyPoolReusesKeys(t *testing.T) {
+	t.Parallel()
+
+	client := expectKeys(t, 1)
+	pool := linodeclient.NewKeyPool(slog.New(slog.DiscardHandler), client, time.Hour)
+
+	first, err := pool.Acquire(t.Context(), testScope)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second, err := pool.Acquire(t.Context(), testScope)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first.Key.ID != second.Key.ID {
+		t.Errorf("expected leases to share the key, got %d and %d", first.Key.ID, second.Key.ID)
+	}
+
+	for _, lease := range []*linodeclient.Lease{first, second, second} {
+		if err := lease.Release(t.Context()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// The key is revoked only at shutdown.
+	client.EXPECT().DeleteObjectStorageKey(gomock.Any(), first.Key.ID).Return(nil)
+
+	if err := pool.Close(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := pool.Acquire(t.Context(), testScope); !errors.Is(err, linodeclient.ErrKeyPoolClosed) {
+		t.Errorf("expected error %v, got %v", linodeclient.ErrKeyPoolClosed, err)
+	}
+}
+
+func TestKeyPoolWithoutLifetime(t *testing.T) {
+	t.Parallel()
+
+	client := expectKeys(t, 2)
+	client.EXPECT().DeleteObjectStorageKey(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	pool := linodeclient.NewKeyPool(slog.New(slog.DiscardHandler), client, 0)
+
+	for range 2 {
+		lease, err := pool.Acquire(t.Context(), testScope)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := lease.Release(t.Context()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestKeyPoolExpiry(t *testing.T) {
+	t.Parallel()
+
+	client := expectKeys(t, 2)
+	pool := linodeclient.NewKeyPool(slog.New(slog.DiscardHandler), client, 10*time.Millisecond)
+
+	expired, err := pool.Acquire(t.Context(), testScope)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	fresh, err := pool.Acquire(t.Context(), testScope)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fresh.Key.ID == expired.Key.ID {
+		t.Fatalf("expected expired key not to be leased")
+	}
+
+	// The expired key is revoked once its last lease is released.
+	client.EXPECT().DeleteObjectStorageKey(gomock.Any(), expired.Key.ID).Return(nil)
+
+	if err := expired.Release(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestKeyPoolExpire(t *testing.T) {
+	t.Parallel()
+
+	client := expectKeys(t, 1)
+	pool := linodeclient.NewKeyPool(slog.New(slog.DiscardHandler), client, time.Hour)
+
+	var revoked []int
+	pool.OnRevoke(func(key *linodego.ObjectStorageKey) {
+		revoked = append(revoked, key.ID)
+	})
+
+	lease, err := pool.Acquire(t.Context(), testScope)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The key is still leased, so it is not revoked yet.
+	if err := pool.Expire(t.Context(), testScope.Region, testScope.Bucket); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	client.EXPECT().DeleteObjectStorageKey(gomock.Any(), lease.Key.ID).Return(nil)
+
+	if err := lease.Release(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(revoked) != 1 || revoked[0] != lease.Key.ID {
+		t.Errorf("expected revocation of key %d to be reported, got %v", lease.Key.ID, revoked)
+	}
+}
+
+func TestKeyPoolDeduplicatesCreation(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockLinodeClient(ctrl)
+	client.EXPECT().
+		CreateObjectStorageKey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, linodego.ObjectStorageKeyCreateOptions) (*linodego.ObjectStorageKey, error) {
+			// Keep the creation in flight long enough for all acquisitions to join it.
+			time.Sleep(100 * time.Millisecond)
+			return &linodego.ObjectStorageKey{ID: 1}, nil
+		})
+
+	pool := linodeclient.NewKeyPool(slog.New(slog.DiscardHandler), client, time.Hour)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			lease, err := pool.Acquire(t.Context(), testScope)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if err := lease.Release(t.Context()); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestKeyPoolRejectsUnscopedKeys(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	pool := linodeclient.NewKeyPool(slog.New(slog.DiscardHandler), mock.NewMockLinodeClient(ctrl), time.Hour)
+
+	if _, err := pool.Acquire(t.Context(), linodeclient.KeyScope{Region: "us-test"}); !errors.Is(err, linodeclient.ErrInvalidKeyScope) {
+		t.Errorf("expected error %v, got %v", linodeclient.ErrInvalidKeyScope, err)
+	}
+}
+
+func TestKeyPoolCreationFailure(t *testing.T) {
+	t.Parallel()
+
+	errCreate := errors.New("create failed")
+
+	ctrl := gomock.NewController(t)
+	client := mock.NewMockLinodeClient(ctrl)
+	gomock.InOrder(
+		client.EXPECT().
+			CreateObjectStorageKey(gomock.Any(), gomock.Any()).
+			Return(nil, errCreate),
+		client.EXPECT().
+			CreateObjectStorageKey(gomock.Any(), gomock.Any()).
+			Return(&linodego.ObjectStorageKey{ID: 1}, nil),
+	)
+
+	pool := linodeclient.NewKeyPool(slog.New(slog.DiscardHandler), client, time.Hour)
+
+	if _, err := pool.Acquire(t.Context(), testScope); !errors.Is(err, errCreate) {
+		t.Fatalf("expected error %v, got %v", errCreate, err)
+	}
+
+	// Failed creations are not cached.
+	if _, err := pool.Acquire(t.Context(), testScope); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -16,8 +16,8 @@ package linodeclient
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log/slog"
 
 	"github.com/google/uuid"
 	"github.com/linode/linodego/v2"
@@ -27,6 +27,7 @@ import (
 type Client interface {
 	CreateObjectStorageBucket(context.Context, linodego.ObjectStorageBucketCreateOptions) (*linodego.ObjectStorageBucket, error)
 	GetObjectStorageBucket(context.Context, string, string) (*linodego.ObjectStorageBucket, error)
+	ListObjectStorageBuckets(context.Context, *linodego.ListOptions) ([]linodego.ObjectStorageBucket, error)
 	DeleteObjectStorageBucket(context.Context, string, string) error
 
 	GetObjectStorageBucketAccess(context.Context, string, string) (*linodego.ObjectStorageBucketAccess, error)
@@ -38,6 +39,8 @@ type Client interface {
 	DeleteObjectStorageKey(context.Context, int) error
 
 	ListObjectStorageEndpoints(context.Context, *linodego.ListOptions) ([]linodego.ObjectStorageEndpoint, error)
+
+	ListRegions(context.Context, *linodego.ListOptions) ([]linodego.Region, error)
 }
 
 // NewLinodeClient takes userAgent prefix after initial validation
@@ -54,30 +57,43 @@ func NewLinodeClient(ua string) (*linodego.Client, error) {
 	return linodeClient, nil
 }
 
-func NewEphemeralS3Credentials(
-	ctx context.Context,
-	slog *slog.Logger,
-	client Client,
-	region string,
-) (*linodego.ObjectStorageKey, func(context.Context) error, error) {
-	if region == "" {
-		return nil, nil, fmt.Errorf("region is required for ephemeral object storage credentials")
-	}
+// Permissions of ephemeral keys.
+const (
+	KeyPermissionsReadOnly  = "read_only"
+	KeyPermissionsReadWrite = "read_write"
+)
 
-	keyLabel := fmt.Sprintf("cosi-%s", uuid.New().String())
-	slog.Info(fmt.Sprintf("Generating new ephemeral key: %s", keyLabel))
+// EphemeralKeyLabelPrefix is the label prefix of ephemeral keys.
+const EphemeralKeyLabelPrefix = "cosi-bucket-"
 
-	creds, err := client.CreateObjectStorageKey(ctx, linodego.ObjectStorageKeyCreateOptions{
-		Label:   keyLabel,
-		Regions: []string{region},
-	})
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to create object storage key: %w. requested region was: %s", err, region)
+// ErrInvalidKeyScope is returned for ephemeral keys not scoped to a single bucket.
+var ErrInvalidKeyScope = errors.New("ephemeral object storage credentials must be scoped to a single bucket")
+
+// KeyScope is the scope of an ephemeral key: a single bucket, with the given permissions.
+type KeyScope struct {
+	Region      string
+	Bucket      string
+	Permissions string
+}
+
+// EphemeralKeyOptions returns the options of an ephemeral key limited to the scope. All
+// ephemeral keys are created with these options, so that they never grant access beyond
+// a single bucket.
+func EphemeralKeyOptions(scope KeyScope) (linodego.ObjectStorageKeyCreateOptions, error) {
+	if scope.Region == "" || scope.Bucket == "" {
+		return linodego.ObjectStorageKeyCreateOptions{}, fmt.Errorf("%w: region and bucket are required", ErrInvalidKeyScope)
 	}
 
-	cleanup := func(cctx context.Context) error {
-		return client.DeleteObjectStorageKey(cctx, creds.ID)
+	if scope.Permissions != KeyPermissionsReadOnly && scope.Permissions != KeyPermissionsReadWrite {
+		return linodego.ObjectStorageKeyCreateOptions{}, fmt.Errorf("%w: unknown permissions %q", ErrInvalidKeyScope, scope.Permissions)
 	}
 
-	return creds, cleanup, nil
+	return linodego.ObjectStorageKeyCreateOptions{
+		Label: EphemeralKeyLabelPrefix + uuid.NewString(),
+		BucketAccess: []linodego.ObjectStorageKeyBucketAccessCreateOptions{{
+			Region:      scope.Region,
+			BucketName:  scope.Bucket,
+			Permissions: scope.Permissions,
+		}},
+	}, nil
 }
@@ -16,14 +16,9 @@ package linodeclient_test
 
 import (
 	"errors"
-	"log/slog"
 	"testing"
 
-	"github.com/linode/linodego/v2"
-	"go.uber.org/mock/gomock"
-
 	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
-	"github.com/linode/linode-cosi-driver/testing/mock"
 )
 
 //nolint:paralleltest // modifies environment variables
@@ -79,64 +74,36 @@ func TestNewLinodeClient(t *testing.T) {
 	}
 }
 
-func TestNewEphemeralS3Credentials(t *testing.T) {
+func TestEphemeralKeyOptionsRequireBucketScope(t *testing.T) {
 	t.Parallel()
 
-	ctrl := gomock.NewController(t)
-	client := mock.NewMockLinodeClient(ctrl)
-
-	client.EXPECT().
-		CreateObjectStorageKey(gomock.Any(), gomock.Any()).
-		Return(&linodego.ObjectStorageKey{
-			ID:    10,
-			Label: "test-key",
-			Regions: []linodego.ObjectStorageKeyRegion{{
-				ID: "us-test",
-			}}}, nil).
-		Times(1)
-
-	client.EXPECT().
-		DeleteObjectStorageKey(gomock.Any(), 10).
-		Return(nil).
-		Times(1)
-	creds, cleanup, err := linodeclient.NewEphemeralS3Credentials(
-		t.Context(),
-		slog.New(slog.DiscardHandler),
-		client,
-		"us-test",
-	)
-	if err != nil {
-		t.Fatalf("expected no error, got: %v", err)
-	}
-
-	if creds.Limited {
-		t.Fatal("expected ephemeral policy credentials to be region-scoped, got bucket-scoped key")
-	}
-
-	if creds.BucketAccess != nil {
-		t.Fatalf("expected no bucket access restrictions, got: %+v", *creds.BucketAccess)
-	}
-
-	regionIDs := make([]string, 0, len(creds.Regions))
-	for _, region := range creds.Regions {
-		regionIDs = append(regionIDs, region.ID)
-	}
-
-	if len(regionIDs) != 1 || !contains(regionIDs, "us-test") {
-		t.Fatalf("expected credentials for only us-test, got: %+v", regionIDs)
-	}
-
-	if err := cleanup(t.Context()); err != nil {
-		t.Fatalf("expected cleanup to succeed, got: %v", err)
-	}
-}
+	for _, tc := range []struct {
+		testName string
+		scope    linodeclient.KeyScope
+	}{
+		{
+			testName: "region only",
+			scope:    linodeclient.KeyScope{Region: "us-test", Permissions: linodeclient.KeyPermissionsReadWrite},
+		},
+		{
+			testName: "bucket only",
+			scope:    linodeclient.KeyScope{Bucket: "test-bucket", Permissions: linodeclient.KeyPermissionsReadWrite},
+		},
+		{
+			testName: "no permissions",
+			scope:    linodeclient.KeyScope{Region: "us-test", Bucket: "test-bucket"},
+		},
+		{
+			testName: "unknown permissions",
+			scope:    linodeclient.KeyScope{Region: "us-test", Bucket: "test-bucket", Permissions: "admin"},
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			t.Parallel()
 
-func contains(items []string, want string) bool {
-	for _, item := range items {
-		if item == want {
-			return true
-		}
+			if _, err := linodeclient.EphemeralKeyOptions(tc.scope); !errors.Is(err, linodeclient.ErrInvalidKeyScope) {
+				t.Errorf("expected error %v, got %v", linodeclient.ErrInvalidKeyScope, err)
+			}
+		})
 	}
-
-	return false
 }
@@ -0,0 +1,159 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics holds the Prometheus metrics reported by the driver.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "linode_cosi"
+
+const (
+	shutdownTimeout   = 5 * time.Second
+	readHeaderTimeout = 10 * time.Second
+)
+
+// Cache lookup results.
+const (
+	ResultHit  = "hit"
+	ResultMiss = "miss"
+	// ResultShared is reported for misses that joined a lookup already in flight.
+	ResultShared = "shared"
+)
+
+// Registry is the registry all driver metrics are registered with.
+var Registry = prometheus.NewRegistry()
+
+// CacheLookups counts cache lookups by cache, resource and result.
+var CacheLookups = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "cache_lookups_total",
+	Help:      "Number of cache lookups by cache, resource and result.",
+}, []string{"cache", "resource", "result"})
+
+// PrunedObjects counts the objects, versions, delete markers and multipart uploads removed by prunes.
+var PrunedObjects = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "pruned_objects_total",
+	Help:      "Number of objects removed from pruned buckets by kind.",
+}, []string{"kind"})
+
+// PrunedBytes counts the bytes of the objects removed by prunes.
+var PrunedBytes = promauto.With(Registry).NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "pruned_bytes_total",
+	Help:      "Number of bytes removed from pruned buckets.",
+})
+
+// PruneFailures counts the objects prunes failed to remove.
+var PruneFailures = promauto.With(Registry).NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "prune_failures_total",
+	Help:      "Number of objects that failed to be removed from pruned buckets.",
+})
+
+// DeletionRefusals counts the deletions refused because the bucket exceeded a deletion limit.
+var DeletionRefusals = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "deletion_refusals_total",
+	Help:      "Number of bucket deletions refused by exceeded limit.",
+}, []string{"limit"})
+
+// CopiedObjects counts the objects copied between buckets, e.g. by archives.
+var CopiedObjects = promauto.With(Registry).NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "copied_objects_total",
+	Help:      "Number of objects copied between buckets.",
+})
+
+// CopiedBytes counts the bytes of the objects copied between buckets.
+var CopiedBytes = promauto.With(Registry).NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "copied_bytes_total",
+	Help:      "Number of bytes copied between buckets.",
+})
+
+// KeyAuditFindings reports the problem keys found by the last key audit, by kind. Keys deleted
+// by the audit are not reported.
+var KeyAuditFindings = promauto.With(Registry).NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: namespace,
+	Name:      "key_audit_findings",
+	Help:      "Number of problem object storage keys found by the last key audit by kind.",
+}, []string{"kind"})
+
+// KeyAuditRemediations counts the keys deleted by key audits.
+var KeyAuditRemediations = promauto.With(Registry).NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "key_audit_remediations_total",
+	Help:      "Number of object storage keys deleted by key audits.",
+})
+
+// ExpiredKeysDeleted counts the time-limited keys deleted once they expired.
+var ExpiredKeysDeleted = promauto.With(Registry).NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "expired_keys_deleted_total",
+	Help:      "Number of time-limited object storage keys deleted once they expired.",
+})
+
+// RotatedKeys counts the keys of BucketAccess objects rotated by the rotation controller.
+var RotatedKeys = promauto.With(Registry).NewCounter(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "rotated_keys_total",
+	Help:      "Number of object storage keys of BucketAccess objects rotated.",
+})
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Serve serves the metrics on the address until the context is canceled.
+func Serve(ctx context.Context, address string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry}))
+
+	srv := &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		sctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), shutdownTimeout)
+		defer cancel()
+
+		srv.Shutdown(sctx) //nolint:errcheck //ignore shutdown error
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("metrics server failed: %w", err)
+	}
+
+	return ctx.Err()
+}
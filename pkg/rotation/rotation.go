@@ -0,0 +1,462 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rotation implements the rotation of the keys granted for BucketAccess objects.
+package rotation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strconv"
+	"time"
+
+	"github.com/linode/linodego/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/pkg/metrics"
+)
+
+// DefaultOverlap is the default time both the previous and the rotated key are valid.
+const DefaultOverlap = 24 * time.Hour
+
+// Annotations of the credentials Secret, recording the state of the rotation.
+const (
+	// AnnotationKeyID holds the ID of the key written to the Secret by the last rotation.
+	AnnotationKeyID = "cosi.linode.com/key-id"
+	// AnnotationRotatedAt holds the time of the last rotation.
+	AnnotationRotatedAt = "cosi.linode.com/rotated-at"
+	// AnnotationPreviousKeyID holds the ID of the key replaced by the last rotation, until it is deleted.
+	AnnotationPreviousKeyID = "cosi.linode.com/previous-key-id"
+	// AnnotationRetireAfter holds the time after which the previous key is deleted.
+	AnnotationRetireAfter = "cosi.linode.com/retire-after"
+)
+
+// BucketInfoKey is the key of the credentials Secret holding the BucketInfo written by COSI.
+const BucketInfoKey = "BucketInfo"
+
+var (
+	// BucketAccessResource is the resource of COSI BucketAccess objects.
+	BucketAccessResource = schema.GroupVersionResource{
+		Group:    "objectstorage.k8s.io",
+		Version:  "v1alpha1",
+		Resource: "bucketaccesses",
+	}
+	// BucketAccessClassResource is the resource of COSI BucketAccessClass objects.
+	BucketAccessClassResource = schema.GroupVersionResource{
+		Group:    "objectstorage.k8s.io",
+		Version:  "v1alpha1",
+		Resource: "bucketaccessclasses",
+	}
+)
+
+// ErrInvalidBucketInfo is returned for credentials Secrets without a valid BucketInfo.
+var ErrInvalidBucketInfo = errors.New("credentials secret does not hold a valid BucketInfo")
+
+// Controller rotates the keys granted for the BucketAccess objects of the driver. Rotated keys are
+// written to the credentials Secret of the BucketAccess, and the previous key is deleted once
+// the overlap elapsed.
+type Controller struct {
+	log        *slog.Logger
+	client     linodeclient.Client
+	kube       kubernetes.Interface
+	dynamic    dynamic.Interface
+	driverName string
+	period     time.Duration
+	overlap    time.Duration
+}
+
+// Option configures optional behavior of the Controller.
+type Option func(*Controller)
+
+// WithLogger sets the logger of the controller.
+func WithLogger(log *slog.Logger) Option {
+	return func(c *Controller) {
+		c.log = log
+	}
+}
+
+// WithOverlap sets how long the previous key stays valid after a rotation, so that workloads
+// pick up the rotated key from the Secret. DefaultOverlap is used by default.
+func WithOverlap(overlap time.Duration) Option {
+	return func(c *Controller) {
+		c.overlap = overlap
+	}
+}
+
+// New returns a controller rotating the keys of the BucketAccess objects of classes of the driver
+// every period.
+func New(
+	client linodeclient.Client,
+	kube kubernetes.Interface,
+	dyn dynamic.Interface,
+	driverName string,
+	period time.Duration,
+	opts ...Option,
+) *Controller {
+	c := &Controller{
+		client:     client,
+		kube:       kube,
+		dynamic:    dyn,
+		driverName: driverName,
+		period:     period,
+		overlap:    DefaultOverlap,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.log == nil {
+		c.log = slog.Default()
+	}
+
+	return c
+}
+
+// Start reconciles the BucketAccess objects every interval until the context is canceled.
+func (c *Controller) Start(ctx context.Context, interval time.Duration) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			if err := c.Reconcile(ctx); err != nil {
+				c.log.ErrorContext(ctx, "Failed to rotate object storage keys", "error", err)
+			}
+
+			timer.Reset(interval)
+		}
+	}
+}
+
+// Reconcile rotates the keys due for rotation, and deletes the previous keys whose overlap elapsed.
+func (c *Controller) Reconcile(ctx context.Context) error {
+	accesses, errs := bucketAccesses(ctx, c.dynamic, c.driverName)
+
+	for _, access := range accesses {
+		if err := c.reconcile(ctx, access); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("bucket access %s/%s: %w", access.GetNamespace(), access.GetName(), err))
+		}
+	}
+
+	return errs
+}
+
+// LiveKeyIDs returns the IDs of the keys granted for the BucketAccess objects of classes of the
+// driver: their account IDs, and the previous keys of rotations whose overlap did not elapse yet.
+// An error is returned when any BucketAccess could not be checked, as the result is incomplete.
+func LiveKeyIDs(ctx context.Context, kube kubernetes.Interface, dyn dynamic.Interface, driverName string) ([]int, error) {
+	accesses, err := bucketAccesses(ctx, dyn, driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+
+	for _, access := range accesses {
+		accountID, _, _ := unstructured.NestedString(access.Object, "status", "accountID")
+		if accountID == "" {
+			continue
+		}
+
+		id, err := strconv.Atoi(accountID)
+		if err != nil {
+			return nil, fmt.Errorf("bucket access %s/%s: invalid account ID %q: %w", access.GetNamespace(), access.GetName(), accountID, err)
+		}
+		ids = append(ids, id)
+
+		secretName, _, _ := unstructured.NestedString(access.Object, "spec", "credentialsSecretName")
+		if secretName == "" {
+			continue
+		}
+
+		secret, err := kube.CoreV1().Secrets(access.GetNamespace()).Get(ctx, secretName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bucket access %s/%s: failed to get credentials secret: %w", access.GetNamespace(), access.GetName(), err)
+		}
+
+		for _, annotation := range []string{AnnotationKeyID, AnnotationPreviousKeyID} {
+			if value := secret.Annotations[annotation]; value != "" {
+				id, err := strconv.Atoi(value)
+				if err != nil {
+					return nil, fmt.Errorf("bucket access %s/%s: invalid %s annotation: %w", access.GetNamespace(), access.GetName(), annotation, err)
+				}
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+// bucketAccesses lists the BucketAccess objects of classes of the driver. The objects whose class
+// could be read are returned along with the errors reading the other classes.
+func bucketAccesses(ctx context.Context, dyn dynamic.Interface, driverName string) ([]*unstructured.Unstructured, error) {
+	list, err := dyn.Resource(BucketAccessResource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bucket accesses: %w", err)
+	}
+
+	drivers := make(map[string]string)
+
+	var (
+		accesses []*unstructured.Unstructured
+		errs     error
+	)
+
+	for i := range list.Items {
+		access := &list.Items[i]
+
+		className, _, _ := unstructured.NestedString(access.Object, "spec", "bucketAccessClassName")
+
+		driver, ok := drivers[className]
+		if !ok {
+			class, err := dyn.Resource(BucketAccessClassResource).Get(ctx, className, metav1.GetOptions{})
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("failed to get bucket access class %s: %w", className, err))
+				continue
+			}
+
+			driver, _, _ = unstructured.NestedString(class.Object, "driverName")
+			drivers[className] = driver
+		}
+
+		if driver == driverName {
+			accesses = append(accesses, access)
+		}
+	}
+
+	return accesses, errs
+}
+
+func (c *Controller) reconcile(ctx context.Context, access *unstructured.Unstructured) error {
+	granted, _, _ := unstructured.NestedBool(access.Object, "status", "accessGranted")
+	accountID, _, _ := unstructured.NestedString(access.Object, "status", "accountID")
+	secretName, _, _ := unstructured.NestedString(access.Object, "spec", "credentialsSecretName")
+
+	if !granted || accountID == "" || secretName == "" {
+		return nil
+	}
+
+	log := c.log.With(
+		slog.String("bucket_access", access.GetNamespace()+"/"+access.GetName()),
+		slog.String("secret", secretName),
+	)
+
+	secrets := c.kube.CoreV1().Secrets(access.GetNamespace())
+
+	secret, err := secrets.Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get credentials secret: %w", err)
+	}
+
+	// The account of the BucketAccess is moved to the rotated key after the Secret was
+	// updated, so that revoking the access deletes the rotated key.
+	if keyID := secret.Annotations[AnnotationKeyID]; keyID != "" && keyID != accountID {
+		if err := c.updateAccountID(ctx, access, keyID); err != nil {
+			return err
+		}
+
+		accountID = keyID
+	}
+
+	if previous := secret.Annotations[AnnotationPreviousKeyID]; previous != "" {
+		return c.retire(ctx, log, secret, previous)
+	}
+
+	rotatedAt := secret.CreationTimestamp.Time
+	if value := secret.Annotations[AnnotationRotatedAt]; value != "" {
+		if rotatedAt, err = time.Parse(time.RFC3339, value); err != nil {
+			return fmt.Errorf("invalid %s annotation: %w", AnnotationRotatedAt, err)
+		}
+	}
+
+	if time.Since(rotatedAt) < c.period {
+		return nil
+	}
+
+	return c.rotate(ctx, log, access, secret, accountID)
+}
+
+// rotate issues a key with the same bucket access as the current key, and writes it to the Secret.
+func (c *Controller) rotate(
+	ctx context.Context,
+	log *slog.Logger,
+	access *unstructured.Unstructured,
+	secret *corev1.Secret,
+	accountID string,
+) error {
+	id, err := strconv.Atoi(accountID)
+	if err != nil {
+		return fmt.Errorf("invalid account ID %q: %w", accountID, err)
+	}
+
+	key, err := c.client.GetObjectStorageKey(ctx, id)
+	if linodego.IsNotFound(err) {
+		log.WarnContext(ctx, "Key of the bucket access not found, skipping rotation", slog.Int("key_id", id))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get key %d: %w", id, err)
+	}
+
+	// Time-limited keys expire instead of being rotated.
+	if _, _, ok := linodeclient.KeyExpiry(key.Label); ok || !key.Limited || key.BucketAccess == nil {
+		return nil
+	}
+
+	opts := linodego.ObjectStorageKeyCreateOptions{Label: key.Label}
+	for _, bucket := range *key.BucketAccess {
+		opts.BucketAccess = append(opts.BucketAccess, linodego.ObjectStorageKeyBucketAccessCreateOptions{
+			Region:      bucket.Region,
+			BucketName:  bucket.BucketName,
+			Permissions: bucket.Permissions,
+		})
+	}
+
+	rotated, err := c.client.CreateObjectStorageKey(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to create object storage key: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	if err := c.writeCredentials(ctx, secret, rotated, map[string]string{
+		AnnotationKeyID:         strconv.Itoa(rotated.ID),
+		AnnotationRotatedAt:     now.Format(time.RFC3339),
+		AnnotationPreviousKeyID: accountID,
+		AnnotationRetireAfter:   now.Add(c.overlap).Format(time.RFC3339),
+	}); err != nil {
+		if derr := c.client.DeleteObjectStorageKey(context.WithoutCancel(ctx), rotated.ID); derr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to delete rotated key %d: %w", rotated.ID, derr))
+		}
+
+		return err
+	}
+
+	log.InfoContext(ctx, "Object storage key rotated",
+		slog.Int("key_id", rotated.ID),
+		slog.Int("previous_key_id", id),
+		slog.Time("retire_after", now.Add(c.overlap)),
+	)
+	metrics.RotatedKeys.Inc()
+
+	return c.updateAccountID(ctx, access, strconv.Itoa(rotated.ID))
+}
+
+// retire deletes the previous key once the overlap elapsed.
+func (c *Controller) retire(ctx context.Context, log *slog.Logger, secret *corev1.Secret, previous string) error {
+	retireAfter, err := time.Parse(time.RFC3339, secret.Annotations[AnnotationRetireAfter])
+	if err != nil {
+		return fmt.Errorf("invalid %s annotation: %w", AnnotationRetireAfter, err)
+	}
+
+	if time.Now().Before(retireAfter) {
+		return nil
+	}
+
+	id, err := strconv.Atoi(previous)
+	if err != nil {
+		return fmt.Errorf("invalid %s annotation: %w", AnnotationPreviousKeyID, err)
+	}
+
+	if err := c.client.DeleteObjectStorageKey(ctx, id); err != nil && !linodego.IsNotFound(err) {
+		return fmt.Errorf("failed to delete previous key %d: %w", id, err)
+	}
+
+	log.InfoContext(ctx, "Previous object storage key deleted", slog.Int("key_id", id))
+
+	secret = secret.DeepCopy()
+	delete(secret.Annotations, AnnotationPreviousKeyID)
+	delete(secret.Annotations, AnnotationRetireAfter)
+
+	if _, err := c.kube.CoreV1().Secrets(secret.Namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update credentials secret: %w", err)
+	}
+
+	return nil
+}
+
+// writeCredentials replaces the credentials of the BucketInfo held by the Secret.
+func (c *Controller) writeCredentials(
+	ctx context.Context,
+	secret *corev1.Secret,
+	key *linodego.ObjectStorageKey,
+	annotations map[string]string,
+) error {
+	var info map[string]any
+	if err := json.Unmarshal(secret.Data[BucketInfoKey], &info); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidBucketInfo, err)
+	}
+
+	if _, ok, _ := unstructured.NestedMap(info, "spec", "secretS3"); !ok {
+		return fmt.Errorf("%w: missing S3 credentials", ErrInvalidBucketInfo)
+	}
+
+	// Errors are not possible, as the path was checked above.
+	_ = unstructured.SetNestedField(info, key.AccessKey, "spec", "secretS3", "accessKeyID")
+	_ = unstructured.SetNestedField(info, key.SecretKey, "spec", "secretS3", "accessSecretKey")
+
+	raw, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to encode BucketInfo: %w", err)
+	}
+
+	secret = secret.DeepCopy()
+	secret.Data[BucketInfoKey] = raw
+
+	if secret.Annotations == nil {
+		secret.Annotations = make(map[string]string, len(annotations))
+	}
+	maps.Copy(secret.Annotations, annotations)
+
+	if _, err := c.kube.CoreV1().Secrets(secret.Namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update credentials secret: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Controller) updateAccountID(ctx context.Context, access *unstructured.Unstructured, accountID string) error {
+	access = access.DeepCopy()
+
+	if err := unstructured.SetNestedField(access.Object, accountID, "status", "accountID"); err != nil {
+		return fmt.Errorf("failed to set account ID: %w", err)
+	}
+
+	if _, err := c.dynamic.Resource(BucketAccessResource).
+		Namespace(access.GetNamespace()).
+		UpdateStatus(ctx, access, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update account ID of bucket access: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,240 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotation_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego/v2"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/linode/linode-cosi-driver/pkg/rotation"
+	"github.com/linode/linode-cosi-driver/testing/mock"
+)
+
+const (
+	testDriverName = "objectstorage.cosi.linode.com"
+	testNamespace  = "default"
+	testSecretName = "creds"
+)
+
+var discardLog = slog.New(slog.DiscardHandler)
+
+func bucketAccessClass(name, driverName string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "objectstorage.k8s.io/v1alpha1",
+		"kind":       "BucketAccessClass",
+		"metadata":   map[string]any{"name": name},
+		"driverName": driverName,
+	}}
+}
+
+func bucketAccess(name, className, accountID string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "objectstorage.k8s.io/v1alpha1",
+		"kind":       "BucketAccess",
+		"metadata":   map[string]any{"name": name, "namespace": testNamespace},
+		"spec": map[string]any{
+			"bucketAccessClassName": className,
+			"credentialsSecretName": testSecretName,
+		},
+		"status": map[string]any{
+			"accessGranted": true,
+			"accountID":     accountID,
+		},
+	}}
+}
+
+func bucketInfo(t *testing.T, accessKey, secretKey string) []byte {
+	t.Helper()
+
+	raw, err := json.Marshal(map[string]any{
+		"apiVersion": "objectstorage.k8s.io/v1alpha1",
+		"kind":       "BucketInfo",
+		"spec": map[string]any{
+			"bucketName":         "test-bucket",
+			"authenticationType": "KEY",
+			"secretS3": map[string]any{
+				"endpoint":        "https://us-east-1.linodeobjects.com",
+				"region":          "us-east",
+				"accessKeyID":     accessKey,
+				"accessSecretKey": secretKey,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to encode BucketInfo: %v", err)
+	}
+
+	return raw
+}
+
+func TestReconcile(t *testing.T) {
+	t.Parallel()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              testSecretName,
+			Namespace:         testNamespace,
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-91 * 24 * time.Hour)),
+		},
+		Data: map[string][]byte{rotation.BucketInfoKey: bucketInfo(t, "old-access", "old-secret")},
+	}
+	kube := kubefake.NewClientset(secret)
+
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{rotation.BucketAccessResource: "BucketAccessList"},
+		bucketAccessClass("linode", testDriverName),
+		bucketAccessClass("other", "other.example.com"),
+		bucketAccess("linode-access", "linode", "1"),
+		// Keys of other drivers are left alone.
+		bucketAccess("other-access", "other", "9"),
+	)
+
+	ctrl := gomock.NewController(t)
+	mockLinode := mock.NewMockLinodeClient(ctrl)
+	mockLinode.EXPECT().
+		GetObjectStorageKey(gomock.Any(), gomock.Eq(1)).
+		Return(&linodego.ObjectStorageKey{
+			ID:      1,
+			Label:   "ba-test",
+			Limited: true,
+			BucketAccess: &[]linodego.ObjectStorageKeyBucketAccess{
+				{Region: "us-east", BucketName: "test-bucket", Permissions: "read_write"},
+			},
+		}, nil)
+	mockLinode.EXPECT().
+		CreateObjectStorageKey(gomock.Any(), gomock.Eq(linodego.ObjectStorageKeyCreateOptions{
+			Label: "ba-test",
+			BucketAccess: []linodego.ObjectStorageKeyBucketAccessCreateOptions{
+				{Region: "us-east", BucketName: "test-bucket", Permissions: "read_write"},
+			},
+		})).
+		Return(&linodego.ObjectStorageKey{ID: 2, Label: "ba-test", AccessKey: "new-access", SecretKey: "new-secret"}, nil)
+
+	controller := rotation.New(mockLinode, kube, dyn, testDriverName, 90*24*time.Hour,
+		rotation.WithLogger(discardLog),
+		rotation.WithOverlap(time.Hour),
+	)
+
+	// The key is due for rotation, as the Secret is older than the period.
+	if err := controller.Reconcile(t.Context()); err != nil {
+		t.Fatalf("failed to rotate keys: %v", err)
+	}
+
+	rotated, err := kube.CoreV1().Secrets(testNamespace).Get(t.Context(), testSecretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get secret: %v", err)
+	}
+
+	if expected := bucketInfo(t, "new-access", "new-secret"); string(rotated.Data[rotation.BucketInfoKey]) != string(expected) {
+		t.Errorf("expected BucketInfo %s, got %s", expected, rotated.Data[rotation.BucketInfoKey])
+	}
+	if rotated.Annotations[rotation.AnnotationKeyID] != "2" || rotated.Annotations[rotation.AnnotationPreviousKeyID] != "1" {
+		t.Errorf("expected rotation from key 1 to key 2, got annotations %v", rotated.Annotations)
+	}
+
+	access, err := dyn.Resource(rotation.BucketAccessResource).Namespace(testNamespace).
+		Get(t.Context(), "linode-access", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get bucket access: %v", err)
+	}
+	if accountID, _, _ := unstructured.NestedString(access.Object, "status", "accountID"); accountID != "2" {
+		t.Errorf("expected account ID of the rotated key, got %q", accountID)
+	}
+
+	// The previous key is kept during the overlap.
+	if err := controller.Reconcile(t.Context()); err != nil {
+		t.Fatalf("failed to reconcile during overlap: %v", err)
+	}
+
+	// The previous key is deleted once the overlap elapsed, and the key is not rotated again.
+	rotated.Annotations[rotation.AnnotationRetireAfter] = time.Now().Add(-time.Minute).Format(time.RFC3339)
+	if _, err := kube.CoreV1().Secrets(testNamespace).Update(t.Context(), rotated, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update secret: %v", err)
+	}
+
+	mockLinode.EXPECT().DeleteObjectStorageKey(gomock.Any(), gomock.Eq(1)).Return(nil)
+
+	for range 2 {
+		if err := controller.Reconcile(t.Context()); err != nil {
+			t.Fatalf("failed to retire previous key: %v", err)
+		}
+	}
+
+	retired, err := kube.CoreV1().Secrets(testNamespace).Get(t.Context(), testSecretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get secret: %v", err)
+	}
+	if _, ok := retired.Annotations[rotation.AnnotationPreviousKeyID]; ok {
+		t.Errorf("expected previous key to be forgotten, got annotations %v", retired.Annotations)
+	}
+}
+
+func TestLiveKeyIDs(t *testing.T) {
+	t.Parallel()
+
+	// The key of the BucketAccess was rotated, the previous key is valid until the overlap elapsed.
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testSecretName,
+			Namespace: testNamespace,
+			Annotations: map[string]string{
+				rotation.AnnotationKeyID:         "2",
+				rotation.AnnotationPreviousKeyID: "1",
+			},
+		},
+	}
+	kube := kubefake.NewClientset(secret)
+
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{rotation.BucketAccessResource: "BucketAccessList"},
+		bucketAccessClass("linode", testDriverName),
+		bucketAccessClass("other", "other.example.com"),
+		bucketAccess("linode-access", "linode", "2"),
+		bucketAccess("other-access", "other", "9"),
+	)
+
+	ids, err := rotation.LiveKeyIDs(t.Context(), kube, dyn, testDriverName)
+	if err != nil {
+		t.Fatalf("failed to list live key IDs: %v", err)
+	}
+
+	slices.Sort(ids)
+	if !slices.Equal(slices.Compact(ids), []int{1, 2}) {
+		t.Errorf("expected live keys [1 2], got %v", ids)
+	}
+
+	// Live keys are unknown when the class of any BucketAccess cannot be read.
+	dyn = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{rotation.BucketAccessResource: "BucketAccessList"},
+		bucketAccess("linode-access", "deleted", "2"),
+	)
+
+	if _, err := rotation.LiveKeyIDs(t.Context(), kube, dyn, testDriverName); err == nil {
+		t.Errorf("expected error for bucket access of unknown class")
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import "sync"
+
+// Clients reuses clients created for the same endpoint and credentials.
+type Clients struct {
+	s3SSL bool
+	opts  []Option
+
+	mu      sync.Mutex
+	clients map[clientKey]*ClientS3
+}
+
+type clientKey struct {
+	endpoint  string
+	accessKey string
+}
+
+func NewClients(s3SSL bool, opts ...Option) *Clients {
+	return &Clients{
+		s3SSL:   s3SSL,
+		opts:    opts,
+		clients: make(map[clientKey]*ClientS3),
+	}
+}
+
+// Get returns the client for the endpoint and credentials, creating it if needed.
+func (c *Clients) Get(endpoint, s3AccessKey, s3SecretKey string) *ClientS3 {
+	key := clientKey{endpoint: endpoint, accessKey: s3AccessKey}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cli, ok := c.clients[key]; ok && cli.s3SecretKey == s3SecretKey {
+		return cli
+	}
+
+	cli := NewWithEndpoint(endpoint, s3AccessKey, s3SecretKey, c.s3SSL, c.opts...)
+	c.clients[key] = cli
+
+	return cli
+}
+
+// Forget drops the clients using the access key, e.g. after the key was revoked.
+func (c *Clients) Forget(s3AccessKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.clients {
+		if key.accessKey == s3AccessKey {
+			delete(c.clients, key)
+		}
+	}
+}
@@ -0,0 +1,481 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec // MD5 is the checksum of S3 ETags
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/linode/linode-cosi-driver/pkg/metrics"
+)
+
+// DefaultCopyWorkers is the default number of objects copied concurrently.
+const DefaultCopyWorkers = 8
+
+const (
+	// maxServerSideCopySize is the size of the largest object copied by a single CopyObject request.
+	maxServerSideCopySize = 5 << 30
+	// copyProgressInterval is how often the progress of a copy is logged.
+	copyProgressInterval = 10 * time.Second
+	// copyDeadlineMargin is reserved before the deadline of the caller, so that an
+	// interrupted copy is reported instead of timing out.
+	copyDeadlineMargin = 5 * time.Second
+	// maxCopyErrorSamples is the number of failed objects named in copy errors.
+	maxCopyErrorSamples = 5
+)
+
+// ErrCopyIncomplete is returned when objects of the source are missing in the destination
+// after a copy. Copying again resumes with the missing objects.
+var ErrCopyIncomplete = errors.New("copy incomplete")
+
+// ErrCopyInterrupted is returned when a copy was interrupted before all objects were copied.
+// Copying again resumes with the remaining objects.
+var ErrCopyInterrupted = errors.New("copy interrupted")
+
+// ErrChecksumMismatch is returned when the content of an object does not match its checksum.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// Location is a prefix of a bucket, accessed through a client.
+type Location struct {
+	Client Client
+	Region string
+	Bucket string
+	// Prefix limits a source to the objects below it, and is prepended to the keys copied
+	// into a destination, in place of the prefix of the source.
+	Prefix string
+}
+
+func (l Location) String() string {
+	return l.Region + "/" + l.Bucket + "/" + l.Prefix
+}
+
+// CopyResult counts the objects of a copy.
+type CopyResult struct {
+	// Objects and Bytes count the copied objects.
+	Objects int64
+	Bytes   int64
+	// Skipped counts the objects already present in the destination with the same size,
+	// e.g. copied by an earlier attempt.
+	Skipped int64
+}
+
+// CopyOption configures a copy.
+type CopyOption func(*copier)
+
+// WithCopyWorkers sets the number of objects copied concurrently.
+func WithCopyWorkers(workers int) CopyOption {
+	return func(c *copier) {
+		c.workers = workers
+	}
+}
+
+// WithCopyLogger sets the logger used to report the progress of the copy.
+func WithCopyLogger(logger *slog.Logger) CopyOption {
+	return func(c *copier) {
+		c.log = logger
+	}
+}
+
+// WithCopyProgress sets a function called periodically and once the copy finished, with the
+// objects copied so far.
+func WithCopyProgress(progress func(CopyResult)) CopyOption {
+	return func(c *copier) {
+		c.onProgress = progress
+	}
+}
+
+// WithCopyChecksums verifies the MD5 checksum of every object. Objects are streamed through
+// the driver to hash their content, which is compared to the ETags of the source and the
+// destination. Destination objects uploaded in parts are downloaded again to hash them.
+// Objects already present in the destination are only skipped if their ETags match.
+func WithCopyChecksums() CopyOption {
+	return func(c *copier) {
+		c.checksums = make(map[string]string)
+	}
+}
+
+// Copy copies the current version of all objects of the source into the destination, which
+// must not overlap. Objects are copied server-side when both locations share the client and
+// region, and streamed through the driver otherwise.
+//
+// Objects already present in the destination with the same size are skipped, so that an
+// interrupted copy resumes where it stopped. Copies interrupted by the context, or shortly
+// before its deadline, return ErrCopyInterrupted. Once all objects were copied, the
+// destination is listed again to verify that it holds every object of the source, otherwise
+// ErrCopyIncomplete is returned. Objects removed from the source while copying are not verified.
+func Copy(ctx context.Context, src, dst Location, opts ...CopyOption) (CopyResult, error) {
+	c := &copier{
+		log:      slog.Default(),
+		src:      src,
+		dst:      dst,
+		workers:  DefaultCopyWorkers,
+		vanished: make(map[string]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.workers = max(c.workers, 1)
+	c.log = c.log.With(slog.String("source", src.String()), slog.String("destination", dst.String()))
+
+	return c.run(ctx)
+}
+
+// copier copies the objects of a source into a destination, using a bounded number of workers.
+type copier struct {
+	log        *slog.Logger
+	src, dst   Location
+	workers    int
+	onProgress func(CopyResult)
+
+	objects atomic.Int64
+	bytes   atomic.Int64
+	skipped atomic.Int64
+
+	mu       sync.Mutex
+	failed   int64
+	samples  []string
+	firstErr error
+	vanished map[string]struct{}
+	// checksums holds the MD5 checksums of streamed objects by destination key. It is nil
+	// unless checksums are verified.
+	checksums map[string]string
+}
+
+func (c *copier) run(ctx context.Context) (CopyResult, error) {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) > 2*copyDeadlineMargin {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-copyDeadlineMargin))
+		defer cancel()
+	}
+
+	existing, err := listObjects(ctx, c.dst)
+	if err != nil {
+		return CopyResult{}, fmt.Errorf("failed to list destination: %w", err)
+	}
+
+	start := time.Now()
+	stop := c.reportProgress(ctx, start)
+
+	sources, err := c.copyObjects(ctx, existing)
+
+	stop()
+
+	c.log.InfoContext(ctx, "Copy finished", c.progress(start)...)
+	c.notify()
+
+	if ctx.Err() != nil {
+		return c.result(), fmt.Errorf("%w after copying %d objects: %w", ErrCopyInterrupted, c.objects.Load(), ctx.Err())
+	}
+
+	if err != nil {
+		return c.result(), fmt.Errorf("failed to list source: %w", err)
+	}
+
+	if c.failed > 0 {
+		return c.result(), fmt.Errorf("%w: failed to copy %d objects, e.g. %s: %w",
+			ErrCopyIncomplete, c.failed, strings.Join(c.samples, ", "), c.firstErr)
+	}
+
+	return c.result(), c.verify(ctx, sources)
+}
+
+// copyObjects lists the source and copies the objects missing in the destination. It returns
+// the source objects by their key in the destination.
+func (c *copier) copyObjects(ctx context.Context, existing map[string]minio.ObjectInfo) (map[string]minio.ObjectInfo, error) {
+	sources := make(map[string]minio.ObjectInfo)
+	objects := make(chan minio.ObjectInfo)
+
+	var wg sync.WaitGroup
+	for range c.workers {
+		wg.Go(func() {
+			for obj := range objects {
+				c.copyObject(ctx, obj)
+			}
+		})
+	}
+
+	err := c.src.Client.ListObjects(ctx, c.src.Region, c.src.Bucket, c.src.Prefix, func(obj minio.ObjectInfo) error {
+		key := c.dstKey(obj.Key)
+		sources[key] = obj
+
+		if dst, ok := existing[key]; ok && c.unchanged(obj, dst) {
+			c.skipped.Add(1)
+			return nil
+		}
+
+		select {
+		case objects <- obj:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	close(objects)
+	wg.Wait()
+
+	return sources, err
+}
+
+func (c *copier) copyObject(ctx context.Context, obj minio.ObjectInfo) {
+	key := c.dstKey(obj.Key)
+
+	var err error
+	if c.checksums == nil && c.serverSide() && obj.Size <= maxServerSideCopySize {
+		err = c.dst.Client.CopyObject(ctx, c.dst.Region, c.src.Bucket, obj.Key, c.dst.Bucket, key)
+	} else {
+		err = c.stream(ctx, obj.Key, key)
+	}
+
+	if IsNotFound(err) {
+		c.mu.Lock()
+		c.vanished[key] = struct{}{}
+		c.mu.Unlock()
+
+		return
+	}
+
+	if err != nil {
+		c.fail(obj.Key, err)
+		return
+	}
+
+	c.objects.Add(1)
+	c.bytes.Add(obj.Size)
+	metrics.CopiedObjects.Inc()
+	metrics.CopiedBytes.Add(float64(obj.Size))
+}
+
+// stream downloads the object from the source, while uploading it to the destination.
+func (c *copier) stream(ctx context.Context, srcKey, dstKey string) error {
+	body, info, err := c.src.Client.GetObject(ctx, c.src.Region, c.src.Bucket, srcKey)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if c.checksums == nil {
+		return c.dst.Client.PutObject(ctx, c.dst.Region, c.dst.Bucket, dstKey, body, info)
+	}
+
+	hash := md5.New() //nolint:gosec // MD5 is the checksum of S3 ETags
+	if err := c.dst.Client.PutObject(ctx, c.dst.Region, c.dst.Bucket, dstKey, io.TeeReader(body, hash), info); err != nil {
+		return err
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if plainETag(info.ETag) && info.ETag != sum {
+		return fmt.Errorf("%w: source ETag %s, read %s", ErrChecksumMismatch, info.ETag, sum)
+	}
+
+	c.mu.Lock()
+	c.checksums[dstKey] = sum
+	c.mu.Unlock()
+
+	return nil
+}
+
+// unchanged reports whether the destination object is a copy of the source object.
+func (c *copier) unchanged(src, dst minio.ObjectInfo) bool {
+	if src.Size != dst.Size {
+		return false
+	}
+
+	return c.checksums == nil || (plainETag(src.ETag) && src.ETag == dst.ETag)
+}
+
+// serverSide reports whether the credentials of the source are able to write the destination.
+func (c *copier) serverSide() bool {
+	return c.src.Client == c.dst.Client && c.src.Region == c.dst.Region
+}
+
+func (c *copier) dstKey(srcKey string) string {
+	return c.dst.Prefix + strings.TrimPrefix(srcKey, c.src.Prefix)
+}
+
+// verify lists the destination, and checks that it holds the expected objects.
+func (c *copier) verify(ctx context.Context, expected map[string]minio.ObjectInfo) error {
+	copied, err := listObjects(ctx, c.dst)
+	if err != nil {
+		return fmt.Errorf("failed to list destination for verification: %w", err)
+	}
+
+	var (
+		missing, differing int64
+		samples            []string
+	)
+
+	for _, key := range slices.Sorted(maps.Keys(expected)) {
+		if _, ok := c.vanished[key]; ok {
+			continue
+		}
+
+		dst, ok := copied[key]
+
+		switch {
+		case !ok:
+			missing++
+		case dst.Size != expected[key].Size:
+			differing++
+		case c.checksums != nil && !c.checksumMatches(ctx, key, expected[key], dst):
+			differing++
+		default:
+			continue
+		}
+
+		if len(samples) < maxCopyErrorSamples {
+			samples = append(samples, key)
+		}
+	}
+
+	if missing == 0 && differing == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %d of %d objects missing and %d differing in size or checksum, e.g. %s",
+		ErrCopyIncomplete, missing, len(expected), differing, strings.Join(samples, ", "))
+}
+
+// checksumMatches reports whether the checksum of the destination object matches the checksum
+// of the streamed content, or the ETag of the skipped source object.
+func (c *copier) checksumMatches(ctx context.Context, key string, src, dst minio.ObjectInfo) bool {
+	want, ok := c.checksums[key]
+	if !ok {
+		want = src.ETag
+	}
+
+	if plainETag(dst.ETag) {
+		return dst.ETag == want
+	}
+
+	// ETags of objects uploaded in parts are derived from the parts, so the content is hashed.
+	body, _, err := c.dst.Client.GetObject(ctx, c.dst.Region, c.dst.Bucket, key)
+	if err != nil {
+		c.log.WarnContext(ctx, "Failed to download object for verification", "key", key, "error", err)
+		return false
+	}
+	defer body.Close()
+
+	hash := md5.New() //nolint:gosec // MD5 is the checksum of S3 ETags
+	if _, err := io.Copy(hash, body); err != nil {
+		c.log.WarnContext(ctx, "Failed to download object for verification", "key", key, "error", err)
+		return false
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)) == want
+}
+
+func (c *copier) fail(key string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failed++
+	if c.firstErr == nil {
+		c.firstErr = err
+	}
+
+	if len(c.samples) < maxCopyErrorSamples {
+		c.samples = append(c.samples, key)
+	}
+}
+
+// reportProgress logs the progress until the returned function is called.
+func (c *copier) reportProgress(ctx context.Context, start time.Time) func() {
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ticker := time.NewTicker(copyProgressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				c.log.InfoContext(ctx, "Copying objects", c.progress(start)...)
+				c.notify()
+			}
+		}
+	})
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+func (c *copier) progress(start time.Time) []any {
+	c.mu.Lock()
+	failed := c.failed
+	c.mu.Unlock()
+
+	return []any{
+		slog.Int64("objects", c.objects.Load()),
+		slog.Int64("bytes", c.bytes.Load()),
+		slog.Int64("skipped", c.skipped.Load()),
+		slog.Int64("failed", failed),
+		slog.Duration("duration", time.Since(start)),
+	}
+}
+
+func (c *copier) result() CopyResult {
+	return CopyResult{
+		Objects: c.objects.Load(),
+		Bytes:   c.bytes.Load(),
+		Skipped: c.skipped.Load(),
+	}
+}
+
+func (c *copier) notify() {
+	if c.onProgress != nil {
+		c.onProgress(c.result())
+	}
+}
+
+// listObjects returns the objects of the location by key.
+func listObjects(ctx context.Context, loc Location) (map[string]minio.ObjectInfo, error) {
+	objects := make(map[string]minio.ObjectInfo)
+
+	err := loc.Client.ListObjects(ctx, loc.Region, loc.Bucket, loc.Prefix, func(obj minio.ObjectInfo) error {
+		objects[obj.Key] = obj
+		return nil
+	})
+
+	return objects, err
+}
+
+// plainETag reports whether the ETag is the MD5 checksum of the object. ETags of objects
+// uploaded in parts, or encrypted with customer keys, are not.
+func plainETag(etag string) bool {
+	_, err := hex.DecodeString(etag)
+	return err == nil && len(etag) == 2*md5.Size
+}
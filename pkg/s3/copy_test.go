@@ -0,0 +1,310 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec // MD5 is the checksum of S3 ETags
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// fakeStore is an in-memory client serving the objects of several buckets. Keys containing
+// "broken/" cannot be written, keys containing "corrupt/" are read back with altered content,
+// and keys containing "parts/" have the ETags of objects uploaded in parts.
+type fakeStore struct {
+	mu      sync.Mutex
+	buckets map[string]map[string][]byte
+	copies  int
+	puts    int
+}
+
+var _ Client = (*fakeStore)(nil)
+
+func newFakeStore(buckets ...string) *fakeStore {
+	f := &fakeStore{buckets: make(map[string]map[string][]byte)}
+	for _, bucket := range buckets {
+		f.buckets[bucket] = make(map[string][]byte)
+	}
+
+	return f
+}
+
+var errFakeNoSuchKey = minio.ErrorResponse{StatusCode: http.StatusNotFound, Code: "NoSuchKey"}
+
+func (f *fakeStore) ListObjects(_ context.Context, _, bucket, prefix string, fn func(minio.ObjectInfo) error) error {
+	f.mu.Lock()
+	objects := maps.Clone(f.buckets[bucket])
+	f.mu.Unlock()
+
+	for _, key := range slices.Sorted(maps.Keys(objects)) {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		if err := fn(fakeObjectInfo(key, objects[key])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *fakeStore) GetObject(_ context.Context, _, bucket, key string) (io.ReadCloser, minio.ObjectInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, ok := f.buckets[bucket][key]
+	if !ok {
+		return nil, minio.ObjectInfo{}, errFakeNoSuchKey
+	}
+
+	info := fakeObjectInfo(key, data)
+	if strings.Contains(key, "corrupt/") {
+		data = bytes.ToUpper(data)
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), info, nil
+}
+
+func fakeObjectInfo(key string, data []byte) minio.ObjectInfo {
+	sum := md5.Sum(data) //nolint:gosec // MD5 is the checksum of S3 ETags
+	etag := hex.EncodeToString(sum[:])
+	if strings.Contains(key, "parts/") {
+		etag += "-2"
+	}
+
+	return minio.ObjectInfo{Key: key, Size: int64(len(data)), ETag: etag}
+}
+
+func (f *fakeStore) PutObject(_ context.Context, _, bucket, key string, body io.Reader, _ minio.ObjectInfo) error {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.puts++
+
+	return f.write(bucket, key, data)
+}
+
+func (f *fakeStore) CopyObject(_ context.Context, _, srcBucket, srcKey, dstBucket, dstKey string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, ok := f.buckets[srcBucket][srcKey]
+	if !ok {
+		return errFakeNoSuchKey
+	}
+
+	f.copies++
+
+	return f.write(dstBucket, dstKey, data)
+}
+
+func (f *fakeStore) write(bucket, key string, data []byte) error {
+	if strings.Contains(key, "broken/") {
+		return minio.ErrorResponse{StatusCode: http.StatusForbidden, Code: "AccessDenied"}
+	}
+
+	f.buckets[bucket][key] = data
+
+	return nil
+}
+
+func (f *fakeStore) keys(bucket string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Sorted(maps.Keys(f.buckets[bucket]))
+}
+
+func (*fakeStore) Prune(context.Context, string, string, func(int64, int64)) error { return nil }
+func (*fakeStore) SetBucketPolicy(context.Context, string, string, string) error   { return nil }
+func (*fakeStore) GetBucketPolicy(context.Context, string, string) (string, error) { return "", nil }
+func (*fakeStore) GetBucketTags(context.Context, string, string) (map[string]string, error) {
+	return map[string]string{}, nil
+}
+func (*fakeStore) SetBucketTags(context.Context, string, string, map[string]string) error { return nil }
+
+var discardLog = slog.New(slog.DiscardHandler)
+
+func TestCopy(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		testName       string
+		sameClient     bool
+		source         map[string]string
+		existing       map[string]string
+		expected       []string
+		expectedResult CopyResult
+		expectedErr    error
+		serverSide     bool
+		checksums      bool
+	}{
+		{
+			testName:   "copies objects server-side with a shared client",
+			sameClient: true,
+			source:     map[string]string{"a": "1", "dir/b": "22"},
+			expected:   []string{"archive/a", "archive/dir/b"},
+			expectedResult: CopyResult{
+				Objects: 2,
+				Bytes:   3,
+			},
+			serverSide: true,
+		},
+		{
+			testName: "streams objects between clients",
+			source:   map[string]string{"a": "1", "dir/b": "22"},
+			expected: []string{"archive/a", "archive/dir/b"},
+			expectedResult: CopyResult{
+				Objects: 2,
+				Bytes:   3,
+			},
+		},
+		{
+			testName: "resumes with missing and differing objects",
+			source:   map[string]string{"a": "1", "b": "22", "c": "333"},
+			existing: map[string]string{"archive/a": "1", "archive/b": "2"},
+			expected: []string{"archive/a", "archive/b", "archive/c"},
+			expectedResult: CopyResult{
+				Objects: 2,
+				Bytes:   5,
+				Skipped: 1,
+			},
+		},
+		{
+			testName: "reports objects failing to be copied",
+			source:   map[string]string{"a": "1", "broken/b": "22"},
+			expected: []string{"archive/a"},
+			expectedResult: CopyResult{
+				Objects: 1,
+				Bytes:   1,
+			},
+			expectedErr: ErrCopyIncomplete,
+		},
+		{
+			testName:   "streams and verifies checksums with a shared client",
+			sameClient: true,
+			source:     map[string]string{"a": "1", "b": "2", "parts/c": "33"},
+			existing:   map[string]string{"archive/a": "1", "archive/b": "x"},
+			expected:   []string{"archive/a", "archive/b", "archive/parts/c"},
+			expectedResult: CopyResult{
+				Objects: 2,
+				Bytes:   3,
+				Skipped: 1,
+			},
+			checksums: true,
+		},
+		{
+			testName: "reports objects failing checksum verification",
+			source:   map[string]string{"a": "1", "corrupt/b": "x"},
+			expected: []string{"archive/a", "archive/corrupt/b"},
+			expectedResult: CopyResult{
+				Objects: 1,
+				Bytes:   1,
+			},
+			expectedErr: ErrCopyIncomplete,
+			checksums:   true,
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			t.Parallel()
+
+			srcStore := newFakeStore("source", "backup")
+			dstStore := srcStore
+			if !tc.sameClient {
+				dstStore = newFakeStore("backup")
+			}
+
+			for key, data := range tc.source {
+				srcStore.buckets["source"][key] = []byte(data)
+			}
+			for key, data := range tc.existing {
+				dstStore.buckets["backup"][key] = []byte(data)
+			}
+
+			var progress []CopyResult
+
+			opts := []CopyOption{
+				WithCopyWorkers(2),
+				WithCopyLogger(discardLog),
+				WithCopyProgress(func(r CopyResult) { progress = append(progress, r) }),
+			}
+			if tc.checksums {
+				opts = append(opts, WithCopyChecksums())
+			}
+
+			result, err := Copy(t.Context(),
+				Location{Client: srcStore, Region: "us-east", Bucket: "source"},
+				Location{Client: dstStore, Region: "us-east", Bucket: "backup", Prefix: "archive/"},
+				opts...,
+			)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+			}
+
+			if result != tc.expectedResult {
+				t.Errorf("expected result %+v, got %+v", tc.expectedResult, result)
+			}
+
+			if len(progress) == 0 || progress[len(progress)-1] != result {
+				t.Errorf("expected final progress %+v, got %+v", result, progress)
+			}
+
+			if keys := dstStore.keys("backup"); !slices.Equal(keys, tc.expected) {
+				t.Errorf("expected destination keys %v, got %v", tc.expected, keys)
+			}
+
+			if serverSide := srcStore.copies > 0; serverSide != tc.serverSide || (tc.serverSide && dstStore.puts > 0) {
+				t.Errorf("expected server-side copy %t, got %d copies and %d uploads", tc.serverSide, srcStore.copies, dstStore.puts)
+			}
+		})
+	}
+}
+
+func TestCopyInterrupted(t *testing.T) {
+	t.Parallel()
+
+	store := newFakeStore("source", "backup")
+	store.buckets["source"]["a"] = []byte("1")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err := Copy(ctx,
+		Location{Client: store, Region: "us-east", Bucket: "source"},
+		Location{Client: store, Region: "us-east", Bucket: "backup"},
+		WithCopyLogger(discardLog),
+	)
+	if !errors.Is(err, ErrCopyInterrupted) {
+		t.Errorf("expected error %v, got %v", ErrCopyInterrupted, err)
+	}
+}
@@ -16,7 +16,10 @@ package s3
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"text/template"
 )
 
@@ -37,3 +40,151 @@ func ApplyTemplate(policy string, params PolicyTemplateParams) (string, error) {
 
 	return buf.String(), nil
 }
+
+// DenyAllStatementID identifies the statement added by DenyAll.
+const DenyAllStatementID = "CosiDenyAll"
+
+const policyVersion = "2012-10-17"
+
+// denyAllExceptions are the actions not denied by DenyAll, so that the driver can still
+// manage the policy and tags of the bucket.
+var denyAllExceptions = []string{
+	"s3:GetBucketPolicy",
+	"s3:PutBucketPolicy",
+	"s3:DeleteBucketPolicy",
+	"s3:GetBucketTagging",
+	"s3:PutBucketTagging",
+}
+
+// DenyAll adds a statement to the policy denying all access to the bucket, except for
+// managing its policy and tags. The statements of the policy are kept, so that the
+// policy can be restored with AllowAll. Policies denying all access are returned as is.
+func DenyAll(policy, bucket string) (string, error) {
+	doc, statements, err := parsePolicy(policy)
+	if err != nil {
+		return "", err
+	}
+
+	if slices.ContainsFunc(statements, isDenyAll) {
+		return policy, nil
+	}
+
+	statements = append(statements, map[string]any{
+		"Sid":       DenyAllStatementID,
+		"Effect":    "Deny",
+		"Principal": "*",
+		"NotAction": denyAllExceptions,
+		"Resource": []string{
+			"arn:aws:s3:::" + bucket,
+			"arn:aws:s3:::" + bucket + "/*",
+		},
+	})
+
+	return marshalPolicy(doc, statements)
+}
+
+// AllowAll removes the statement added by DenyAll from the policy. An empty policy is
+// returned when no other statements remain.
+func AllowAll(policy string) (string, error) {
+	doc, statements, err := parsePolicy(policy)
+	if err != nil {
+		return "", err
+	}
+
+	if !slices.ContainsFunc(statements, isDenyAll) {
+		return policy, nil
+	}
+
+	statements = slices.DeleteFunc(statements, isDenyAll)
+	if len(statements) == 0 {
+		return "", nil
+	}
+
+	return marshalPolicy(doc, statements)
+}
+
+// RenameBucket rewrites the resources of the policy referring to the bucket or its objects,
+// so that they refer to another bucket. Other resources are kept.
+func RenameBucket(policy, from, to string) (string, error) {
+	if policy == "" {
+		return "", nil
+	}
+
+	doc, statements, err := parsePolicy(policy)
+	if err != nil {
+		return "", err
+	}
+
+	rename := func(resource any) any {
+		arn, ok := resource.(string)
+		if !ok {
+			return resource
+		}
+
+		if rest, ok := strings.CutPrefix(arn, "arn:aws:s3:::"+from); ok && (rest == "" || strings.HasPrefix(rest, "/")) {
+			return "arn:aws:s3:::" + to + rest
+		}
+
+		return arn
+	}
+
+	for _, statement := range statements {
+		s, ok := statement.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		for _, field := range []string{"Resource", "NotResource"} {
+			switch resources := s[field].(type) {
+			case string:
+				s[field] = rename(resources)
+			case []any:
+				for i := range resources {
+					resources[i] = rename(resources[i])
+				}
+			}
+		}
+	}
+
+	return marshalPolicy(doc, statements)
+}
+
+// parsePolicy decodes the policy document and its statements. The statement of policies
+// with a single statement may be an object instead of a list.
+func parsePolicy(policy string) (map[string]any, []any, error) {
+	if policy == "" {
+		return map[string]any{"Version": policyVersion}, nil, nil
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(policy), &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse policy: %w", err)
+	}
+
+	switch statement := doc["Statement"].(type) {
+	case nil:
+		return doc, nil, nil
+	case []any:
+		return doc, statement, nil
+	case map[string]any:
+		return doc, []any{statement}, nil
+	default:
+		return nil, nil, fmt.Errorf("failed to parse policy: unexpected statement %v", statement)
+	}
+}
+
+func marshalPolicy(doc map[string]any, statements []any) (string, error) {
+	doc["Statement"] = statements
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal policy: %w", err)
+	}
+
+	return string(out), nil
+}
+
+func isDenyAll(statement any) bool {
+	s, ok := statement.(map[string]any)
+	return ok && s["Sid"] == DenyAllStatementID
+}
@@ -82,6 +82,100 @@ func TestApplyTemplate(t *testing.T) {
 	}
 }
 
+func TestDenyAll(t *testing.T) {
+	t.Parallel()
+
+	const (
+		allowPolicy    = `{"Version":"2012-10-17","Statement":{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::test-bucket/*"}}`
+		allowStatement = `{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::test-bucket/*"}`
+		denyStatement  = `{
+  "Sid": "CosiDenyAll",
+  "Effect": "Deny",
+  "Principal": "*",
+  "NotAction": ["s3:GetBucketPolicy", "s3:PutBucketPolicy", "s3:DeleteBucketPolicy", "s3:GetBucketTagging", "s3:PutBucketTagging"],
+  "Resource": ["arn:aws:s3:::test-bucket", "arn:aws:s3:::test-bucket/*"]
+}`
+	)
+
+	for name, tc := range map[string]struct {
+		policy   string
+		denied   string
+		restored string
+	}{
+		"without policy": {
+			policy:   "",
+			denied:   `{"Version":"2012-10-17","Statement":[` + denyStatement + `]}`,
+			restored: "",
+		},
+		"with policy": {
+			policy:   allowPolicy,
+			denied:   `{"Version":"2012-10-17","Statement":[` + allowStatement + `,` + denyStatement + `]}`,
+			restored: `{"Version":"2012-10-17","Statement":[` + allowStatement + `]}`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			denied, err := DenyAll(tc.policy, "test-bucket")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if normalizeJSON(t, denied) != normalizeJSON(t, tc.denied) {
+				t.Errorf("expected policy: %v, but got: %v", tc.denied, denied)
+			}
+
+			again, err := DenyAll(denied, "test-bucket")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if again != denied {
+				t.Errorf("expected policy to be denied once, but got: %v", again)
+			}
+
+			restored, err := AllowAll(denied)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tc.restored == "" {
+				if restored != "" {
+					t.Errorf("expected empty policy, but got: %v", restored)
+				}
+			} else if normalizeJSON(t, restored) != normalizeJSON(t, tc.restored) {
+				t.Errorf("expected policy: %v, but got: %v", tc.restored, restored)
+			}
+		})
+	}
+
+	if _, err := DenyAll("{", "test-bucket"); err == nil {
+		t.Error("expected error for invalid policy")
+	}
+}
+
+func TestRenameBucket(t *testing.T) {
+	t.Parallel()
+
+	const (
+		policy   = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:*","Resource":["arn:aws:s3:::test-bucket","arn:aws:s3:::test-bucket/*","arn:aws:s3:::test-bucket-logs/*"]},{"Effect":"Deny","Principal":"*","Action":"s3:DeleteObject","NotResource":"arn:aws:s3:::test-bucket/tmp/*"}]}`
+		expected = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:*","Resource":["arn:aws:s3:::new-bucket","arn:aws:s3:::new-bucket/*","arn:aws:s3:::test-bucket-logs/*"]},{"Effect":"Deny","Principal":"*","Action":"s3:DeleteObject","NotResource":"arn:aws:s3:::new-bucket/tmp/*"}]}`
+	)
+
+	renamed, err := RenameBucket(policy, "test-bucket", "new-bucket")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if normalizeJSON(t, renamed) != normalizeJSON(t, expected) {
+		t.Errorf("expected policy: %v, but got: %v", expected, renamed)
+	}
+
+	if renamed, err := RenameBucket("", "test-bucket", "new-bucket"); err != nil || renamed != "" {
+		t.Errorf("expected empty policy, but got: %q, %v", renamed, err)
+	}
+}
+
 func normalizeJSON(t *testing.T, input string) string {
 	t.Helper()
 
@@ -0,0 +1,371 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/linode/linode-cosi-driver/pkg/metrics"
+)
+
+// DefaultPruneWorkers is the default number of concurrent delete requests of a prune.
+const DefaultPruneWorkers = 8
+
+const (
+	// maxDeleteBatch is the maximum number of objects removed by a single DeleteObjects request.
+	maxDeleteBatch = 1000
+	// pruneProgressInterval is how often the progress of a prune is logged.
+	pruneProgressInterval = 10 * time.Second
+	// pruneDeadlineMargin is reserved before the deadline of the caller, so that an
+	// interrupted prune is reported instead of timing out.
+	pruneDeadlineMargin = 5 * time.Second
+	// maxPruneErrorSamples is the number of failed objects named in a PruneError.
+	maxPruneErrorSamples = 5
+)
+
+// Kinds of pruned entries, used as metric labels.
+const (
+	pruneKindObject       = "object"
+	pruneKindVersion      = "version"
+	pruneKindDeleteMarker = "delete_marker"
+	pruneKindUpload       = "upload"
+)
+
+// ErrPruneInterrupted is returned when a prune was interrupted before the bucket was empty.
+// Pruning the bucket again resumes with the remaining objects.
+var ErrPruneInterrupted = errors.New("prune interrupted")
+
+// PruneError summarizes the objects a prune failed to remove.
+type PruneError struct {
+	// Failed is the number of objects, versions and uploads that were not removed.
+	Failed int64
+	// Codes counts the failures by S3 error code.
+	Codes map[string]int64
+	// Samples names some of the objects that were not removed.
+	Samples []string
+}
+
+func (e *PruneError) Error() string {
+	codes := make([]string, 0, len(e.Codes))
+	for _, code := range slices.Sorted(maps.Keys(e.Codes)) {
+		codes = append(codes, fmt.Sprintf("%s: %d", code, e.Codes[code]))
+	}
+
+	return fmt.Sprintf("failed to remove %d objects (%s), e.g. %s",
+		e.Failed, strings.Join(codes, ", "), strings.Join(e.Samples, ", "))
+}
+
+// pruner removes all objects, versions, delete markers and incomplete multipart uploads of a bucket.
+// Listed objects are removed in batches by a bounded number of workers.
+type pruner struct {
+	log        *slog.Logger
+	core       minio.Core
+	bucket     string
+	workers    int
+	batchSize  int
+	onProgress func(objects, bytes int64)
+
+	objects atomic.Int64
+	bytes   atomic.Int64
+	uploads atomic.Int64
+
+	mu       sync.Mutex
+	failures PruneError
+}
+
+type objectVersion struct {
+	key, versionID string
+}
+
+func (p *pruner) run(ctx context.Context) error {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) > 2*pruneDeadlineMargin {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-pruneDeadlineMargin))
+		defer cancel()
+	}
+
+	start := time.Now()
+	stop := p.reportProgress(ctx, start)
+
+	err := p.abortUploads(ctx)
+	if err == nil {
+		err = p.removeObjects(ctx)
+	}
+
+	stop()
+
+	p.log.InfoContext(ctx, "Prune finished", p.progress(start)...)
+	p.notify()
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("%w after removing %d objects: %w", ErrPruneInterrupted, p.objects.Load(), ctx.Err())
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if p.failures.Failed > 0 {
+		return &p.failures
+	}
+
+	return nil
+}
+
+// abortUploads aborts all incomplete multipart uploads.
+func (p *pruner) abortUploads(ctx context.Context) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	sem := make(chan struct{}, p.workers)
+
+	var keyMarker, uploadIDMarker string
+
+	for {
+		res, err := p.core.ListMultipartUploads(ctx, p.bucket, "", keyMarker, uploadIDMarker, "", maxDeleteBatch)
+		if err != nil {
+			return err
+		}
+
+		for _, upload := range res.Uploads {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			wg.Go(func() {
+				defer func() { <-sem }()
+
+				err := p.core.AbortMultipartUpload(ctx, p.bucket, upload.Key, upload.UploadID)
+				if err != nil && minio.ToErrorResponse(err).Code != errCodeNoSuchUpload {
+					p.fail(upload.Key, upload.UploadID, err)
+					return
+				}
+
+				p.uploads.Add(1)
+				metrics.PrunedObjects.WithLabelValues(pruneKindUpload).Inc()
+			})
+		}
+
+		if !res.IsTruncated {
+			return nil
+		}
+
+		keyMarker, uploadIDMarker = res.NextKeyMarker, res.NextUploadIDMarker
+	}
+}
+
+// removeObjects lists all object versions and removes them in batches.
+func (p *pruner) removeObjects(ctx context.Context) error {
+	batches := make(chan []minio.ObjectInfo)
+
+	var wg sync.WaitGroup
+	for range p.workers {
+		wg.Go(func() {
+			for batch := range batches {
+				p.removeBatch(ctx, batch)
+			}
+		})
+	}
+
+	err := p.listObjects(ctx, batches)
+
+	close(batches)
+	wg.Wait()
+
+	return err
+}
+
+func (p *pruner) listObjects(ctx context.Context, batches chan<- []minio.ObjectInfo) error {
+	send := func(batch []minio.ObjectInfo) error {
+		select {
+		case batches <- batch:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	batch := make([]minio.ObjectInfo, 0, p.batchSize)
+
+	for obj := range p.core.ListObjectsIter(ctx, p.bucket, minio.ListObjectsOptions{Recursive: true, WithVersions: true}) {
+		if obj.Err != nil {
+			return obj.Err
+		}
+
+		batch = append(batch, obj)
+		if len(batch) < p.batchSize {
+			continue
+		}
+
+		if err := send(batch); err != nil {
+			return err
+		}
+
+		batch = make([]minio.ObjectInfo, 0, p.batchSize)
+	}
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	return send(batch)
+}
+
+func (p *pruner) removeBatch(ctx context.Context, batch []minio.ObjectInfo) {
+	failed := make(map[objectVersion]struct{})
+
+	results, err := p.core.RemoveObjectsWithIter(ctx, p.bucket, slices.Values(batch), minio.RemoveObjectsOptions{})
+	if err != nil {
+		for _, obj := range batch {
+			p.fail(obj.Key, obj.VersionID, err)
+		}
+
+		return
+	}
+
+	for res := range results {
+		if res.Err == nil {
+			continue
+		}
+
+		// Errors not bound to an object, e.g. an undecodable response, fail the whole batch.
+		if res.ObjectName == "" {
+			for _, obj := range batch {
+				if _, ok := failed[objectVersion{obj.Key, obj.VersionID}]; !ok {
+					p.fail(obj.Key, obj.VersionID, res.Err)
+				}
+			}
+
+			return
+		}
+
+		failed[objectVersion{res.ObjectName, res.ObjectVersionID}] = struct{}{}
+		p.fail(res.ObjectName, res.ObjectVersionID, res.Err)
+	}
+
+	for _, obj := range batch {
+		if _, ok := failed[objectVersion{obj.Key, obj.VersionID}]; ok {
+			continue
+		}
+
+		p.objects.Add(1)
+		p.bytes.Add(obj.Size)
+		metrics.PrunedObjects.WithLabelValues(objectKind(obj)).Inc()
+		metrics.PrunedBytes.Add(float64(obj.Size))
+	}
+}
+
+func (p *pruner) fail(key, id string, err error) {
+	code := minio.ToErrorResponse(err).Code
+	if code == "" {
+		code = "Unknown"
+	}
+
+	metrics.PruneFailures.Inc()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failures.Codes == nil {
+		p.failures.Codes = make(map[string]int64)
+	}
+
+	p.failures.Failed++
+	p.failures.Codes[code]++
+
+	if len(p.failures.Samples) < maxPruneErrorSamples {
+		if id != "" && id != nullVersionID {
+			key += " (" + id + ")"
+		}
+
+		p.failures.Samples = append(p.failures.Samples, key)
+	}
+}
+
+// reportProgress logs the progress until the returned function is called.
+func (p *pruner) reportProgress(ctx context.Context, start time.Time) func() {
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ticker := time.NewTicker(pruneProgressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				p.log.InfoContext(ctx, "Pruning bucket", p.progress(start)...)
+				p.notify()
+			}
+		}
+	})
+
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+func (p *pruner) progress(start time.Time) []any {
+	p.mu.Lock()
+	failed := p.failures.Failed
+	p.mu.Unlock()
+
+	return []any{
+		slog.Int64("objects", p.objects.Load()),
+		slog.Int64("bytes", p.bytes.Load()),
+		slog.Int64("uploads", p.uploads.Load()),
+		slog.Int64("failed", failed),
+		slog.Duration("duration", time.Since(start)),
+	}
+}
+
+func (p *pruner) notify() {
+	if p.onProgress != nil {
+		p.onProgress(p.objects.Load(), p.bytes.Load())
+	}
+}
+
+const (
+	errCodeNoSuchUpload = "NoSuchUpload"
+	nullVersionID       = "null"
+)
+
+func objectKind(obj minio.ObjectInfo) string {
+	switch {
+	case obj.IsDeleteMarker:
+		return pruneKindDeleteMarker
+	case !obj.IsLatest:
+		return pruneKindVersion
+	default:
+		return pruneKindObject
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testBucket = "test-bucket"
+
+type fakeVersion struct {
+	Key          string
+	VersionID    string
+	Size         int64
+	Latest       bool
+	DeleteMarker bool
+}
+
+type fakeUpload struct {
+	Key      string
+	UploadID string
+}
+
+// fakeBucket is a minimal S3 server for a single versioned bucket. Objects prefixed
+// with "locked/" cannot be removed.
+type fakeBucket struct {
+	mu       sync.Mutex
+	missing  bool
+	versions []fakeVersion
+	uploads  []fakeUpload
+}
+
+func (b *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.missing {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `<Error><Code>NoSuchBucket</Code><BucketName>%s</BucketName></Error>`, testBucket)
+
+		return
+	}
+
+	query := r.URL.Query()
+
+	switch {
+	case r.Method == http.MethodGet && query.Has("versions"):
+		fmt.Fprint(w, `<ListVersionsResult><Name>`+testBucket+`</Name><IsTruncated>false</IsTruncated>`)
+		for _, v := range b.versions {
+			tag := "Version"
+			if v.DeleteMarker {
+				tag = "DeleteMarker"
+			}
+			fmt.Fprintf(w, `<%[1]s><Key>%s</Key><VersionId>%s</VersionId><IsLatest>%t</IsLatest><Size>%d</Size>`+
+				`<LastModified>2025-01-01T00:00:00.000Z</LastModified></%[1]s>`, tag, v.Key, v.VersionID, v.Latest, v.Size)
+		}
+		fmt.Fprint(w, `</ListVersionsResult>`)
+
+	case r.Method == http.MethodGet && query.Has("uploads"):
+		fmt.Fprint(w, `<ListMultipartUploadsResult><Bucket>`+testBucket+`</Bucket><IsTruncated>false</IsTruncated>`)
+		for _, u := range b.uploads {
+			fmt.Fprintf(w, `<Upload><Key>%s</Key><UploadId>%s</UploadId></Upload>`, u.Key, u.UploadID)
+		}
+		fmt.Fprint(w, `</ListMultipartUploadsResult>`)
+
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		b.uploads = slices.DeleteFunc(b.uploads, func(u fakeUpload) bool { return u.UploadID == query.Get("uploadId") })
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodPost && query.Has("delete"):
+		b.deleteObjects(w, r)
+
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (b *fakeBucket) deleteObjects(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Objects []struct {
+			Key       string
+			VersionID string `xml:"VersionId"`
+		} `xml:"Object"`
+	}
+
+	raw, _ := io.ReadAll(r.Body)
+	if err := xml.Unmarshal(raw, &req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	fmt.Fprint(w, `<DeleteResult>`)
+	for _, obj := range req.Objects {
+		if strings.HasPrefix(obj.Key, "locked/") {
+			fmt.Fprintf(w, `<Error><Key>%s</Key><VersionId>%s</VersionId><Code>AccessDenied</Code></Error>`, obj.Key, obj.VersionID)
+			continue
+		}
+
+		b.versions = slices.DeleteFunc(b.versions, func(v fakeVersion) bool {
+			return v.Key == obj.Key && v.VersionID == obj.VersionID
+		})
+		fmt.Fprintf(w, `<Deleted><Key>%s</Key><VersionId>%s</VersionId></Deleted>`, obj.Key, obj.VersionID)
+	}
+	fmt.Fprint(w, `</DeleteResult>`)
+}
+
+func (b *fakeBucket) remaining() ([]string, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	keys := make([]string, 0, len(b.versions))
+	for _, v := range b.versions {
+		keys = append(keys, v.Key+"@"+v.VersionID)
+	}
+
+	return keys, len(b.uploads)
+}
+
+func newPruneClient(t *testing.T, bucket *fakeBucket) *ClientS3 {
+	t.Helper()
+
+	srv := httptest.NewServer(bucket)
+	t.Cleanup(srv.Close)
+
+	client := NewWithEndpoint(strings.TrimPrefix(srv.URL, "http://"), "access-key", "secret-key", false,
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+		WithPruneWorkers(3))
+	client.pruneBatchSize = 2
+
+	return client
+}
+
+func TestPrune(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		testName          string // required
+		bucket            *fakeBucket
+		cancel            bool
+		expectedRemaining []string
+		expectedRemoved   int64
+		expectedError     error
+		expectedFailures  *PruneError
+	}{
+		{
+			testName: "versions, delete markers and uploads",
+			bucket: &fakeBucket{
+				versions: []fakeVersion{
+					{Key: "a", VersionID: "1", Size: 10, Latest: true},
+					{Key: "a", VersionID: "0", Size: 5},
+					{Key: "b", VersionID: "2", Latest: true, DeleteMarker: true},
+					{Key: "b", VersionID: "1", Size: 7},
+					{Key: "c", VersionID: "null", Size: 1, Latest: true},
+				},
+				uploads: []fakeUpload{{Key: "d", UploadID: "u1"}, {Key: "d", UploadID: "u2"}},
+			},
+			expectedRemaining: []string{},
+			expectedRemoved:   5,
+		},
+		{
+			testName: "summarized failures",
+			bucket: &fakeBucket{
+				versions: []fakeVersion{
+					{Key: "a", VersionID: "1", Latest: true},
+					{Key: "locked/b", VersionID: "1", Latest: true},
+					{Key: "locked/c", VersionID: "null", Latest: true},
+				},
+			},
+			expectedRemaining: []string{"locked/b@1", "locked/c@null"},
+			expectedRemoved:   1,
+			expectedFailures: &PruneError{
+				Failed:  2,
+				Codes:   map[string]int64{"AccessDenied": 2},
+				Samples: []string{"locked/b (1)", "locked/c"},
+			},
+		},
+		{
+			testName: "interrupted",
+			bucket: &fakeBucket{
+				versions: []fakeVersion{{Key: "a", VersionID: "1", Latest: true}},
+			},
+			cancel:            true,
+			expectedRemaining: []string{"a@1"},
+			expectedError:     ErrPruneInterrupted,
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			t.Parallel()
+
+			client := newPruneClient(t, tc.bucket)
+
+			ctx, cancel := context.WithCancel(t.Context())
+			if tc.cancel {
+				cancel()
+			}
+			defer cancel()
+
+			var removed int64
+			err := client.Prune(ctx, "us-ord", testBucket, func(objects, _ int64) { removed = objects })
+
+			var failures *PruneError
+			switch {
+			case tc.expectedFailures != nil:
+				if !errors.As(err, &failures) {
+					t.Fatalf("expected prune error, got: %v", err)
+				}
+
+				slices.Sort(failures.Samples)
+				if !reflect.DeepEqual(failures, tc.expectedFailures) {
+					t.Errorf("expected failures %+v, got %+v", tc.expectedFailures, failures)
+				}
+			case !errors.Is(err, tc.expectedError):
+				t.Errorf("expected error: %v, but got: %v", tc.expectedError, err)
+			}
+
+			remaining, uploads := tc.bucket.remaining()
+			slices.Sort(remaining)
+			if !slices.Equal(remaining, tc.expectedRemaining) {
+				t.Errorf("expected remaining objects %v, got %v", tc.expectedRemaining, remaining)
+			}
+
+			if !tc.cancel && uploads != 0 {
+				t.Errorf("expected all uploads to be aborted, %d remaining", uploads)
+			}
+
+			if removed != tc.expectedRemoved {
+				t.Errorf("expected %d removed objects to be reported, got %d", tc.expectedRemoved, removed)
+			}
+		})
+	}
+}
+
+func TestPruneMissingBucket(t *testing.T) {
+	t.Parallel()
+
+	client := newPruneClient(t, &fakeBucket{missing: true})
+
+	if err := client.Prune(t.Context(), "us-ord", testBucket, nil); !IsNotFound(err) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
@@ -18,18 +18,35 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"sync"
 
+	"github.com/linode/linodego/v2"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/tags"
 
 	"github.com/linode/linode-cosi-driver/pkg/linodeclient/cache"
 )
 
 type Client interface {
-	Prune(ctx context.Context, region, bucket string) error
+	// Prune removes all objects of the bucket. The progress function, if any, is called periodically
+	// and once the prune finished, with the number of objects and bytes removed so far.
+	Prune(ctx context.Context, region, bucket string, progress func(objects, bytes int64)) error
 	SetBucketPolicy(ctx context.Context, region, bucketName, policy string) error
 	GetBucketPolicy(ctx context.Context, region, bucketName string) (string, error)
+	GetBucketTags(ctx context.Context, region, bucketName string) (map[string]string, error)
+	SetBucketTags(ctx context.Context, region, bucketName string, tags map[string]string) error
+	// ListObjects calls fn with the current version of every object below the prefix.
+	ListObjects(ctx context.Context, region, bucketName, prefix string, fn func(minio.ObjectInfo) error) error
+	// GetObject returns the content and metadata of the object. The content must be closed.
+	GetObject(ctx context.Context, region, bucketName, key string) (io.ReadCloser, minio.ObjectInfo, error)
+	// PutObject uploads the content of the object, with the size, content type and user metadata of info.
+	PutObject(ctx context.Context, region, bucketName, key string, body io.Reader, info minio.ObjectInfo) error
+	// CopyObject copies the object server-side into another bucket served by the same endpoint.
+	CopyObject(ctx context.Context, region, srcBucketName, srcKey, dstBucketName, dstKey string) error
 }
 
 type ClientS3 struct {
@@ -38,34 +55,68 @@ type ClientS3 struct {
 	s3AccessKey string
 	s3SecretKey string
 	s3SSL       bool
+	typed       map[linodego.ObjectStorageEndpointType]Credentials
+
+	log            *slog.Logger
+	pruneWorkers   int
+	pruneBatchSize int
+
+	mu      sync.Mutex
+	clients map[string]*minio.Client
+	// bound holds the clients returned by ForEndpoint.
+	bound *Clients
 }
 
+// Shared transports of all minio clients, so that connections are reused.
+var (
+	secureTransport   = sync.OnceValues(func() (*http.Transport, error) { return minio.DefaultTransport(true) })
+	insecureTransport = sync.OnceValues(func() (*http.Transport, error) { return minio.DefaultTransport(false) })
+)
+
 var _ Client = (*ClientS3)(nil)
 
 func New(
 	cache cache.Cache,
 	s3AccessKey, s3SecretKey string,
 	s3SSL bool,
+	opts ...Option,
 ) *ClientS3 {
-	return &ClientS3{
+	c := &ClientS3{
 		cache:       cache,
 		s3AccessKey: s3AccessKey,
 		s3SecretKey: s3SecretKey,
 		s3SSL:       s3SSL,
 	}
+
+	return c.apply(opts)
 }
 
 func NewWithEndpoint(
 	endpoint string,
 	s3AccessKey, s3SecretKey string,
 	s3SSL bool,
+	opts ...Option,
 ) *ClientS3 {
-	return &ClientS3{
+	c := &ClientS3{
 		endpoint:    endpoint,
 		s3AccessKey: s3AccessKey,
 		s3SecretKey: s3SecretKey,
 		s3SSL:       s3SSL,
 	}
+
+	return c.apply(opts)
+}
+
+func (c *ClientS3) apply(opts []Option) *ClientS3 {
+	c.log = slog.Default()
+	c.pruneWorkers = DefaultPruneWorkers
+	c.pruneBatchSize = maxDeleteBatch
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
 func (c *ClientS3) new(region string) (*minio.Client, error) {
@@ -80,33 +131,60 @@ func (c *ClientS3) new(region string) (*minio.Client, error) {
 		}
 	}
 
+	key := endpoint + "/" + region
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cli, ok := c.clients[key]; ok {
+		return cli, nil
+	}
+
+	transport, err := insecureTransport()
+	if c.s3SSL {
+		transport, err = secureTransport()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to create transport: %w", err)
+	}
+
 	cli, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(c.s3AccessKey, c.s3SecretKey, ""),
-		Region: region,
-		Secure: c.s3SSL,
+		Creds:     credentials.NewStaticV4(c.s3AccessKey, c.s3SecretKey, ""),
+		Region:    region,
+		Secure:    c.s3SSL,
+		Transport: transport,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("unable to instantiate client: %w", err)
 	}
 
+	if c.clients == nil {
+		c.clients = make(map[string]*minio.Client)
+	}
+	c.clients[key] = cli
+
 	return cli, nil
 }
 
-func (c *ClientS3) Prune(ctx context.Context, region, bucket string) error {
+// Prune removes all objects of the bucket, including noncurrent versions, delete markers and
+// incomplete multipart uploads. Objects failing to be removed are reported as a PruneError.
+// Prunes interrupted by the context, or shortly before its deadline, return ErrPruneInterrupted.
+func (c *ClientS3) Prune(ctx context.Context, region, bucket string, progress func(objects, bytes int64)) error {
 	cli, err := c.new(region)
 	if err != nil {
 		return err
 	}
 
-	oiChan := cli.ListObjects(ctx, bucket, minio.ListObjectsOptions{Recursive: true})
-
-	errChan := cli.RemoveObjects(ctx, bucket, oiChan, minio.RemoveObjectsOptions{})
-
-	for oerr := range errChan {
-		err = errors.Join(err, oerr.Err)
+	p := &pruner{
+		log:        c.log.With(slog.String("bucket", bucket)),
+		core:       minio.Core{Client: cli},
+		bucket:     bucket,
+		workers:    max(c.pruneWorkers, 1),
+		batchSize:  min(max(c.pruneBatchSize, 1), maxDeleteBatch),
+		onProgress: progress,
 	}
 
-	return err
+	return p.run(ctx)
 }
 
 func (c *ClientS3) SetBucketPolicy(ctx context.Context, region, bucket, policy string) error {
@@ -136,7 +214,119 @@ func (c *ClientS3) GetBucketPolicy(ctx context.Context, region, bucket string) (
 	return cli.GetBucketPolicy(ctx, bucket)
 }
 
+// GetBucketTags returns the tags of the bucket. Buckets without tags return an empty map.
+func (c *ClientS3) GetBucketTags(ctx context.Context, region, bucket string) (map[string]string, error) {
+	cli, err := c.new(region)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := cli.GetBucketTagging(ctx, bucket)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == errCodeNoSuchTagSet {
+			return map[string]string{}, nil
+		}
+
+		return nil, err
+	}
+
+	return t.ToMap(), nil
+}
+
+// SetBucketTags replaces the tags of the bucket.
+func (c *ClientS3) SetBucketTags(ctx context.Context, region, bucket string, bucketTags map[string]string) error {
+	cli, err := c.new(region)
+	if err != nil {
+		return err
+	}
+
+	t, err := tags.NewTags(bucketTags, false)
+	if err != nil {
+		return fmt.Errorf("invalid bucket tags: %w", err)
+	}
+
+	return cli.SetBucketTagging(ctx, bucket, t)
+}
+
+// ListObjects calls fn with the current version of every object below the prefix, stopping at
+// the first error returned by fn.
+func (c *ClientS3) ListObjects(ctx context.Context, region, bucket, prefix string, fn func(minio.ObjectInfo) error) error {
+	cli, err := c.new(region)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for obj := range cli.ListObjectsIter(ctx, bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return obj.Err
+		}
+
+		if err := fn(obj); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetObject returns the content and metadata of the object. The content must be closed.
+func (c *ClientS3) GetObject(ctx context.Context, region, bucket, key string) (io.ReadCloser, minio.ObjectInfo, error) {
+	cli, err := c.new(region)
+	if err != nil {
+		return nil, minio.ObjectInfo{}, err
+	}
+
+	obj, err := cli.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, minio.ObjectInfo{}, err
+	}
+
+	info, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, minio.ObjectInfo{}, err
+	}
+
+	return obj, info, nil
+}
+
+// PutObject uploads the content of the object, with the size, content type and user metadata of info.
+func (c *ClientS3) PutObject(ctx context.Context, region, bucket, key string, body io.Reader, info minio.ObjectInfo) error {
+	cli, err := c.new(region)
+	if err != nil {
+		return err
+	}
+
+	_, err = cli.PutObject(ctx, bucket, key, body, info.Size, minio.PutObjectOptions{
+		ContentType:  info.ContentType,
+		UserMetadata: info.UserMetadata,
+	})
+
+	return err
+}
+
+// CopyObject copies the object server-side into another bucket served by the same endpoint.
+// Objects larger than 5 GiB cannot be copied by a single request, and must be streamed instead.
+func (c *ClientS3) CopyObject(ctx context.Context, region, srcBucket, srcKey, dstBucket, dstKey string) error {
+	cli, err := c.new(region)
+	if err != nil {
+		return err
+	}
+
+	_, err = cli.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: dstBucket, Object: dstKey},
+		minio.CopySrcOptions{Bucket: srcBucket, Object: srcKey},
+	)
+
+	return err
+}
+
+const errCodeNoSuchTagSet = "NoSuchTagSet"
+
 func IsNotFound(err error) bool {
-	res := minio.ToErrorResponse(err)
-	return res.StatusCode == http.StatusNotFound
+	var res minio.ErrorResponse
+	return errors.As(err, &res) && res.StatusCode == http.StatusNotFound
 }
@@ -14,7 +14,14 @@
 
 package s3
 
-import "testing"
+import (
+	"errors"
+	"testing"
+
+	"github.com/linode/linodego/v2"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient/cache"
+)
 
 func TestNewWithEndpointDoesNotRequireCache(t *testing.T) {
 	t.Parallel()
@@ -25,3 +32,144 @@ func TestNewWithEndpointDoesNotRequireCache(t *testing.T) {
 		t.Fatalf("expected explicit endpoint client creation to succeed: %v", err)
 	}
 }
+
+func TestClientReusesMinioClients(t *testing.T) {
+	t.Parallel()
+
+	client := NewWithEndpoint("selected.example.com", "access-key", "secret-key", true)
+
+	first, err := client.new("us-ord")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second, err := client.new("us-ord")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first != second {
+		t.Errorf("expected minio client to be reused")
+	}
+}
+
+func TestClients(t *testing.T) {
+	t.Parallel()
+
+	clients := NewClients(true)
+
+	first := clients.Get("selected.example.com", "access-key", "secret-key")
+	if clients.Get("selected.example.com", "access-key", "secret-key") != first {
+		t.Errorf("expected client to be reused")
+	}
+
+	if clients.Get("other.example.com", "access-key", "secret-key") == first {
+		t.Errorf("expected separate clients for separate endpoints")
+	}
+
+	clients.Forget("access-key")
+
+	if clients.Get("selected.example.com", "access-key", "secret-key") == first {
+		t.Errorf("expected forgotten client to be recreated")
+	}
+}
+
+type staticCache map[string]string
+
+func (c staticCache) Get(key string) (string, bool) {
+	val, ok := c[key]
+	return val, ok
+}
+
+func (c staticCache) Endpoints() []cache.Endpoint { return nil }
+
+func (c staticCache) Endpoint(string, linodego.ObjectStorageEndpointType) (cache.Endpoint, bool) {
+	return cache.Endpoint{}, false
+}
+
+func TestForEndpoint(t *testing.T) {
+	t.Parallel()
+
+	endpoints := staticCache{
+		"us-ord":    "us-ord-1.linodeobjects.com",
+		"us-ord/E2": "us-ord-2.linodeobjects.com",
+	}
+
+	for _, tc := range []struct {
+		testName          string // required
+		defaultCreds      Credentials
+		opts              []Option
+		endpoint          string
+		endpointType      linodego.ObjectStorageEndpointType
+		expectedEndpoint  string
+		expectedAccessKey string
+		expectedError     error
+	}{
+		{
+			testName:          "given endpoint",
+			defaultCreds:      Credentials{AccessKey: "default", SecretKey: "secret"},
+			endpoint:          "us-ord-3.linodeobjects.com",
+			endpointType:      linodego.ObjectStorageEndpointE3,
+			expectedEndpoint:  "us-ord-3.linodeobjects.com",
+			expectedAccessKey: "default",
+		},
+		{
+			testName:          "resolved endpoint of type",
+			defaultCreds:      Credentials{AccessKey: "default", SecretKey: "secret"},
+			endpointType:      linodego.ObjectStorageEndpointE2,
+			expectedEndpoint:  "us-ord-2.linodeobjects.com",
+			expectedAccessKey: "default",
+		},
+		{
+			testName:     "endpoint type credentials",
+			defaultCreds: Credentials{AccessKey: "default", SecretKey: "secret"},
+			opts: []Option{
+				WithEndpointTypeCredentials(linodego.ObjectStorageEndpointE2, Credentials{AccessKey: "e2", SecretKey: "secret"}),
+			},
+			endpointType:      linodego.ObjectStorageEndpointE2,
+			expectedEndpoint:  "us-ord-2.linodeobjects.com",
+			expectedAccessKey: "e2",
+		},
+		{
+			testName: "missing credentials of type",
+			opts: []Option{
+				WithEndpointTypeCredentials(linodego.ObjectStorageEndpointE2, Credentials{AccessKey: "e2", SecretKey: "secret"}),
+			},
+			endpointType:  linodego.ObjectStorageEndpointE3,
+			expectedError: ErrNoCredentials,
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			t.Parallel()
+
+			client := New(endpoints, tc.defaultCreds.AccessKey, tc.defaultCreds.SecretKey, true, tc.opts...)
+
+			cli, err := client.ForEndpoint("us-ord", tc.endpoint, tc.endpointType)
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("expected error: %v, but got: %v", tc.expectedError, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			bound, ok := cli.(*ClientS3)
+			if !ok {
+				t.Fatalf("expected *ClientS3, got %T", cli)
+			}
+
+			if bound.endpoint != tc.expectedEndpoint {
+				t.Errorf("expected endpoint %q, got %q", tc.expectedEndpoint, bound.endpoint)
+			}
+
+			if bound.s3AccessKey != tc.expectedAccessKey {
+				t.Errorf("expected access key %q, got %q", tc.expectedAccessKey, bound.s3AccessKey)
+			}
+
+			again, err := client.ForEndpoint("us-ord", tc.endpoint, tc.endpointType)
+			if err != nil || again != cli {
+				t.Errorf("expected bound client to be reused")
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/linode/linodego/v2"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient/cache"
+)
+
+// ErrNoCredentials is returned when no static credentials are configured for an endpoint type.
+var ErrNoCredentials = errors.New("no static S3 credentials for endpoint type")
+
+// Credentials is a static S3 key pair.
+type Credentials struct {
+	AccessKey string
+	SecretKey string
+}
+
+// Option configures the ClientS3.
+type Option func(*ClientS3)
+
+// WithEndpointTypeCredentials uses the key pair for buckets served by endpoints of the type,
+// instead of the default credentials.
+func WithEndpointTypeCredentials(endpointType linodego.ObjectStorageEndpointType, creds Credentials) Option {
+	return func(c *ClientS3) {
+		if c.typed == nil {
+			c.typed = make(map[linodego.ObjectStorageEndpointType]Credentials)
+		}
+
+		c.typed[endpointType] = creds
+	}
+}
+
+// WithLogger sets the logger used to report the progress of long running operations.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *ClientS3) {
+		c.log = logger
+	}
+}
+
+// WithPruneWorkers sets the number of concurrent delete requests of a prune.
+func WithPruneWorkers(workers int) Option {
+	return func(c *ClientS3) {
+		c.pruneWorkers = workers
+	}
+}
+
+// EndpointBinder is implemented by clients able to serve buckets of a specific endpoint.
+type EndpointBinder interface {
+	// ForEndpoint returns a client for buckets served by the endpoint. Without an endpoint,
+	// the endpoint of the type in the region is resolved.
+	ForEndpoint(region, endpoint string, endpointType linodego.ObjectStorageEndpointType) (Client, error)
+}
+
+var _ EndpointBinder = (*ClientS3)(nil)
+
+// ForEndpoint returns a client for buckets served by the endpoint, using the credentials
+// configured for the endpoint type. Without an endpoint, the endpoint of the type in the
+// region is resolved through the cache.
+func (c *ClientS3) ForEndpoint(region, endpoint string, endpointType linodego.ObjectStorageEndpointType) (Client, error) {
+	creds, err := c.credentials(endpointType)
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoint == "" {
+		var ok bool
+		if c.cache != nil {
+			endpoint, ok = c.cache.Get(cache.Key(region, endpointType))
+		}
+		if !ok || endpoint == "" {
+			return nil, fmt.Errorf("failed to get %s endpoint for region: %s", endpointType, region)
+		}
+	}
+
+	c.mu.Lock()
+	if c.bound == nil {
+		c.bound = NewClients(c.s3SSL, WithLogger(c.log), WithPruneWorkers(c.pruneWorkers))
+	}
+	bound := c.bound
+	c.mu.Unlock()
+
+	return bound.Get(endpoint, creds.AccessKey, creds.SecretKey), nil
+}
+
+// credentials returns the credentials of the endpoint type, falling back to the default credentials.
+func (c *ClientS3) credentials(endpointType linodego.ObjectStorageEndpointType) (Credentials, error) {
+	if creds, ok := c.typed[endpointType]; ok {
+		return creds, nil
+	}
+
+	if c.s3AccessKey == "" || c.s3SecretKey == "" {
+		return Credentials{}, fmt.Errorf("%w: %q", ErrNoCredentials, endpointType)
+	}
+
+	return Credentials{AccessKey: c.s3AccessKey, SecretKey: c.s3SecretKey}, nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"time"
+
+	"github.com/linode/linode-cosi-driver/pkg/deletion"
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/pkg/s3"
+)
+
+// archiveTimestampFormat formats the time of an archive in its prefix.
+const archiveTimestampFormat = "20060102T150405Z"
+
+// parseArchiveTarget parses the backup bucket receiving the objects of pruned buckets, in the
+// form "region/label".
+func parseArchiveTarget(value string) (bucketRef, error) {
+	ref, err := parseBucketLocation(value)
+	if err != nil {
+		return bucketRef{}, fmt.Errorf("%w: %w", ErrInvalidArchiveTarget, err)
+	}
+
+	return ref, nil
+}
+
+// archiveQueued archives the bucket of the task, recording the archived objects.
+func (s *Server) archiveQueued(ctx context.Context, log *slog.Logger, task *deletion.Task, update func(deletion.Task)) error {
+	ref := bucketRef{
+		Region:       task.Region,
+		Label:        task.Label,
+		EndpointType: task.EndpointType,
+		Cleanup:      task.Cleanup,
+		ArchiveTo:    task.ArchiveTo,
+	}
+
+	s3cli, cleanup, err := s.s3ClientForBucket(ctx, ref, linodeclient.KeyPermissionsReadWrite)
+	if err != nil {
+		return err
+	}
+	defer cleanupWithTimeout(ctx, log, cleanup)
+
+	return s.archiveBucket(ctx, log, s3cli, ref, func(result s3.CopyResult) {
+		task.Archived = result.Objects + result.Skipped
+		update(*task)
+	})
+}
+
+// archiveBucket copies the objects of the bucket into the backup bucket of the reference,
+// below "archive/<label>/<timestamp>/", and verifies the copy. The prefix is recorded in the
+// bucket tags, so that retries resume the same archive instead of starting a new one.
+func (s *Server) archiveBucket(
+	ctx context.Context,
+	log *slog.Logger,
+	s3cli s3.Client,
+	ref bucketRef,
+	progress func(s3.CopyResult),
+) error {
+	target, err := parseArchiveTarget(ref.ArchiveTo)
+	if err != nil {
+		return err
+	}
+
+	if target.Region == ref.Region && target.Label == ref.Label {
+		return fmt.Errorf("%w: bucket cannot be archived into itself", ErrInvalidArchiveTarget)
+	}
+
+	tags, err := s3cli.GetBucketTags(ctx, ref.Region, ref.Label)
+	if s3.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get bucket tags: %w", err)
+	}
+
+	prefix, ok := tags[TagArchivePrefix]
+	if !ok {
+		prefix = path.Join("archive", ref.Label, time.Now().UTC().Format(archiveTimestampFormat)) + "/"
+		tags[TagArchivePrefix] = prefix
+
+		if err := s3cli.SetBucketTags(ctx, ref.Region, ref.Label, tags); err != nil {
+			return fmt.Errorf("failed to record archive prefix: %w", err)
+		}
+	}
+
+	log = log.With(slog.String("archive_to", ref.ArchiveTo), slog.String("archive_prefix", prefix))
+
+	dst, cleanup, err := s.s3ClientForBucket(ctx, target, linodeclient.KeyPermissionsReadWrite)
+	if err != nil {
+		// A missing backup bucket must not be mistaken for a deleted bucket.
+		return fmt.Errorf("%w %s: %v", ErrArchiveUnavailable, ref.ArchiveTo, err) //nolint:errorlint // hides ErrNotFound
+	}
+	defer cleanupWithTimeout(ctx, log, cleanup)
+
+	result, err := s3.Copy(ctx,
+		s3.Location{Client: s3cli, Region: ref.Region, Bucket: ref.Label},
+		s3.Location{Client: dst, Region: target.Region, Bucket: target.Label, Prefix: prefix},
+		s3.WithCopyLogger(log),
+		s3.WithCopyProgress(progress),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to archive bucket: %w", err)
+	}
+
+	log.InfoContext(ctx, "Bucket archived",
+		slog.Int64("objects", result.Objects),
+		slog.Int64("bytes", result.Bytes),
+		slog.Int64("skipped", result.Skipped),
+	)
+
+	return nil
+}
@@ -0,0 +1,310 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec // MD5 is the checksum of S3 ETags
+	"encoding/hex"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/linode/linodego/v2"
+	"github.com/minio/minio-go/v7"
+	"go.uber.org/mock/gomock"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	cosi "sigs.k8s.io/container-object-storage-interface-spec"
+
+	"github.com/linode/linode-cosi-driver/pkg/deletion"
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient/cache"
+	"github.com/linode/linode-cosi-driver/pkg/servers/provisioner"
+	"github.com/linode/linode-cosi-driver/testing/mock"
+)
+
+const (
+	testBackupBucketName   = "backup-bucket"
+	testBucketIDV2Archived = testBucketIDV2Force + "&archive-to=" + testRegion + "%2F" + testBackupBucketName
+)
+
+// fakeObjects records the objects of buckets listed, copied and streamed through the mock S3
+// client. Objects are stored by size, their content is filled with "x". Keys containing
+// "broken/" cannot be copied.
+type fakeObjects struct {
+	mu      sync.Mutex
+	buckets map[string]map[string]int64
+}
+
+func (f *fakeObjects) expect(mockS3 *mock.MockS3Client) {
+	mockS3.EXPECT().
+		ListObjects(gomock.Any(), gomock.Eq(testRegion), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, bucket, prefix string, fn func(minio.ObjectInfo) error) error {
+			f.mu.Lock()
+			objects := maps.Clone(f.buckets[bucket])
+			f.mu.Unlock()
+
+			for _, key := range slices.Sorted(maps.Keys(objects)) {
+				if !strings.HasPrefix(key, prefix) {
+					continue
+				}
+				if err := fn(fakeObjectInfo(key, objects[key])); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}).
+		AnyTimes()
+	mockS3.EXPECT().
+		CopyObject(gomock.Any(), gomock.Eq(testRegion), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, srcBucket, srcKey, dstBucket, dstKey string) error {
+			if strings.Contains(srcKey, "broken/") {
+				return minio.ErrorResponse{StatusCode: http.StatusForbidden, Code: "AccessDenied"}
+			}
+
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			f.buckets[dstBucket][dstKey] = f.buckets[srcBucket][srcKey]
+
+			return nil
+		}).
+		AnyTimes()
+	mockS3.EXPECT().
+		GetObject(gomock.Any(), gomock.Eq(testRegion), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, bucket, key string) (io.ReadCloser, minio.ObjectInfo, error) {
+			f.mu.Lock()
+			size, ok := f.buckets[bucket][key]
+			f.mu.Unlock()
+
+			if !ok {
+				return nil, minio.ObjectInfo{}, minio.ErrorResponse{StatusCode: http.StatusNotFound, Code: "NoSuchKey"}
+			}
+
+			return io.NopCloser(bytes.NewReader(fakeContent(size))), fakeObjectInfo(key, size), nil
+		}).
+		AnyTimes()
+	mockS3.EXPECT().
+		PutObject(gomock.Any(), gomock.Eq(testRegion), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, bucket, key string, body io.Reader, _ minio.ObjectInfo) error {
+			data, err := io.ReadAll(body)
+			if err != nil {
+				return err
+			}
+
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			f.buckets[bucket][key] = int64(len(data))
+
+			return nil
+		}).
+		AnyTimes()
+}
+
+func fakeContent(size int64) []byte {
+	return bytes.Repeat([]byte("x"), int(size))
+}
+
+func fakeObjectInfo(key string, size int64) minio.ObjectInfo {
+	sum := md5.Sum(fakeContent(size)) //nolint:gosec // MD5 is the checksum of S3 ETags
+	return minio.ObjectInfo{Key: key, Size: size, ETag: hex.EncodeToString(sum[:])}
+}
+
+func (f *fakeObjects) keys(bucket string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Sorted(maps.Keys(f.buckets[bucket]))
+}
+
+func TestArchiveBeforePrune(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		testName     string
+		source       map[string]int64
+		backup       map[string]int64
+		tags         map[string]string
+		expectedCode grpccodes.Code
+		expected     []string
+	}{
+		{
+			testName:     "archives objects before pruning",
+			source:       map[string]int64{"a": 1, "dir/b": 2},
+			backup:       map[string]int64{"unrelated": 3},
+			tags:         map[string]string{},
+			expectedCode: grpccodes.OK,
+		},
+		{
+			testName:     "resumes the recorded archive",
+			source:       map[string]int64{"a": 1, "dir/b": 2},
+			backup:       map[string]int64{"archive/test-bucket/20250101T000000Z/a": 1},
+			tags:         map[string]string{provisioner.TagArchivePrefix: "archive/test-bucket/20250101T000000Z/"},
+			expectedCode: grpccodes.OK,
+			expected:     []string{"archive/test-bucket/20250101T000000Z/a", "archive/test-bucket/20250101T000000Z/dir/b"},
+		},
+		{
+			testName:     "keeps buckets failing to be archived",
+			source:       map[string]int64{"a": 1, "broken/b": 2},
+			backup:       map[string]int64{},
+			tags:         map[string]string{},
+			expectedCode: grpccodes.Internal,
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockLinode := mock.NewMockLinodeClient(ctrl)
+			mockLinode.EXPECT().
+				ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+				Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+				AnyTimes()
+
+			objects := &fakeObjects{buckets: map[string]map[string]int64{
+				testBucketName:       maps.Clone(tc.source),
+				testBackupBucketName: maps.Clone(tc.backup),
+			}}
+			metadata := &fakeBucketMetadata{tags: maps.Clone(tc.tags)}
+
+			mockS3 := mock.NewMockS3Client(ctrl)
+			objects.expect(mockS3)
+			metadata.expect(mockS3)
+
+			if tc.expectedCode == grpccodes.OK {
+				mockS3.EXPECT().
+					Prune(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName), gomock.Any()).
+					DoAndReturn(func(context.Context, string, string, func(int64, int64)) error {
+						tags, _ := metadata.get()
+						prefix := tags[provisioner.TagArchivePrefix]
+
+						for key := range tc.source {
+							if !slices.Contains(objects.keys(testBackupBucketName), prefix+key) {
+								t.Errorf("expected %q to be archived below %q before pruning", key, prefix)
+							}
+						}
+
+						return nil
+					})
+				mockLinode.EXPECT().
+					DeleteObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(nil)
+			}
+
+			epc := cache.New(discardLog, mockLinode, 0)
+			if err := epc.Refresh(t.Context()); err != nil {
+				t.Fatalf("failed to refresh cache: %v", err)
+			}
+
+			srv, err := provisioner.New(nil, mockLinode, epc, mockS3, true)
+			if err != nil {
+				t.Fatalf("failed to create provisioner server: %v", err)
+			}
+
+			_, err = srv.DriverDeleteBucket(t.Context(), &cosi.DriverDeleteBucketRequest{BucketId: testBucketIDV2Archived})
+			if code := status.Code(err); code != tc.expectedCode {
+				t.Fatalf("expected status code %q, but got %q: %v", tc.expectedCode, code, err)
+			}
+
+			if tc.expected != nil {
+				if keys := objects.keys(testBackupBucketName); !slices.Equal(keys, tc.expected) {
+					t.Errorf("expected backup keys %v, got %v", tc.expected, keys)
+				}
+			}
+		})
+	}
+}
+
+func TestQueuedArchive(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockLinode := mock.NewMockLinodeClient(ctrl)
+	mockLinode.EXPECT().
+		ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+		AnyTimes()
+	mockLinode.EXPECT().
+		ListObjectStorageKeys(gomock.Any(), gomock.Any()).
+		Return(nil, nil)
+	mockLinode.EXPECT().
+		DeleteObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		Return(nil)
+
+	objects := &fakeObjects{buckets: map[string]map[string]int64{
+		testBucketName:       {"a": 1, "b": 2},
+		testBackupBucketName: {},
+	}}
+	metadata := &fakeBucketMetadata{tags: map[string]string{}}
+
+	mockS3 := mock.NewMockS3Client(ctrl)
+	objects.expect(mockS3)
+	metadata.expect(mockS3)
+	mockS3.EXPECT().
+		Prune(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName), gomock.Any()).
+		Return(nil)
+
+	epc := cache.New(discardLog, mockLinode, 0)
+	if err := epc.Refresh(t.Context()); err != nil {
+		t.Fatalf("failed to refresh cache: %v", err)
+	}
+
+	srv, err := provisioner.New(nil, mockLinode, epc, mockS3, true)
+	if err != nil {
+		t.Fatalf("failed to create provisioner server: %v", err)
+	}
+
+	var (
+		states   []deletion.State
+		archived int64
+	)
+
+	err = srv.ProcessDeletion(t.Context(), deletion.Task{
+		Region:       testRegion,
+		Label:        testBucketName,
+		EndpointType: linodego.ObjectStorageEndpointE0,
+		Cleanup:      true,
+		ArchiveTo:    testRegion + "/" + testBackupBucketName,
+		State:        deletion.StatePending,
+	}, func(task deletion.Task) {
+		if len(states) == 0 || states[len(states)-1] != task.State {
+			states = append(states, task.State)
+		}
+		archived = max(archived, task.Archived)
+	})
+	if err != nil {
+		t.Fatalf("failed to process deletion: %v", err)
+	}
+
+	expected := []deletion.State{deletion.StateArchiving, deletion.StatePruning, deletion.StateDeleting}
+	if !slices.Equal(states, expected) {
+		t.Errorf("expected states %v, got %v", expected, states)
+	}
+
+	if archived != 2 {
+		t.Errorf("expected 2 archived objects, got %d", archived)
+	}
+
+	if keys := objects.keys(testBackupBucketName); len(keys) != 2 {
+		t.Errorf("expected 2 archived objects in the backup bucket, got %v", keys)
+	}
+}
@@ -0,0 +1,307 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/linode/linodego/v2"
+)
+
+const (
+	bucketIDParts       = 3
+	bucketIDV2Prefix    = "v2:"
+	bucketIDS3URIScheme = "s3"
+
+	bucketIDKeyRegion       = "region"
+	bucketIDKeyLabel        = "label"
+	bucketIDKeyEndpointType = "type"
+	bucketIDKeyCleanup      = "cleanup"
+	bucketIDKeySoftDelete   = "soft-delete"
+	bucketIDKeyMaxObjects   = "max-objects"
+	bucketIDKeyMaxBytes     = "max-bytes"
+	bucketIDKeyArchiveTo    = "archive-to"
+	bucketIDKeyAccount      = "account"
+	bucketIDKeySignature    = "sig"
+)
+
+// bucketRef is the decoded form of a bucket ID.
+//
+// Legacy IDs have the form "region/label[/force]". Versioned IDs start with "v2:"
+// followed by URL encoded key/value pairs, which leaves room for new fields.
+// Unknown keys are preserved, so IDs minted by newer drivers can still be parsed.
+// Versioned IDs may be signed with an HMAC over all other key/value pairs.
+//
+// Existing buckets may also be imported using "s3://label?region=us-ord" URIs, bucket
+// hostnames such as "label.us-ord-1.linodeobjects.com", or legacy IDs that use object
+// storage cluster IDs in place of regions, e.g. "us-east-1/label". Hostnames and cluster
+// IDs are resolved against the object storage endpoints, see Server.resolveBucketRef.
+type bucketRef struct {
+	Region       string
+	Label        string
+	EndpointType linodego.ObjectStorageEndpointType
+	Cleanup      bool
+	Account      string
+	// SoftDeletePeriod is the grace period between the deletion request and the deletion
+	// of the bucket. Buckets are deleted immediately when it is zero.
+	SoftDeletePeriod time.Duration
+	// MaxObjects and MaxBytes limit the size of buckets deleted with cleanup. They can only
+	// tighten the limits of the driver, see WithDeletionLimits. Zero sets no limit.
+	MaxObjects int
+	MaxBytes   int
+	// ArchiveTo is the backup bucket, in the form "region/label", receiving a copy of the
+	// objects of buckets deleted with cleanup before they are pruned.
+	ArchiveTo string
+
+	legacy    bool
+	imported  bool
+	hostname  string
+	signature string
+	extra     url.Values
+}
+
+func parseBucketID(id string) (bucketRef, error) {
+	if encoded, ok := strings.CutPrefix(id, bucketIDV2Prefix); ok {
+		return parseBucketIDV2(id, encoded)
+	}
+
+	if strings.HasPrefix(id, bucketIDS3URIScheme+"://") {
+		return parseBucketURI(id)
+	}
+
+	if id != "" && !strings.Contains(id, "/") && strings.Contains(id, ".") {
+		return bucketRef{hostname: id, imported: true}, nil
+	}
+
+	return parseLegacyBucketID(id)
+}
+
+// parseBucketLocation parses a bucket given as "region/label", e.g. in class parameters
+// referring to other buckets.
+func parseBucketLocation(value string) (bucketRef, error) {
+	region, label, ok := strings.Cut(value, "/")
+	if !ok || region == "" || label == "" || strings.Contains(label, "/") {
+		return bucketRef{}, fmt.Errorf("%q, expected region/label", value)
+	}
+
+	return bucketRef{Region: region, Label: label}, nil
+}
+
+func parseBucketURI(id string) (bucketRef, error) {
+	uri, err := url.Parse(id)
+	if err != nil {
+		return bucketRef{}, fmt.Errorf("invalid bucket URI %q: %w", id, err)
+	}
+
+	if uri.Host == "" || (uri.Path != "" && uri.Path != "/") {
+		return bucketRef{}, fmt.Errorf("invalid bucket URI %q: expected s3://label?region=region", id)
+	}
+
+	region := uri.Query().Get(bucketIDKeyRegion)
+	if region == "" {
+		return bucketRef{}, fmt.Errorf("invalid bucket URI %q: region is required", id)
+	}
+
+	return bucketRef{
+		Region:   region,
+		Label:    uri.Host,
+		imported: true,
+	}, nil
+}
+
+func parseLegacyBucketID(id string) (bucketRef, error) {
+	parts := strings.SplitN(id, "/", bucketIDParts)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return bucketRef{}, fmt.Errorf("invalid bucket ID %q", id)
+	}
+
+	ref := bucketRef{
+		Region: parts[0],
+		Label:  parts[1],
+		legacy: true,
+	}
+
+	if len(parts) == bucketIDParts {
+		cleanupValue := ParamCleanupValue(parts[2])
+		if !cleanupValue.Force() {
+			return bucketRef{}, fmt.Errorf("invalid bucket cleanup policy %q", parts[2])
+		}
+		ref.Cleanup = true
+	}
+
+	return ref, nil
+}
+
+func parseBucketIDV2(id, encoded string) (bucketRef, error) {
+	values, err := url.ParseQuery(encoded)
+	if err != nil {
+		return bucketRef{}, fmt.Errorf("invalid bucket ID %q: %w", id, err)
+	}
+
+	ref := bucketRef{
+		Region:       values.Get(bucketIDKeyRegion),
+		Label:        values.Get(bucketIDKeyLabel),
+		EndpointType: linodego.ObjectStorageEndpointType(values.Get(bucketIDKeyEndpointType)),
+		Account:      values.Get(bucketIDKeyAccount),
+		signature:    values.Get(bucketIDKeySignature),
+	}
+	if ref.Region == "" || ref.Label == "" {
+		return bucketRef{}, fmt.Errorf("invalid bucket ID %q: region and label are required", id)
+	}
+
+	if cleanup := values.Get(bucketIDKeyCleanup); cleanup != "" {
+		if !ParamCleanupValue(cleanup).Force() {
+			return bucketRef{}, fmt.Errorf("invalid bucket cleanup policy %q", cleanup)
+		}
+		ref.Cleanup = true
+	}
+
+	if period := values.Get(bucketIDKeySoftDelete); period != "" {
+		ref.SoftDeletePeriod, err = parseSoftDeletePeriod(period)
+		if err != nil {
+			return bucketRef{}, fmt.Errorf("invalid bucket ID %q: %w", id, err)
+		}
+	}
+
+	for key, limit := range map[string]*int{
+		bucketIDKeyMaxObjects: &ref.MaxObjects,
+		bucketIDKeyMaxBytes:   &ref.MaxBytes,
+	} {
+		if value := values.Get(key); value != "" {
+			if *limit, err = parseDeletionLimit(value); err != nil {
+				return bucketRef{}, fmt.Errorf("invalid bucket ID %q: %w", id, err)
+			}
+		}
+	}
+
+	if archiveTo := values.Get(bucketIDKeyArchiveTo); archiveTo != "" {
+		if _, err := parseArchiveTarget(archiveTo); err != nil {
+			return bucketRef{}, fmt.Errorf("invalid bucket ID %q: %w", id, err)
+		}
+		ref.ArchiveTo = archiveTo
+	}
+
+	if ref.EndpointType != "" {
+		if _, err := parseEndpointType(map[string]string{ParamEndpointType: string(ref.EndpointType)}); err != nil {
+			return bucketRef{}, fmt.Errorf("invalid bucket ID %q: %w", id, err)
+		}
+	}
+
+	for _, key := range []string{
+		bucketIDKeyRegion,
+		bucketIDKeyLabel,
+		bucketIDKeyEndpointType,
+		bucketIDKeyCleanup,
+		bucketIDKeySoftDelete,
+		bucketIDKeyMaxObjects,
+		bucketIDKeyMaxBytes,
+		bucketIDKeyArchiveTo,
+		bucketIDKeyAccount,
+		bucketIDKeySignature,
+	} {
+		values.Del(key)
+	}
+	if len(values) > 0 {
+		ref.extra = values
+	}
+
+	return ref, nil
+}
+
+// values returns the key/value pairs encoded in the versioned bucket ID.
+func (r bucketRef) values() url.Values {
+	values := url.Values{}
+	for key, vals := range r.extra {
+		values[key] = append([]string(nil), vals...)
+	}
+
+	values.Set(bucketIDKeyRegion, r.Region)
+	values.Set(bucketIDKeyLabel, r.Label)
+
+	if r.EndpointType != "" {
+		values.Set(bucketIDKeyEndpointType, string(r.EndpointType))
+	}
+	if r.Cleanup {
+		values.Set(bucketIDKeyCleanup, string(ParamCleanupForce))
+	}
+	if r.SoftDeletePeriod > 0 {
+		values.Set(bucketIDKeySoftDelete, r.SoftDeletePeriod.String())
+	}
+	if r.MaxObjects > 0 {
+		values.Set(bucketIDKeyMaxObjects, strconv.Itoa(r.MaxObjects))
+	}
+	if r.MaxBytes > 0 {
+		values.Set(bucketIDKeyMaxBytes, strconv.Itoa(r.MaxBytes))
+	}
+	if r.ArchiveTo != "" {
+		values.Set(bucketIDKeyArchiveTo, r.ArchiveTo)
+	}
+	if r.Account != "" {
+		values.Set(bucketIDKeyAccount, r.Account)
+	}
+
+	return values
+}
+
+// String returns the versioned bucket ID. Keys are sorted, so the result is stable.
+func (r bucketRef) String() string {
+	values := r.values()
+	if r.signature != "" {
+		values.Set(bucketIDKeySignature, r.signature)
+	}
+
+	return bucketIDV2Prefix + values.Encode()
+}
+
+// Signed returns a copy of the reference carrying an HMAC of its contents.
+func (r bucketRef) Signed(key []byte) bucketRef {
+	r.signature = r.mac(key)
+	return r
+}
+
+// Verify reports whether the reference carries a valid HMAC of its contents.
+// Legacy and unsigned IDs never verify.
+func (r bucketRef) Verify(key []byte) bool {
+	if r.legacy || r.signature == "" {
+		return false
+	}
+
+	return hmac.Equal([]byte(r.signature), []byte(r.mac(key)))
+}
+
+// withoutDeletionPolicy returns a copy of the reference with the deletion policy of the
+// driver defaults: no cleanup, soft delete, deletion limits or archive.
+func (r bucketRef) withoutDeletionPolicy() bucketRef {
+	r.Cleanup = false
+	r.SoftDeletePeriod = 0
+	r.MaxObjects, r.MaxBytes = 0, 0
+	r.ArchiveTo = ""
+
+	return r
+}
+
+func (r bucketRef) mac(key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(bucketIDV2Prefix + r.values().Encode()))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
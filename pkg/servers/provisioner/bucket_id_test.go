@@ -0,0 +1,369 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego/v2"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient/cache"
+)
+
+func TestBucketIDCleanup(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		cleanup     ParamCleanupValue
+		wantID      string
+		wantCleanup bool
+	}{
+		{name: "cleanup omitted", wantID: "v2:label=rc-example&region=pl-labkrk-2&type=E1"},
+		{
+			name:        "cleanup forced",
+			cleanup:     ParamCleanupForce,
+			wantID:      "v2:cleanup=force&label=rc-example&region=pl-labkrk-2&type=E1",
+			wantCleanup: true,
+		},
+		{
+			name:    "unknown cleanup value",
+			cleanup: ParamCleanupValue("invalid"),
+			wantID:  "v2:label=rc-example&region=pl-labkrk-2&type=E1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			id := bucketRef{
+				Region:       "pl-labkrk-2",
+				Label:        "rc-example",
+				EndpointType: linodego.ObjectStorageEndpointE1,
+				Cleanup:      tt.cleanup.Force(),
+			}.String()
+			if id != tt.wantID {
+				t.Fatalf("expected bucket ID %q, got %q", tt.wantID, id)
+			}
+			ref, err := parseBucketID(id)
+			if err != nil {
+				t.Fatalf("expected valid bucket ID, got error: %v", err)
+			}
+			if ref.Region != "pl-labkrk-2" || ref.Label != "rc-example" {
+				t.Fatalf("expected bucket location pl-labkrk-2/rc-example, got %s/%s", ref.Region, ref.Label)
+			}
+			if ref.EndpointType != linodego.ObjectStorageEndpointE1 {
+				t.Fatalf("expected endpoint type E1, got %q", ref.EndpointType)
+			}
+			if ref.Cleanup != tt.wantCleanup {
+				t.Fatalf("expected cleanup forced to be %t, got %t", tt.wantCleanup, ref.Cleanup)
+			}
+		})
+	}
+}
+
+func TestBucketIDSoftDelete(t *testing.T) {
+	t.Parallel()
+
+	id := bucketRef{
+		Region:           "pl-labkrk-2",
+		Label:            "rc-example",
+		SoftDeletePeriod: 168 * time.Hour,
+	}.String()
+	if want := "v2:label=rc-example&region=pl-labkrk-2&soft-delete=168h0m0s"; id != want {
+		t.Fatalf("expected bucket ID %q, got %q", want, id)
+	}
+
+	ref, err := parseBucketID(id)
+	if err != nil {
+		t.Fatalf("expected valid bucket ID, got error: %v", err)
+	}
+	if ref.SoftDeletePeriod != 168*time.Hour {
+		t.Fatalf("expected soft delete period of 168h, got %s", ref.SoftDeletePeriod)
+	}
+
+	for _, invalid := range []string{"1w", "-1h", "0s"} {
+		if _, err := parseBucketID("v2:label=rc-example&region=pl-labkrk-2&soft-delete=" + invalid); !errors.Is(err, ErrInvalidSoftDeletePeriod) {
+			t.Errorf("expected soft delete period %q to be rejected, got error: %v", invalid, err)
+		}
+	}
+}
+
+func TestBucketIDDeletionLimits(t *testing.T) {
+	t.Parallel()
+
+	id := bucketRef{
+		Region:     "pl-labkrk-2",
+		Label:      "rc-example",
+		MaxObjects: 1000,
+		MaxBytes:   1 << 40,
+	}.String()
+	if want := "v2:label=rc-example&max-bytes=1099511627776&max-objects=1000&region=pl-labkrk-2"; id != want {
+		t.Fatalf("expected bucket ID %q, got %q", want, id)
+	}
+
+	ref, err := parseBucketID(id)
+	if err != nil {
+		t.Fatalf("expected valid bucket ID, got error: %v", err)
+	}
+	if ref.MaxObjects != 1000 || ref.MaxBytes != 1<<40 {
+		t.Fatalf("expected limits of 1000 objects and 1TiB, got %d objects and %d bytes", ref.MaxObjects, ref.MaxBytes)
+	}
+
+	if _, err := parseBucketID("v2:label=rc-example&region=pl-labkrk-2&max-objects=-1"); !errors.Is(err, ErrInvalidDeletionLimit) {
+		t.Errorf("expected negative limit to be rejected, got error: %v", err)
+	}
+}
+
+func TestBucketIDArchiveTo(t *testing.T) {
+	t.Parallel()
+
+	id := bucketRef{
+		Region:    "pl-labkrk-2",
+		Label:     "rc-example",
+		Cleanup:   true,
+		ArchiveTo: "us-ord/backup",
+	}.String()
+	if want := "v2:archive-to=us-ord%2Fbackup&cleanup=force&label=rc-example&region=pl-labkrk-2"; id != want {
+		t.Fatalf("expected bucket ID %q, got %q", want, id)
+	}
+
+	ref, err := parseBucketID(id)
+	if err != nil {
+		t.Fatalf("expected valid bucket ID, got error: %v", err)
+	}
+	if ref.ArchiveTo != "us-ord/backup" {
+		t.Fatalf("expected archive target us-ord/backup, got %q", ref.ArchiveTo)
+	}
+
+	if _, err := parseBucketID("v2:label=rc-example&region=pl-labkrk-2&archive-to=backup"); !errors.Is(err, ErrInvalidArchiveTarget) {
+		t.Errorf("expected archive target without region to be rejected, got error: %v", err)
+	}
+}
+
+func TestParseLegacyBucketID(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		id          string
+		wantCleanup bool
+	}{
+		{id: "pl-labkrk-2/rc-example"},
+		{id: "pl-labkrk-2/rc-example/force", wantCleanup: true},
+	} {
+		t.Run(tc.id, func(t *testing.T) {
+			t.Parallel()
+
+			ref, err := parseBucketID(tc.id)
+			if err != nil {
+				t.Fatalf("expected legacy bucket ID to be accepted, got error: %v", err)
+			}
+			if ref.Region != "pl-labkrk-2" || ref.Label != "rc-example" {
+				t.Fatalf("expected bucket location pl-labkrk-2/rc-example, got %s/%s", ref.Region, ref.Label)
+			}
+			if ref.Cleanup != tc.wantCleanup {
+				t.Fatalf("expected cleanup forced to be %t, got %t", tc.wantCleanup, ref.Cleanup)
+			}
+			if !ref.legacy || ref.EndpointType != "" {
+				t.Fatalf("expected legacy bucket ID without endpoint type, got %+v", ref)
+			}
+		})
+	}
+}
+
+func TestParseImportedBucketID(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		id   string
+		want bucketRef
+	}{
+		{
+			id:   "s3://rc-example?region=pl-labkrk-2",
+			want: bucketRef{Region: "pl-labkrk-2", Label: "rc-example", imported: true},
+		},
+		{
+			id:   "s3://rc-example/?region=pl-labkrk-2",
+			want: bucketRef{Region: "pl-labkrk-2", Label: "rc-example", imported: true},
+		},
+		{
+			id:   "rc-example.pl-labkrk-2.linodeobjects.com",
+			want: bucketRef{hostname: "rc-example.pl-labkrk-2.linodeobjects.com", imported: true},
+		},
+	} {
+		t.Run(tc.id, func(t *testing.T) {
+			t.Parallel()
+
+			ref, err := parseBucketID(tc.id)
+			if err != nil {
+				t.Fatalf("expected imported bucket ID to be accepted, got error: %v", err)
+			}
+			if !reflect.DeepEqual(ref, tc.want) {
+				t.Fatalf("expected %+v, got %+v", tc.want, ref)
+			}
+		})
+	}
+}
+
+func TestResolveImportedEndpoints(t *testing.T) {
+	t.Parallel()
+
+	e0, e1 := "pl-labkrk-2.linodeobjects.com", "pl-labkrk-2-1.linodeobjects.com"
+	endpoints := []cache.Endpoint{
+		cache.NewEndpoint(linodego.ObjectStorageEndpoint{Region: "pl-labkrk-2", S3Endpoint: &e0, EndpointType: linodego.ObjectStorageEndpointE0}),
+		cache.NewEndpoint(linodego.ObjectStorageEndpoint{Region: "pl-labkrk-2", S3Endpoint: &e1, EndpointType: linodego.ObjectStorageEndpointE1}),
+	}
+
+	endpoint, label, ok := endpointForHostname(endpoints, "rc.example.pl-labkrk-2-1.linodeobjects.com")
+	if !ok || label != "rc.example" || endpoint.EndpointType != linodego.ObjectStorageEndpointE1 {
+		t.Fatalf("expected E1 endpoint for label rc.example, got %+v %q %t", endpoint, label, ok)
+	}
+	if _, _, ok := endpointForHostname(endpoints, "rc-example.example.com"); ok {
+		t.Fatalf("expected unknown hostname to be rejected")
+	}
+
+	endpoint, ok = cache.ClusterEndpoint(endpoints, "pl-labkrk-2-1")
+	if !ok || endpoint.EndpointType != linodego.ObjectStorageEndpointE1 {
+		t.Fatalf("expected E1 endpoint for cluster, got %+v %t", endpoint, ok)
+	}
+	if _, ok := cache.ClusterEndpoint(endpoints, "pl-labkrk-2"); ok {
+		t.Fatalf("expected region not to be treated as cluster ID")
+	}
+}
+
+// catalogCache serves a fixed endpoint catalog and fails the test on key lookups.
+type catalogCache struct {
+	t         *testing.T
+	endpoints []cache.Endpoint
+}
+
+func (c catalogCache) Get(key string) (string, bool) {
+	c.t.Errorf("unexpected lookup of %q", key)
+	return "", false
+}
+
+func (c catalogCache) Endpoints() []cache.Endpoint { return c.endpoints }
+
+func (c catalogCache) Endpoint(string, linodego.ObjectStorageEndpointType) (cache.Endpoint, bool) {
+	return cache.Endpoint{}, false
+}
+
+func TestResolveLegacyBucketRef(t *testing.T) {
+	t.Parallel()
+
+	e0 := "us-east-1.linodeobjects.com"
+	srv := &Server{cache: catalogCache{t: t, endpoints: []cache.Endpoint{
+		cache.NewEndpoint(linodego.ObjectStorageEndpoint{Region: "us-east", S3Endpoint: &e0}),
+	}}}
+
+	// Legacy IDs are matched against the catalog by cluster, without key lookups.
+	ref, err := srv.resolveBucketRef(t.Context(), bucketRef{Region: "us-east", Label: "rc-example", legacy: true})
+	if err != nil || ref.imported || ref.Region != "us-east" {
+		t.Fatalf("expected region to be returned unchanged, got %+v %v", ref, err)
+	}
+}
+
+func TestBucketIDPreservesUnknownKeys(t *testing.T) {
+	t.Parallel()
+
+	const id = "v2:account=acme&future=value&label=rc-example&region=pl-labkrk-2"
+
+	ref, err := parseBucketID(id)
+	if err != nil {
+		t.Fatalf("expected valid bucket ID, got error: %v", err)
+	}
+	if ref.Account != "acme" {
+		t.Fatalf("expected account acme, got %q", ref.Account)
+	}
+	if got := ref.String(); got != id {
+		t.Fatalf("expected bucket ID %q to round trip, got %q", id, got)
+	}
+}
+
+func TestParseBucketIDRejectsMalformedIDs(t *testing.T) {
+	t.Parallel()
+
+	for _, id := range []string{
+		"",
+		"region",
+		"/label",
+		"region/",
+		"region/label/",
+		"region/label/unknown",
+		"region/label/force/extra",
+		"v2:",
+		"v2:region=pl-labkrk-2",
+		"v2:label=rc-example",
+		"v2:label=rc-example&region=pl-labkrk-2&cleanup=unknown",
+		"v2:label=rc-example&region=pl-labkrk-2&type=E9",
+		"v2:label=rc-example&region=%zz",
+		"s3://",
+		"s3://rc-example",
+		"s3://rc-example/key?region=pl-labkrk-2",
+	} {
+		t.Run(id, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := parseBucketID(id); err == nil {
+				t.Fatalf("expected bucket ID %q to be rejected", id)
+			}
+		})
+	}
+}
+
+func TestBucketIDSignature(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	ref := bucketRef{
+		Region:       "pl-labkrk-2",
+		Label:        "rc-example",
+		EndpointType: linodego.ObjectStorageEndpointE1,
+	}.Signed(key)
+
+	parsed, err := parseBucketID(ref.String())
+	if err != nil {
+		t.Fatalf("expected valid bucket ID, got error: %v", err)
+	}
+	if !parsed.Verify(key) {
+		t.Fatalf("expected signed bucket ID %q to verify", ref.String())
+	}
+	if parsed.Verify([]byte("another-key-another-key-another-key")) {
+		t.Fatalf("expected bucket ID %q not to verify with another key", ref.String())
+	}
+
+	tampered := parsed
+	tampered.Cleanup = true
+	if tampered.Verify(key) {
+		t.Fatalf("expected tampered bucket ID %q not to verify", tampered.String())
+	}
+
+	for _, id := range []string{
+		"pl-labkrk-2/rc-example",
+		"v2:label=rc-example&region=pl-labkrk-2&type=E1",
+	} {
+		unsigned, err := parseBucketID(id)
+		if err != nil {
+			t.Fatalf("expected valid bucket ID, got error: %v", err)
+		}
+		if unsigned.Verify(key) {
+			t.Fatalf("expected unsigned bucket ID %q not to verify", id)
+		}
+	}
+}
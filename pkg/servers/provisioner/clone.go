@@ -0,0 +1,122 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/pkg/s3"
+)
+
+// parseCloneSource parses the bucket that new buckets are cloned from. It returns nil when
+// buckets are not cloned.
+func parseCloneSource(params map[string]string) (*bucketRef, error) {
+	value, ok := params[ParamCloneFrom]
+	if !ok {
+		return nil, nil //nolint:nilnil // no clone source
+	}
+
+	ref, err := parseBucketLocation(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCloneSource, err)
+	}
+
+	return &ref, nil
+}
+
+// cloneBucket copies the objects of the source into the bucket of the ID. Interrupted clones
+// resume when the creation is retried. Once the copy is verified, the bucket is tagged with
+// TagClonedFrom, so that objects removed from the clone later are not copied again.
+func (s *Server) cloneBucket(ctx context.Context, log *slog.Logger, id string, source bucketRef) error {
+	ref, err := parseBucketID(id)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	from := source.Region + "/" + source.Label
+	if source.Region == ref.Region && source.Label == ref.Label {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("%v: bucket cannot be cloned from itself", ErrInvalidCloneSource))
+	}
+
+	log = log.With(slog.String("clone_from", from))
+
+	dst, cleanup, err := s.s3ClientForBucket(ctx, ref, linodeclient.KeyPermissionsReadWrite)
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to create bucket-scoped credentials", "error", err)
+		return status.Error(codes.Internal, fmt.Sprintf("failed to create bucket-scoped credentials: %v", err))
+	}
+	defer cleanupWithTimeout(ctx, log, cleanup)
+
+	tags, err := dst.GetBucketTags(ctx, ref.Region, ref.Label)
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to get bucket tags", "error", err)
+		return status.Error(codes.Internal, fmt.Sprintf("failed to get bucket tags: %v", err))
+	}
+
+	if clonedFrom, ok := tags[TagClonedFrom]; ok {
+		if clonedFrom != from {
+			log.WarnContext(ctx, "Bucket was cloned from another bucket", "cloned_from", clonedFrom)
+		}
+
+		return nil
+	}
+
+	src, srcCleanup, err := s.s3ClientForBucket(ctx, source, linodeclient.KeyPermissionsReadOnly)
+	if errors.Is(err, ErrNotFound) {
+		log.ErrorContext(ctx, "Clone source not found", "error", err)
+		return status.Error(codes.FailedPrecondition, fmt.Sprintf("%v %s: bucket not found", ErrCloneSourceUnavailable, from))
+	}
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to create clone source credentials", "error", err)
+		return status.Error(codes.Internal, fmt.Sprintf("%v %s: %v", ErrCloneSourceUnavailable, from, err))
+	}
+	defer cleanupWithTimeout(ctx, log, srcCleanup)
+
+	result, err := s3.Copy(ctx,
+		s3.Location{Client: src, Region: source.Region, Bucket: source.Label},
+		s3.Location{Client: dst, Region: ref.Region, Bucket: ref.Label},
+		s3.WithCopyLogger(log),
+	)
+	if errors.Is(err, s3.ErrCopyInterrupted) {
+		log.InfoContext(ctx, "Bucket clone interrupted", "error", err)
+		return status.Error(codes.Unavailable, fmt.Sprintf("%v: %d objects copied: %v",
+			ErrBucketCloneInProgress, result.Objects+result.Skipped, err))
+	}
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to clone bucket", "error", err)
+		return status.Error(codes.Internal, fmt.Sprintf("failed to clone bucket: %v", err))
+	}
+
+	tags[TagClonedFrom] = from
+	if err := dst.SetBucketTags(ctx, ref.Region, ref.Label, tags); err != nil {
+		log.ErrorContext(ctx, "Failed to set clone marker", "error", err)
+		return status.Error(codes.Internal, fmt.Sprintf("failed to set clone marker: %v", err))
+	}
+
+	log.InfoContext(ctx, "Bucket cloned",
+		slog.Int64("objects", result.Objects),
+		slog.Int64("bytes", result.Bytes),
+		slog.Int64("skipped", result.Skipped),
+	)
+
+	return nil
+}
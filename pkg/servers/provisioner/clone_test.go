@@ -0,0 +1,123 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner_test
+
+import (
+	"slices"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	cosi "sigs.k8s.io/container-object-storage-interface-spec"
+
+	"github.com/linode/linode-cosi-driver/pkg/servers/provisioner"
+	"github.com/linode/linode-cosi-driver/testing/mock"
+)
+
+const testSourceBucketName = "source-bucket"
+
+func TestCloneBucket(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockLinode := mock.NewMockLinodeClient(ctrl)
+	mockLinode.EXPECT().
+		GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		Return(nil, provisioner.ErrNotFound)
+	expectCreateBucket(t, mockLinode, "", nil, defaultLinodegoBucket)
+	mockLinode.EXPECT().
+		GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		Return(defaultLinodegoBucket, nil)
+	mockLinode.EXPECT().
+		GetObjectStorageBucketAccess(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		Return(defaultLinodegoBucketAccess, nil)
+
+	// A fixture copied by an earlier attempt is skipped.
+	objects := &fakeObjects{buckets: map[string]map[string]int64{
+		testSourceBucketName: {"fixtures/a": 1, "fixtures/b": 2},
+		testBucketName:       {"fixtures/a": 1},
+	}}
+	metadata := &fakeBucketMetadata{tags: map[string]string{}}
+
+	mockS3 := mock.NewMockS3Client(ctrl)
+	objects.expect(mockS3)
+	metadata.expect(mockS3)
+
+	srv, err := provisioner.New(nil, mockLinode, nil, mockS3, true)
+	if err != nil {
+		t.Fatalf("failed to create provisioner server: %v", err)
+	}
+
+	req := &cosi.DriverCreateBucketRequest{
+		Name: testBucketName,
+		Parameters: map[string]string{
+			provisioner.ParamRegion:    testRegion,
+			provisioner.ParamCloneFrom: testRegion + "/" + testSourceBucketName,
+		},
+	}
+
+	resp, err := srv.DriverCreateBucket(t.Context(), req)
+	if err != nil {
+		t.Fatalf("failed to create bucket: %v", err)
+	}
+	if resp.GetBucketId() != testBucketIDV2 {
+		t.Errorf("expected bucket ID %q, got %q", testBucketIDV2, resp.GetBucketId())
+	}
+
+	expected := []string{"fixtures/a", "fixtures/b"}
+	if keys := objects.keys(testBucketName); !slices.Equal(keys, expected) {
+		t.Fatalf("expected cloned keys %v, got %v", expected, keys)
+	}
+
+	if tags, _ := metadata.get(); tags[provisioner.TagClonedFrom] != testRegion+"/"+testSourceBucketName {
+		t.Errorf("expected clone marker, got tags %v", tags)
+	}
+
+	// Objects removed from a finished clone are not copied again.
+	objects.mu.Lock()
+	delete(objects.buckets[testBucketName], "fixtures/a")
+	objects.mu.Unlock()
+
+	if _, err := srv.DriverCreateBucket(t.Context(), req); err != nil {
+		t.Fatalf("failed to create existing bucket: %v", err)
+	}
+
+	if keys := objects.keys(testBucketName); !slices.Equal(keys, []string{"fixtures/b"}) {
+		t.Errorf("expected finished clone to be left alone, got keys %v", keys)
+	}
+}
+
+func TestCloneBucketInvalidSource(t *testing.T) {
+	t.Parallel()
+
+	srv, err := provisioner.New(nil, mock.NewMockLinodeClient(gomock.NewController(t)), nil, nil, true)
+	if err != nil {
+		t.Fatalf("failed to create provisioner server: %v", err)
+	}
+
+	for _, source := range []string{"", testSourceBucketName, testRegion + "/a/b"} {
+		_, err := srv.DriverCreateBucket(t.Context(), &cosi.DriverCreateBucketRequest{
+			Name: testBucketName,
+			Parameters: map[string]string{
+				provisioner.ParamRegion:    testRegion,
+				provisioner.ParamCloneFrom: source,
+			},
+		})
+		if code := status.Code(err); code != grpccodes.InvalidArgument {
+			t.Errorf("expected clone source %q to be rejected with %q, got %q: %v", source, grpccodes.InvalidArgument, code, err)
+		}
+	}
+}
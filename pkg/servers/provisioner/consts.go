@@ -24,15 +24,45 @@ import (
 const (
 	prefix                      = "cosi.linode.com/v1/"
 	ParamACL                    = prefix + "acl"
+	ParamArchiveTo              = prefix + "archive-to"
 	ParamCORS                   = prefix + "cors"
 	ParamCleanup                = prefix + "cleanup"
+	ParamCloneFrom              = prefix + "clone-from"
 	ParamEndpointType           = prefix + "endpoint-type"
 	ParamEndpointTypePreference = prefix + "endpoint-type-preference"
+	ParamKeyTTL                 = prefix + "key-ttl"
+	ParamLabelTemplate          = prefix + "label-template"
+	ParamMaxDeleteBytes         = prefix + "max-delete-bytes"
+	ParamMaxDeleteObjects       = prefix + "max-delete-objects"
 	ParamPermissions            = prefix + "permissions"
 	ParamPolicy                 = prefix + "policy"
 	ParamRegion                 = prefix + "region"
+	ParamRegionPreference       = prefix + "region-preference"
+	ParamSoftDeletePeriod       = prefix + "soft-delete-period"
 )
 
+// TagClusterID is the bucket tag holding the ID of the cluster owning the bucket.
+const TagClusterID = "cosi.linode.com/cluster-id"
+
+// TagDeleteAfter is the bucket tag marking soft-deleted buckets. It holds the end of the grace period.
+const TagDeleteAfter = "cosi.linode.com/delete-after"
+
+// TagRestored is the bucket tag marking soft-deleted buckets restored before the end of the
+// grace period. It holds the time of the restore.
+const TagRestored = "cosi.linode.com/restored"
+
+// TagFrozen is the bucket tag marking buckets frozen by FreezeBucket. It holds the time of
+// the freeze.
+const TagFrozen = "cosi.linode.com/frozen"
+
+// TagClonedFrom is the bucket tag marking buckets cloned from another bucket, once all objects
+// were copied. It holds the source bucket in the form "region/label".
+const TagClonedFrom = "cosi.linode.com/cloned-from"
+
+// TagArchivePrefix is the bucket tag holding the prefix of the backup bucket that the objects
+// of the bucket are archived to before pruning.
+const TagArchivePrefix = "cosi.linode.com/archive-prefix"
+
 type ParamCleanupValue string
 
 const ParamCleanupForce ParamCleanupValue = "force"
@@ -70,6 +100,8 @@ const (
 	S3Endpoint              = "endpoint"
 	S3SecretAccessKeyID     = "accessKeyID"
 	S3SecretAccessSecretKey = "accessSecretKey"
+	// S3SecretExpiresAt is the expiry of time-limited keys, in RFC 3339 format.
+	S3SecretExpiresAt = "expiresAt"
 )
 
 var (
@@ -79,19 +111,58 @@ var (
 	ErrUnknownEndpointType = errors.New("unknown endpoint type")
 	ErrUnknownPermsissions = errors.New("unknown permissions")
 	ErrValidationError     = errors.New("required value cannot be empty")
+
+	ErrBucketDeletionInProgress = errors.New("bucket deletion in progress")
+
+	ErrInvalidSoftDeletePeriod = errors.New("invalid soft delete period")
+	ErrSoftDeleteUnavailable   = errors.New("soft delete requires a deletion queue")
+	ErrBucketPendingDeletion   = errors.New("bucket is pending deletion")
+	ErrNoPendingDeletion       = errors.New("bucket is not pending deletion")
+	ErrGracePeriodExpired      = errors.New("grace period of the pending deletion expired")
+
+	ErrInvalidDeletionLimit  = errors.New("invalid deletion limit")
+	ErrDeletionLimitExceeded = errors.New("bucket exceeds the deletion limits")
+
+	ErrInvalidArchiveTarget = errors.New("invalid archive target")
+	ErrArchiveUnavailable   = errors.New("unable to access archive bucket")
+
+	ErrInvalidCloneSource     = errors.New("invalid clone source")
+	ErrCloneSourceUnavailable = errors.New("unable to access clone source bucket")
+	ErrBucketCloneInProgress  = errors.New("bucket clone in progress")
+
+	ErrInvalidMigrationTarget = errors.New("invalid migration target")
+
+	ErrInvalidKeyTTL = errors.New("invalid key TTL")
+
+	ErrBucketFrozen    = errors.New("bucket is frozen")
+	ErrBucketNotFrozen = errors.New("bucket is not frozen")
+
+	ErrUnsignedBucketID         = errors.New("bucket ID is not signed and bucket is not allowlisted")
+	ErrInvalidBucketIDSignature = errors.New("bucket ID signature is invalid")
+
+	ErrUnknownBucketHostname = errors.New("bucket hostname does not match any object storage endpoint")
+
+	ErrBucketOwnedByOtherCluster = errors.New("bucket is owned by another cluster")
+	ErrBucketNotMarked           = errors.New("bucket has no ownership marker")
+
+	ErrInvalidLabelTemplate = errors.New("invalid label template")
+	ErrInvalidLabel         = errors.New("invalid bucket label")
 )
 
 const (
 	KeyBucketID                = "bucket.id"
+	KeyBucketName              = "bucket.name"
 	KeyBucketLabel             = "bucket.label"
 	KeyBucketRegion            = "bucket.region"
 	KeyBucketCreationTimestamp = "bucket.created_at"
 	KeyBucketACL               = "bucket.acl"
 	KeyBucketCORS              = "bucket.cors_enabled"
 	KeyBucketEndpointType      = "bucket.endpoint_type"
+	KeyBucketOwner             = "bucket.owner"
 	KeyBucketAccessIDRaw       = "bucket.access.id_raw"
 	KeyBucketAccessID          = "bucket.access.id"
 	KeyBucketAccessName        = "bucket.access.name"
 	KeyBucketAccessAuth        = "bucket.access.auth"
 	KeyBucketAccessPermissions = "bucket.access.permissions"
+	KeyBucketAccessExpiresAt   = "bucket.access.expires_at"
 )
@@ -0,0 +1,303 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/linode/linodego/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	cosi "sigs.k8s.io/container-object-storage-interface-spec"
+
+	"github.com/linode/linode-cosi-driver/pkg/deletion"
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/pkg/s3"
+)
+
+// WithDeletionQueue deletes buckets with cleanup in the background. Deletions are answered
+// with Unavailable until the queued deletion finished. The queue must be started with
+// ProcessDeletion as its handler. Soft delete requires a deletion queue, without one buckets with
+// a soft delete period are neither created nor deleted.
+func WithDeletionQueue(queue *deletion.Queue) Option {
+	return func(s *Server) {
+		s.deletions = queue
+	}
+}
+
+// deletionParams are the parameters of the bucket class controlling the deletion of its
+// buckets. They are recorded in the bucket ID.
+type deletionParams struct {
+	cleanup          ParamCleanupValue
+	softDeletePeriod time.Duration
+	maxObjects       int
+	maxBytes         int
+	archiveTo        string
+}
+
+func parseDeletionParams(params map[string]string) (deletionParams, error) {
+	p := deletionParams{
+		cleanup: ParamCleanupValue(params[ParamCleanup]),
+	}
+
+	var err error
+
+	if value, ok := params[ParamSoftDeletePeriod]; ok {
+		if p.softDeletePeriod, err = parseSoftDeletePeriod(value); err != nil {
+			return deletionParams{}, err
+		}
+
+		// Without cleanup, the deletion of a bucket holding objects after the grace period would never succeed.
+		if !p.cleanup.Force() {
+			return deletionParams{}, fmt.Errorf("%w: %s requires %s=%s",
+				ErrInvalidSoftDeletePeriod, ParamSoftDeletePeriod, ParamCleanup, ParamCleanupForce)
+		}
+	}
+
+	if value, ok := params[ParamMaxDeleteObjects]; ok {
+		if p.maxObjects, err = parseDeletionLimit(value); err != nil {
+			return deletionParams{}, fmt.Errorf("%s: %w", ParamMaxDeleteObjects, err)
+		}
+	}
+
+	if value, ok := params[ParamMaxDeleteBytes]; ok {
+		if p.maxBytes, err = parseDeletionLimit(value); err != nil {
+			return deletionParams{}, fmt.Errorf("%s: %w", ParamMaxDeleteBytes, err)
+		}
+	}
+
+	if value, ok := params[ParamArchiveTo]; ok {
+		if _, err := parseArchiveTarget(value); err != nil {
+			return deletionParams{}, fmt.Errorf("%s: %w", ParamArchiveTo, err)
+		}
+
+		if !p.cleanup.Force() {
+			return deletionParams{}, fmt.Errorf("%w: %s requires %s=%s",
+				ErrInvalidArchiveTarget, ParamArchiveTo, ParamCleanup, ParamCleanupForce)
+		}
+
+		p.archiveTo = value
+	}
+
+	return p, nil
+}
+
+func (s *Server) queuedDeletion(region, label string) (deletion.Task, bool) {
+	if s.deletions == nil {
+		return deletion.Task{}, false
+	}
+
+	return s.deletions.Get(region, label)
+}
+
+// queueDeletion revokes access to the bucket and queues its deletion. Buckets deleted by
+// an earlier queued deletion are reported as deleted.
+func (s *Server) queueDeletion(ctx context.Context, log *slog.Logger, ref bucketRef) (*cosi.DriverDeleteBucketResponse, error) {
+	_, err := s.client.GetObjectStorageBucket(ctx, ref.Region, ref.Label)
+	if errors.Is(err, ErrNotFound) {
+		log.InfoContext(ctx, "Bucket already deleted")
+		return &cosi.DriverDeleteBucketResponse{}, status.Error(codes.OK, "bucket deleted")
+	}
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to get bucket", "error", err)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to get bucket: %v", err))
+	}
+
+	if err := s.revokeBucketAccess(ctx, log, ref.Region, ref.Label); err != nil {
+		log.ErrorContext(ctx, "Failed to revoke bucket access", "error", err)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to revoke bucket access: %v", err))
+	}
+
+	task, _, err := s.deletions.Enqueue(deletion.Task{
+		Region:       ref.Region,
+		Label:        ref.Label,
+		EndpointType: ref.EndpointType,
+		Cleanup:      ref.Cleanup,
+		ArchiveTo:    ref.ArchiveTo,
+	})
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to queue bucket deletion", "error", err)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to queue bucket deletion: %v", err))
+	}
+
+	log.InfoContext(ctx, "Bucket deletion queued")
+
+	return nil, deletionInProgress(task)
+}
+
+func deletionInProgress(task deletion.Task) error {
+	if inGracePeriod(task) {
+		return status.Error(codes.Unavailable, fmt.Sprintf("%v: pending until %s",
+			ErrBucketDeletionInProgress, task.DeleteAfter.Format(time.RFC3339)))
+	}
+
+	msg := fmt.Sprintf("%v: %s, %d objects removed", ErrBucketDeletionInProgress, task.State, task.Objects)
+	if task.State == deletion.StateArchiving {
+		msg = fmt.Sprintf("%v: %s, %d objects archived", ErrBucketDeletionInProgress, task.State, task.Archived)
+	}
+	if task.LastError != "" {
+		msg += fmt.Sprintf(", attempt %d failed: %s", task.Attempts, task.LastError)
+	}
+
+	return status.Error(codes.Unavailable, msg)
+}
+
+// ProcessDeletion revokes access to the bucket of the task, prunes and deletes it.
+// It is the handler of the deletion queue, and resumes tasks at their recorded state.
+func (s *Server) ProcessDeletion(ctx context.Context, task deletion.Task, update func(deletion.Task)) error {
+	log := s.logAttr(
+		slog.String(KeyBucketRegion, task.Region),
+		slog.String(KeyBucketLabel, task.Label),
+	).WithGroup("ProcessDeletion")
+
+	if task.State == deletion.StatePending && !task.DeleteAfter.IsZero() {
+		restored, err := s.endGracePeriod(ctx, log, task)
+		if err != nil {
+			return err
+		}
+
+		if restored {
+			log.InfoContext(ctx, "Restored bucket retained")
+			return nil
+		}
+	}
+
+	if task.State == deletion.StatePending {
+		// Keys granted before the deletion was queued are revoked again.
+		if err := s.revokeBucketAccess(ctx, log, task.Region, task.Label); err != nil {
+			return fmt.Errorf("failed to revoke bucket access: %w", err)
+		}
+
+		task.State = deletion.StatePruning
+		if task.Cleanup && task.ArchiveTo != "" {
+			task.State = deletion.StateArchiving
+		}
+		update(task)
+	}
+
+	if task.State == deletion.StateArchiving {
+		err := s.archiveQueued(ctx, log, &task, update)
+		if errors.Is(err, ErrNotFound) {
+			log.InfoContext(ctx, "Bucket already deleted")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		task.State = deletion.StatePruning
+		update(task)
+	}
+
+	if task.State == deletion.StatePruning && task.Cleanup {
+		err := s.pruneQueued(ctx, log, &task, update)
+		if errors.Is(err, ErrNotFound) {
+			log.InfoContext(ctx, "Bucket already deleted")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	task.State = deletion.StateDeleting
+	update(task)
+
+	if err := s.client.DeleteObjectStorageBucket(ctx, task.Region, task.Label); err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to delete bucket: %w", err)
+	}
+
+	if err := s.keys.Expire(ctx, task.Region, task.Label); err != nil {
+		log.ErrorContext(ctx, "Failed to revoke bucket-scoped credentials", "error", err)
+	}
+
+	log.InfoContext(ctx, "Bucket deleted", slog.Int64("objects", task.Objects))
+
+	return nil
+}
+
+// pruneQueued prunes the bucket of the task, recording the removed objects on top of
+// the objects removed by earlier attempts.
+func (s *Server) pruneQueued(ctx context.Context, log *slog.Logger, task *deletion.Task, update func(deletion.Task)) error {
+	ref := bucketRef{Region: task.Region, Label: task.Label, EndpointType: task.EndpointType, Cleanup: task.Cleanup}
+
+	s3cli, cleanup, err := s.s3ClientForBucket(ctx, ref, linodeclient.KeyPermissionsReadWrite)
+	if err != nil {
+		return err
+	}
+	defer cleanupWithTimeout(ctx, log, cleanup)
+
+	objects, bytes := task.Objects, task.Bytes
+
+	err = s3cli.Prune(ctx, task.Region, task.Label, func(removed, removedBytes int64) {
+		task.Objects, task.Bytes = objects+removed, bytes+removedBytes
+		update(*task)
+	})
+	if err != nil && !s3.IsNotFound(err) {
+		return fmt.Errorf("failed to cleanup bucket: %w", err)
+	}
+
+	return nil
+}
+
+// revokeBucketAccess deletes the keys limited to the bucket, e.g. keys granted by DriverGrantBucketAccess.
+// Ephemeral keys of the driver are revoked through the key pool.
+func (s *Server) revokeBucketAccess(ctx context.Context, log *slog.Logger, region, label string) error {
+	if err := s.keys.Expire(ctx, region, label); err != nil {
+		log.ErrorContext(ctx, "Failed to revoke bucket-scoped credentials", "error", err)
+	}
+
+	keys, err := s.client.ListObjectStorageKeys(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to list object storage keys: %w", err)
+	}
+
+	var errs error
+
+	for _, key := range keys {
+		if strings.HasPrefix(key.Label, linodeclient.EphemeralKeyLabelPrefix) || !limitedToBucket(key, region, label) {
+			continue
+		}
+
+		if err := s.client.DeleteObjectStorageKey(ctx, key.ID); err != nil && !errors.Is(err, ErrNotFound) {
+			errs = errors.Join(errs, fmt.Errorf("failed to delete key %d: %w", key.ID, err))
+			continue
+		}
+
+		log.InfoContext(ctx, "Bucket access revoked", slog.Int(KeyBucketAccessID, key.ID))
+	}
+
+	return errs
+}
+
+// limitedToBucket reports whether the key only grants access to the bucket.
+func limitedToBucket(key linodego.ObjectStorageKey, region, label string) bool {
+	if !key.Limited || key.BucketAccess == nil || len(*key.BucketAccess) == 0 {
+		return false
+	}
+
+	for _, access := range *key.BucketAccess {
+		if access.Region != region || access.BucketName != label {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,163 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego/v2"
+	"go.uber.org/mock/gomock"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	cosi "sigs.k8s.io/container-object-storage-interface-spec"
+
+	"github.com/linode/linode-cosi-driver/pkg/deletion"
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient/cache"
+	"github.com/linode/linode-cosi-driver/pkg/servers/provisioner"
+	"github.com/linode/linode-cosi-driver/testing/mock"
+)
+
+func bucketKey(id int, label string, bucket string) linodego.ObjectStorageKey {
+	return linodego.ObjectStorageKey{
+		ID:      id,
+		Label:   label,
+		Limited: true,
+		BucketAccess: &[]linodego.ObjectStorageKeyBucketAccess{{
+			Region:      testRegion,
+			BucketName:  bucket,
+			Permissions: linodeclient.KeyPermissionsReadWrite,
+		}},
+	}
+}
+
+func TestQueuedDeletion(t *testing.T) {
+	t.Parallel()
+
+	const grantedKeyID = 10
+
+	var deleted atomic.Bool
+
+	ctrl := gomock.NewController(t)
+	mockLinode := mock.NewMockLinodeClient(ctrl)
+	mockLinode.EXPECT().
+		ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+		AnyTimes()
+	mockLinode.EXPECT().
+		GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		DoAndReturn(func(context.Context, string, string) (*linodego.ObjectStorageBucket, error) {
+			if deleted.Load() {
+				return nil, provisioner.ErrNotFound
+			}
+			return defaultLinodegoBucket, nil
+		}).
+		AnyTimes()
+	mockLinode.EXPECT().
+		ListObjectStorageKeys(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageKey{
+			bucketKey(grantedKeyID, testBucketAccessName, testBucketName),
+			bucketKey(11, testBucketAccessName, "other-bucket"),
+			bucketKey(12, linodeclient.EphemeralKeyLabelPrefix+"pooled", testBucketName),
+		}, nil).
+		Times(2)
+	// Revoked when the deletion is queued, and again before it is processed.
+	mockLinode.EXPECT().
+		DeleteObjectStorageKey(gomock.Any(), gomock.Eq(grantedKeyID)).
+		Return(nil).
+		Times(2)
+	mockLinode.EXPECT().
+		DeleteObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		DoAndReturn(func(context.Context, string, string) error {
+			deleted.Store(true)
+			return nil
+		})
+
+	mockS3 := mock.NewMockS3Client(ctrl)
+	mockS3.EXPECT().
+		Prune(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, progress func(objects, bytes int64)) error {
+			progress(7, 700)
+			return nil
+		})
+
+	epc := cache.New(discardLog, mockLinode, 0)
+	if err := epc.Refresh(t.Context()); err != nil {
+		t.Fatalf("failed to refresh cache: %v", err)
+	}
+
+	store := &deletion.MemoryStore{}
+	queue := deletion.New(discardLog, store)
+
+	srv, err := provisioner.New(nil, mockLinode, epc, mockS3, true, provisioner.WithDeletionQueue(queue))
+	if err != nil {
+		t.Fatalf("failed to create provisioner server: %v", err)
+	}
+
+	deleteRequest := &cosi.DriverDeleteBucketRequest{BucketId: testBucketID + "/force"}
+
+	// The deletion is queued, and answered with Unavailable until it finished.
+	for i := range 2 {
+		if _, err := srv.DriverDeleteBucket(t.Context(), deleteRequest); status.Code(err) != grpccodes.Unavailable {
+			t.Fatalf("call %d: expected status code %q, but got %q: %v", i, grpccodes.Unavailable, status.Code(err), err)
+		}
+	}
+
+	_, err = srv.DriverGrantBucketAccess(t.Context(), &cosi.DriverGrantBucketAccessRequest{
+		BucketId:           testBucketID,
+		Name:               testBucketAccessName,
+		AuthenticationType: cosi.AuthenticationType_Key,
+		Parameters:         defaultBucketAccessParameters,
+	})
+	if status.Code(err) != grpccodes.FailedPrecondition {
+		t.Errorf("expected access to a bucket being deleted to be refused, got %q: %v", status.Code(err), err)
+	}
+
+	var objects atomic.Int64
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	go queue.Start(ctx, func(ctx context.Context, task deletion.Task, update func(deletion.Task)) error { //nolint:errcheck // canceled at the end of the test
+		return srv.ProcessDeletion(ctx, task, func(task deletion.Task) {
+			objects.Store(task.Objects)
+			update(task)
+		})
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := queue.Get(testRegion, testBucketName); !ok {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("queued deletion did not finish")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if objects.Load() != 7 {
+		t.Errorf("expected 7 removed objects to be recorded, got %d", objects.Load())
+	}
+
+	if _, err := srv.DriverDeleteBucket(t.Context(), deleteRequest); status.Code(err) != grpccodes.OK {
+		t.Errorf("expected finished deletion to succeed, got %q: %v", status.Code(err), err)
+	}
+}
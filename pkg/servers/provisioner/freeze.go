@@ -0,0 +1,259 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/linode/linodego/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/pkg/s3"
+)
+
+// FreezeReport describes a frozen bucket and the keys removed by FreezeBucket.
+type FreezeReport struct {
+	Region   string    `json:"region"`
+	Label    string    `json:"label"`
+	FrozenAt time.Time `json:"frozenAt"`
+	// DeletedKeys are the limited keys with access to the bucket, which were deleted.
+	DeletedKeys []FrozenKey `json:"deletedKeys"`
+	// UnlimitedKeys have access to every bucket of the account. They are kept, and denied
+	// access to the bucket by its policy.
+	UnlimitedKeys []FrozenKey `json:"unlimitedKeys,omitempty"`
+}
+
+// FrozenKey is an object storage key found by FreezeBucket.
+type FrozenKey struct {
+	ID        int    `json:"id"`
+	Label     string `json:"label"`
+	AccessKey string `json:"accessKey"`
+}
+
+// FreezeBucket cuts off all access to a bucket, identified by its bucket ID or an import
+// reference. A statement denying all access is added to the bucket policy, the bucket is marked
+// as frozen, and every limited key with access to the bucket is deleted, including keys with
+// access to other buckets. The other statements of the policy are kept, so that UnfreezeBucket
+// can restore it. Access to frozen buckets is not granted. Freezing a frozen bucket deletes the
+// keys with access to it again.
+func (s *Server) FreezeBucket(ctx context.Context, id string) (FreezeReport, error) {
+	ref, log, s3cli, cleanup, err := s.bucketForCommand(ctx, id, "FreezeBucket")
+	if err != nil {
+		return FreezeReport{}, err
+	}
+	defer cleanupWithTimeout(ctx, log, cleanup)
+
+	if err := updateBucketPolicy(ctx, s3cli, ref.Region, ref.Label, func(policy string) (string, error) {
+		return s3.DenyAll(policy, ref.Label)
+	}); err != nil {
+		return FreezeReport{}, fmt.Errorf("failed to deny bucket access: %w", err)
+	}
+
+	tags, err := s3cli.GetBucketTags(ctx, ref.Region, ref.Label)
+	if err != nil {
+		return FreezeReport{}, fmt.Errorf("failed to get bucket tags: %w", err)
+	}
+
+	frozenAt, frozen, err := frozenSince(tags)
+	if err != nil {
+		return FreezeReport{}, err
+	}
+
+	if !frozen {
+		frozenAt = time.Now().UTC().Truncate(time.Second)
+		tags[TagFrozen] = frozenAt.Format(time.RFC3339)
+
+		if err := s3cli.SetBucketTags(ctx, ref.Region, ref.Label, tags); err != nil {
+			return FreezeReport{}, fmt.Errorf("failed to set frozen marker: %w", err)
+		}
+	}
+
+	report := FreezeReport{Region: ref.Region, Label: ref.Label, FrozenAt: frozenAt, DeletedKeys: []FrozenKey{}}
+
+	if err := s.keys.Expire(ctx, ref.Region, ref.Label); err != nil {
+		log.ErrorContext(ctx, "Failed to revoke bucket-scoped credentials", "error", err)
+	}
+
+	keys, err := s.client.ListObjectStorageKeys(ctx, nil)
+	if err != nil {
+		return report, fmt.Errorf("failed to list object storage keys: %w", err)
+	}
+
+	var errs error
+
+	for _, key := range keys {
+		frozenKey := FrozenKey{ID: key.ID, Label: key.Label, AccessKey: key.AccessKey}
+
+		if !key.Limited {
+			report.UnlimitedKeys = append(report.UnlimitedKeys, frozenKey)
+			continue
+		}
+
+		if !hasBucketAccess(key, ref.Region, ref.Label) {
+			continue
+		}
+
+		if err := s.client.DeleteObjectStorageKey(ctx, key.ID); err != nil && !errors.Is(err, ErrNotFound) {
+			errs = errors.Join(errs, fmt.Errorf("failed to delete key %d: %w", key.ID, err))
+			continue
+		}
+
+		report.DeletedKeys = append(report.DeletedKeys, frozenKey)
+		log.InfoContext(ctx, "Bucket access revoked", slog.Int(KeyBucketAccessID, key.ID), slog.String("key_label", key.Label))
+	}
+
+	log.InfoContext(ctx, "Bucket frozen",
+		slog.Time("frozen_at", frozenAt),
+		slog.Int("deleted_keys", len(report.DeletedKeys)),
+		slog.Int("unlimited_keys", len(report.UnlimitedKeys)),
+	)
+
+	return report, errs
+}
+
+// UnfreezeBucket lifts the freeze of a bucket, identified by its bucket ID or an import
+// reference. The policy denying all access is removed, unless the bucket is pending deletion,
+// and access can be granted again. Keys deleted by the freeze are not restored.
+func (s *Server) UnfreezeBucket(ctx context.Context, id string) error {
+	ref, log, s3cli, cleanup, err := s.bucketForCommand(ctx, id, "UnfreezeBucket")
+	if err != nil {
+		return err
+	}
+	defer cleanupWithTimeout(ctx, log, cleanup)
+
+	tags, err := s3cli.GetBucketTags(ctx, ref.Region, ref.Label)
+	if err != nil {
+		return fmt.Errorf("failed to get bucket tags: %w", err)
+	}
+
+	frozenAt, frozen, err := frozenSince(tags)
+	if err != nil {
+		return err
+	}
+	if !frozen {
+		return ErrBucketNotFrozen
+	}
+
+	// Buckets pending deletion stay denied until they are restored.
+	if _, pending, _ := pendingDeletion(tags); !pending {
+		if err := updateBucketPolicy(ctx, s3cli, ref.Region, ref.Label, s3.AllowAll); err != nil {
+			return fmt.Errorf("failed to lift bucket access denial: %w", err)
+		}
+	}
+
+	delete(tags, TagFrozen)
+	if err := s3cli.SetBucketTags(ctx, ref.Region, ref.Label, tags); err != nil {
+		return fmt.Errorf("failed to clear frozen marker: %w", err)
+	}
+
+	log.InfoContext(ctx, "Bucket unfrozen", slog.Time("frozen_at", frozenAt))
+
+	return nil
+}
+
+// bucketForCommand resolves the bucket of subcommands managing a single bucket, and returns
+// a client for it, after checking that the bucket is not owned by another cluster.
+func (s *Server) bucketForCommand(
+	ctx context.Context,
+	id, group string,
+) (bucketRef, *slog.Logger, s3.Client, func(context.Context) error, error) {
+	ref, err := s.parseBucketID(id)
+	if err != nil {
+		return bucketRef{}, nil, nil, nil, err
+	}
+
+	ref, err = s.resolveBucketRef(ctx, ref)
+	if err != nil {
+		return bucketRef{}, nil, nil, nil, err
+	}
+
+	log := s.logAttr(
+		slog.String(KeyBucketRegion, ref.Region),
+		slog.String(KeyBucketLabel, ref.Label),
+	).WithGroup(group)
+
+	s3cli, cleanup, err := s.s3ClientForBucket(ctx, ref, linodeclient.KeyPermissionsReadWrite)
+	if err != nil {
+		return bucketRef{}, nil, nil, nil, fmt.Errorf("failed to create bucket-scoped credentials: %w", err)
+	}
+
+	if err := s.checkOwnership(ctx, log, s3cli, ref.Region, ref.Label, ownershipCheck); err != nil {
+		cleanupWithTimeout(ctx, log, cleanup)
+		return bucketRef{}, nil, nil, nil, err
+	}
+
+	return ref, log, s3cli, cleanup, nil
+}
+
+// frozenSince returns the time the bucket was frozen, and whether it is frozen.
+func frozenSince(tags map[string]string) (time.Time, bool, error) {
+	value, ok := tags[TagFrozen]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	frozenAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, true, fmt.Errorf("invalid %s tag %q: %w", TagFrozen, value, err)
+	}
+
+	return frozenAt, true, nil
+}
+
+// checkFrozen refuses access to frozen buckets. Buckets that no longer exist pass the check.
+// Reading the tags only needs read access.
+func (s *Server) checkFrozen(ctx context.Context, log *slog.Logger, ref bucketRef) error {
+	s3cli, cleanup, err := s.s3ClientForBucket(ctx, ref, linodeclient.KeyPermissionsReadOnly)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return status.Error(codes.Internal, fmt.Sprintf("failed to create bucket-scoped credentials: %v", err))
+	}
+	defer cleanupWithTimeout(ctx, log, cleanup)
+
+	tags, err := s3cli.GetBucketTags(ctx, ref.Region, ref.Label)
+	if s3.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return status.Error(codes.Internal, fmt.Sprintf("failed to get bucket tags: %v", err))
+	}
+
+	// Buckets with a malformed marker are treated as frozen.
+	if frozenAt, frozen, _ := frozenSince(tags); frozen {
+		return status.Error(codes.FailedPrecondition,
+			fmt.Sprintf("%v since %s, unfreeze it first", ErrBucketFrozen, frozenAt.Format(time.RFC3339)))
+	}
+
+	return nil
+}
+
+// hasBucketAccess reports whether the limited key grants access to the bucket.
+func hasBucketAccess(key linodego.ObjectStorageKey, region, label string) bool {
+	if key.BucketAccess == nil {
+		return false
+	}
+
+	return slices.ContainsFunc(*key.BucketAccess, func(access linodego.ObjectStorageKeyBucketAccess) bool {
+		return access.Region == region && access.BucketName == label
+	})
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/linode/linodego/v2"
+	"go.uber.org/mock/gomock"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	cosi "sigs.k8s.io/container-object-storage-interface-spec"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient/cache"
+	"github.com/linode/linode-cosi-driver/pkg/s3"
+	"github.com/linode/linode-cosi-driver/pkg/servers/provisioner"
+	"github.com/linode/linode-cosi-driver/testing/mock"
+)
+
+// expectNotFrozen lets grants read the tags of buckets, none of which are frozen.
+func expectNotFrozen(mockS3 *mock.MockS3Client) {
+	mockS3.EXPECT().
+		GetBucketTags(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(map[string]string{}, nil).
+		AnyTimes()
+}
+
+func bucketAccess(buckets ...string) *[]linodego.ObjectStorageKeyBucketAccess {
+	access := make([]linodego.ObjectStorageKeyBucketAccess, 0, len(buckets))
+	for _, bucket := range buckets {
+		access = append(access, linodego.ObjectStorageKeyBucketAccess{
+			Region:      testRegion,
+			BucketName:  bucket,
+			Permissions: string(provisioner.ParamPermissionsValueReadWrite),
+		})
+	}
+
+	return &access
+}
+
+func TestFreezeBucket(t *testing.T) {
+	t.Parallel()
+
+	const policy = `{"Statement":[{"Action":"s3:GetObject","Effect":"Allow","Principal":"*","Resource":"arn:aws:s3:::test-bucket/*"}],"Version":"2012-10-17"}`
+
+	ctrl := gomock.NewController(t)
+	mockLinode := mock.NewMockLinodeClient(ctrl)
+	mockLinode.EXPECT().
+		ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+		AnyTimes()
+	mockLinode.EXPECT().
+		ListObjectStorageKeys(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageKey{
+			{ID: 1, Label: "grant", Limited: true, BucketAccess: bucketAccess(testBucketName)},
+			{ID: 2, Label: "shared", Limited: true, BucketAccess: bucketAccess("other-bucket", testBucketName)},
+			{ID: 3, Label: "other", Limited: true, BucketAccess: bucketAccess("other-bucket")},
+			{ID: 4, Label: "admin", Limited: false},
+		}, nil)
+	mockLinode.EXPECT().DeleteObjectStorageKey(gomock.Any(), gomock.Eq(1)).Return(nil)
+	mockLinode.EXPECT().DeleteObjectStorageKey(gomock.Any(), gomock.Eq(2)).Return(nil)
+
+	metadata := &fakeBucketMetadata{tags: map[string]string{}, policy: policy}
+	mockS3 := mock.NewMockS3Client(ctrl)
+	metadata.expect(mockS3)
+
+	epc := cache.New(discardLog, mockLinode, 0)
+	if err := epc.Refresh(t.Context()); err != nil {
+		t.Fatalf("failed to refresh cache: %v", err)
+	}
+
+	srv, err := provisioner.New(nil, mockLinode, epc, mockS3, true)
+	if err != nil {
+		t.Fatalf("failed to create provisioner server: %v", err)
+	}
+
+	report, err := srv.FreezeBucket(t.Context(), testBucketIDV2)
+	if err != nil {
+		t.Fatalf("failed to freeze bucket: %v", err)
+	}
+
+	ids := func(keys []provisioner.FrozenKey) []int {
+		ids := make([]int, 0, len(keys))
+		for _, key := range keys {
+			ids = append(ids, key.ID)
+		}
+		return ids
+	}
+
+	if deleted := ids(report.DeletedKeys); !slices.Equal(deleted, []int{1, 2}) {
+		t.Errorf("expected keys [1 2] to be deleted, got %v", deleted)
+	}
+	if unlimited := ids(report.UnlimitedKeys); !slices.Equal(unlimited, []int{4}) {
+		t.Errorf("expected unlimited key [4] to be reported, got %v", unlimited)
+	}
+
+	tags, frozenPolicy := metadata.get()
+	if _, ok := tags[provisioner.TagFrozen]; !ok {
+		t.Errorf("expected frozen marker, got tags %v", tags)
+	}
+	if !strings.Contains(frozenPolicy, s3.DenyAllStatementID) {
+		t.Errorf("expected policy to deny all access, got %s", frozenPolicy)
+	}
+
+	_, err = srv.DriverGrantBucketAccess(t.Context(), &cosi.DriverGrantBucketAccessRequest{
+		BucketId:           testBucketIDV2,
+		Name:               testBucketAccessName,
+		AuthenticationType: cosi.AuthenticationType_Key,
+	})
+	if code := status.Code(err); code != grpccodes.FailedPrecondition {
+		t.Errorf("expected grant for frozen bucket to fail with %q, got %q: %v", grpccodes.FailedPrecondition, code, err)
+	}
+
+	if err := srv.UnfreezeBucket(t.Context(), testBucketIDV2); err != nil {
+		t.Fatalf("failed to unfreeze bucket: %v", err)
+	}
+
+	tags, restoredPolicy := metadata.get()
+	if _, ok := tags[provisioner.TagFrozen]; ok {
+		t.Errorf("expected frozen marker to be removed, got tags %v", tags)
+	}
+	if restoredPolicy != policy {
+		t.Errorf("expected policy %s to be restored, got %s", policy, restoredPolicy)
+	}
+
+	if err := srv.UnfreezeBucket(t.Context(), testBucketIDV2); !errors.Is(err, provisioner.ErrBucketNotFrozen) {
+		t.Errorf("expected error %v, got %v", provisioner.ErrBucketNotFrozen, err)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/linode/linode-cosi-driver/pkg/metrics"
+)
+
+// Deletion limits reported in refusal metrics.
+const (
+	limitObjects = "objects"
+	limitBytes   = "bytes"
+)
+
+// WithDeletionLimits refuses to delete buckets with cleanup holding more objects or bytes
+// than the limits, unless the bucket is listed in WithDeletionLimitOverrides. Zero disables
+// a limit. Limits set by the bucket class can only tighten the limits of the driver.
+func WithDeletionLimits(maxObjects, maxBytes int) Option {
+	return func(s *Server) {
+		s.maxDeleteObjects = maxObjects
+		s.maxDeleteBytes = maxBytes
+	}
+}
+
+// WithDeletionLimitOverrides deletes the listed buckets regardless of the deletion limits.
+// Entries have the "region/label" form. The overrides are set by the operator of the driver
+// in place of a marker on the bucket: bucket tags can be set by anyone with write access to
+// the bucket, i.e. by the users the limits protect against.
+func WithDeletionLimitOverrides(buckets ...string) Option {
+	return func(s *Server) {
+		if s.limitOverrides == nil {
+			s.limitOverrides = make(map[string]struct{}, len(buckets))
+		}
+		for _, bucket := range buckets {
+			s.limitOverrides[bucket] = struct{}{}
+		}
+	}
+}
+
+// parseDeletionLimit parses a limit of the objects or bytes of deleted buckets.
+func parseDeletionLimit(value string) (int, error) {
+	limit, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidDeletionLimit, err)
+	}
+
+	if limit < 0 {
+		return 0, fmt.Errorf("%w: %q must not be negative", ErrInvalidDeletionLimit, value)
+	}
+
+	return limit, nil
+}
+
+// deletionLimit returns the smaller of the limits set by the bucket class and the driver.
+// A class cannot lift the limit of the driver, so a zero class limit leaves it.
+func deletionLimit(class, driver int) int {
+	if class == 0 || driver == 0 {
+		return max(class, driver)
+	}
+
+	return min(class, driver)
+}
+
+// checkDeletionLimits refuses the deletion of buckets exceeding the deletion limits, based on
+// the object count and size reported by the Linode API. Buckets listed in the overrides pass
+// the check.
+func (s *Server) checkDeletionLimits(ctx context.Context, log *slog.Logger, ref bucketRef) error {
+	maxObjects := deletionLimit(ref.MaxObjects, s.maxDeleteObjects)
+	maxBytes := deletionLimit(ref.MaxBytes, s.maxDeleteBytes)
+
+	if maxObjects == 0 && maxBytes == 0 {
+		return nil
+	}
+
+	bucket, err := s.client.GetObjectStorageBucket(ctx, ref.Region, ref.Label)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to get bucket", "error", err)
+		return status.Error(codes.Internal, fmt.Sprintf("failed to get bucket: %v", err))
+	}
+
+	var exceeded string
+
+	switch {
+	case maxObjects > 0 && bucket.Objects > maxObjects:
+		exceeded = limitObjects
+	case maxBytes > 0 && bucket.Size > maxBytes:
+		exceeded = limitBytes
+	default:
+		return nil
+	}
+
+	log = log.With(
+		slog.Int("objects", bucket.Objects),
+		slog.Int("bytes", bucket.Size),
+		slog.Int("max_objects", maxObjects),
+		slog.Int("max_bytes", maxBytes),
+	)
+
+	if _, ok := s.limitOverrides[ref.Region+"/"+ref.Label]; ok {
+		log.WarnContext(ctx, "Deletion limits overridden")
+		return nil
+	}
+
+	metrics.DeletionRefusals.WithLabelValues(exceeded).Inc()
+	log.ErrorContext(ctx, "Bucket deletion refused", "limit", exceeded)
+
+	return status.Error(codes.FailedPrecondition, fmt.Sprintf(
+		"%v: %d objects and %d bytes, limits are %d objects and %d bytes (0 is unlimited), list %s/%s in the deletion limit overrides of the driver to delete it",
+		ErrDeletionLimitExceeded, bucket.Objects, bucket.Size, maxObjects, maxBytes, ref.Region, ref.Label))
+}
@@ -0,0 +1,173 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner_test
+
+import (
+	"testing"
+
+	"github.com/linode/linodego/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/mock/gomock"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	cosi "sigs.k8s.io/container-object-storage-interface-spec"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient/cache"
+	"github.com/linode/linode-cosi-driver/pkg/metrics"
+	"github.com/linode/linode-cosi-driver/pkg/servers/provisioner"
+	"github.com/linode/linode-cosi-driver/testing/mock"
+)
+
+// TestDeletionLimits is not parallel, as it reads the global refusal metrics.
+func TestDeletionLimits(t *testing.T) { //nolint:paralleltest // reads global metrics
+	for _, tc := range []struct {
+		testName      string
+		bucketID      string
+		options       []provisioner.Option
+		objects, size int
+		tags          map[string]string
+		expectedCode  grpccodes.Code
+		refusedLimit  string
+	}{
+		{
+			testName: "deletes buckets within the limits",
+			bucketID: testBucketIDV2Force,
+			options:  []provisioner.Option{provisioner.WithDeletionLimits(100, 1000)},
+			objects:  100,
+			size:     1000,
+		},
+		{
+			testName:     "refuses buckets with too many objects",
+			bucketID:     testBucketIDV2Force,
+			options:      []provisioner.Option{provisioner.WithDeletionLimits(100, 0)},
+			objects:      101,
+			expectedCode: grpccodes.FailedPrecondition,
+			refusedLimit: "objects",
+		},
+		{
+			testName:     "refuses buckets exceeding the size limit of the class",
+			bucketID:     testBucketIDV2Force + "&max-bytes=1000",
+			size:         1001,
+			expectedCode: grpccodes.FailedPrecondition,
+			refusedLimit: "bytes",
+		},
+		{
+			testName:     "class limits tighten the limits of the driver",
+			bucketID:     testBucketIDV2Force + "&max-objects=50",
+			options:      []provisioner.Option{provisioner.WithDeletionLimits(100, 0)},
+			objects:      51,
+			expectedCode: grpccodes.FailedPrecondition,
+			refusedLimit: "objects",
+		},
+		{
+			testName:     "class limits do not lift the limits of the driver",
+			bucketID:     testBucketIDV2Force + "&max-objects=1000",
+			options:      []provisioner.Option{provisioner.WithDeletionLimits(100, 0)},
+			objects:      500,
+			expectedCode: grpccodes.FailedPrecondition,
+			refusedLimit: "objects",
+		},
+		{
+			testName:     "class limit of zero keeps the limit of the driver",
+			bucketID:     testBucketIDV2Force + "&max-objects=0",
+			options:      []provisioner.Option{provisioner.WithDeletionLimits(100, 1000)},
+			objects:      101,
+			expectedCode: grpccodes.FailedPrecondition,
+			refusedLimit: "objects",
+		},
+		{
+			testName: "deletes buckets listed in the overrides",
+			bucketID: testBucketIDV2Force,
+			options: []provisioner.Option{
+				provisioner.WithDeletionLimits(100, 0),
+				provisioner.WithDeletionLimitOverrides(testRegion + "/" + testBucketName),
+			},
+			objects: 101,
+		},
+		{
+			testName:     "ignores the tag of the former override",
+			bucketID:     testBucketIDV2Force,
+			options:      []provisioner.Option{provisioner.WithDeletionLimits(100, 0)},
+			objects:      101,
+			tags:         map[string]string{"cosi.linode.com/allow-delete": "true"},
+			expectedCode: grpccodes.FailedPrecondition,
+			refusedLimit: "objects",
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockLinode := mock.NewMockLinodeClient(ctrl)
+			mockLinode.EXPECT().
+				ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+				Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+				AnyTimes()
+			mockLinode.EXPECT().
+				GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+				Return(&linodego.ObjectStorageBucket{
+					Region:       testRegion,
+					Label:        testBucketName,
+					EndpointType: linodego.ObjectStorageEndpointE0,
+					Objects:      tc.objects,
+					Size:         tc.size,
+				}, nil)
+
+			mockS3 := mock.NewMockS3Client(ctrl)
+			mockS3.EXPECT().
+				GetBucketTags(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+				Return(tc.tags, nil).
+				AnyTimes()
+
+			if tc.expectedCode == grpccodes.OK {
+				mockS3.EXPECT().
+					Prune(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName), gomock.Any()).
+					Return(nil)
+				mockLinode.EXPECT().
+					DeleteObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(nil)
+			}
+
+			epc := cache.New(discardLog, mockLinode, 0)
+			if err := epc.Refresh(t.Context()); err != nil {
+				t.Fatalf("failed to refresh cache: %v", err)
+			}
+
+			srv, err := provisioner.New(nil, mockLinode, epc, mockS3, true, tc.options...)
+			if err != nil {
+				t.Fatalf("failed to create provisioner server: %v", err)
+			}
+
+			refusals := map[string]float64{}
+			for _, limit := range []string{"objects", "bytes"} {
+				refusals[limit] = testutil.ToFloat64(metrics.DeletionRefusals.WithLabelValues(limit))
+			}
+
+			_, err = srv.DriverDeleteBucket(t.Context(), &cosi.DriverDeleteBucketRequest{BucketId: tc.bucketID})
+			if code := status.Code(err); code != tc.expectedCode {
+				t.Errorf("expected status code %q, but got %q: %v", tc.expectedCode, code, err)
+			}
+
+			for limit, before := range refusals {
+				expected := 0.0
+				if limit == tc.refusedLimit {
+					expected = 1
+				}
+
+				if got := testutil.ToFloat64(metrics.DeletionRefusals.WithLabelValues(limit)) - before; got != expected {
+					t.Errorf("expected %v refusals by %s limit, got %v", expected, limit, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient/cache"
+)
+
+// WithImportedBucketDeletion allows the driver to delete imported buckets. By default
+// imported buckets are retained when the Bucket object is deleted.
+func WithImportedBucketDeletion(allowed bool) Option {
+	return func(s *Server) {
+		s.importedBucketDeletion = allowed
+	}
+}
+
+// resolveBucketRef resolves imported bucket references to the region, label and endpoint
+// type of an existing bucket. Other references are returned unchanged.
+//
+// Legacy IDs are only resolved when their first segment is not a known region, in which
+// case it is treated as an object storage cluster ID, e.g. "us-east-1".
+func (s *Server) resolveBucketRef(ctx context.Context, ref bucketRef) (bucketRef, error) {
+	switch {
+	case ref.hostname != "":
+		endpoints, err := s.endpoints(ctx)
+		if err != nil {
+			return bucketRef{}, status.Error(codes.Internal, err.Error())
+		}
+
+		endpoint, label, ok := endpointForHostname(endpoints, ref.hostname)
+		if !ok {
+			return bucketRef{}, status.Error(codes.InvalidArgument,
+				fmt.Sprintf("%v: %s", ErrUnknownBucketHostname, ref.hostname))
+		}
+
+		ref.Region, ref.Label, ref.EndpointType = endpoint.Region, label, endpoint.EndpointType
+
+	case ref.legacy:
+		endpoints, err := s.endpoints(ctx)
+		if err != nil {
+			return bucketRef{}, status.Error(codes.Internal, err.Error())
+		}
+
+		endpoint, ok := cache.ClusterEndpoint(endpoints, ref.Region)
+		if !ok {
+			return ref, nil
+		}
+
+		ref.Region, ref.EndpointType, ref.imported = endpoint.Region, endpoint.EndpointType, true
+
+	case !ref.imported:
+		return ref, nil
+	}
+
+	// Imported buckets are looked up to verify they exist and to detect their endpoint type.
+	bucket, err := s.client.GetObjectStorageBucket(ctx, ref.Region, ref.Label)
+	if errors.Is(err, ErrNotFound) {
+		return bucketRef{}, status.Error(codes.NotFound, fmt.Sprintf("imported bucket %s/%s does not exist", ref.Region, ref.Label))
+	} else if err != nil {
+		return bucketRef{}, status.Error(codes.Internal, fmt.Sprintf("failed to get bucket: %v", err))
+	}
+
+	if bucket.EndpointType != "" {
+		ref.EndpointType = bucket.EndpointType
+	}
+
+	return ref, nil
+}
+
+// endpointForHostname returns the endpoint serving the bucket hostname, along with the bucket label.
+func endpointForHostname(endpoints []cache.Endpoint, hostname string) (cache.Endpoint, string, bool) {
+	for _, endpoint := range endpoints {
+		if endpoint.Hostname() == "" {
+			continue
+		}
+
+		label, ok := strings.CutSuffix(hostname, "."+endpoint.Hostname())
+		if ok && label != "" {
+			return endpoint, label, true
+		}
+	}
+
+	return cache.Endpoint{}, "", false
+}
@@ -0,0 +1,48 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxKeyTTL is the longest lifetime of keys granted for a BucketAccess. Longer-lived keys
+// should be rotated instead, see pkg/rotation.
+const maxKeyTTL = 10 * 365 * 24 * time.Hour
+
+// parseKeyTTL parses the lifetime of keys granted for a BucketAccess. Keys of classes without
+// lifetime are returned with a zero TTL, and do not expire.
+func parseKeyTTL(params map[string]string) (time.Duration, error) {
+	value, ok := params[ParamKeyTTL]
+	if !ok {
+		return 0, nil
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidKeyTTL, err)
+	}
+
+	if ttl <= 0 {
+		return 0, fmt.Errorf("%w: %q must be positive", ErrInvalidKeyTTL, value)
+	}
+
+	if ttl > maxKeyTTL {
+		return 0, fmt.Errorf("%w: %q exceeds %v", ErrInvalidKeyTTL, value, maxKeyTTL)
+	}
+
+	return ttl, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego/v2"
+	"go.uber.org/mock/gomock"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	cosi "sigs.k8s.io/container-object-storage-interface-spec"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient/cache"
+	"github.com/linode/linode-cosi-driver/pkg/servers/provisioner"
+	"github.com/linode/linode-cosi-driver/testing/mock"
+)
+
+func TestGrantBucketAccessKeyTTL(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockLinode := mock.NewMockLinodeClient(ctrl)
+	mockLinode.EXPECT().
+		ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+		AnyTimes()
+	mockLinode.EXPECT().
+		GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		Return(defaultLinodegoBucket, nil)
+
+	var label string
+
+	mockLinode.EXPECT().
+		CreateObjectStorageKey(gomock.Any(), bucketScopedKey()).
+		DoAndReturn(func(_ context.Context, opts linodego.ObjectStorageKeyCreateOptions) (*linodego.ObjectStorageKey, error) {
+			label = opts.Label
+			return &linodego.ObjectStorageKey{ID: 1, Label: opts.Label, AccessKey: testAccessKey, SecretKey: testSecretKey}, nil
+		})
+
+	mockS3 := mock.NewMockS3Client(ctrl)
+	expectNotFrozen(mockS3)
+
+	epc := cache.New(discardLog, mockLinode, 0)
+	if err := epc.Refresh(t.Context()); err != nil {
+		t.Fatalf("failed to refresh cache: %v", err)
+	}
+
+	srv, err := provisioner.New(nil, mockLinode, epc, mockS3, true)
+	if err != nil {
+		t.Fatalf("failed to create provisioner server: %v", err)
+	}
+
+	req := &cosi.DriverGrantBucketAccessRequest{
+		BucketId:           testBucketID,
+		Name:               testBucketAccessName,
+		AuthenticationType: cosi.AuthenticationType_Key,
+		Parameters:         map[string]string{provisioner.ParamKeyTTL: "24h"},
+	}
+
+	resp, err := srv.DriverGrantBucketAccess(t.Context(), req)
+	if err != nil {
+		t.Fatalf("failed to grant bucket access: %v", err)
+	}
+
+	name, expiresAt, ok := linodeclient.KeyExpiry(label)
+	if !ok || name != testBucketAccessName {
+		t.Fatalf("expected key label to record the expiry, got %q", label)
+	}
+	if until := time.Until(expiresAt); until < 23*time.Hour || until > 24*time.Hour {
+		t.Errorf("expected key to expire in 24h, got %v", expiresAt)
+	}
+
+	secrets := resp.GetCredentials()[provisioner.S3].GetSecrets()
+	if got := secrets[provisioner.S3SecretExpiresAt]; got != expiresAt.Format(time.RFC3339) {
+		t.Errorf("expected credentials to expire at %v, got %q", expiresAt, got)
+	}
+
+	// Invalid TTLs are rejected before any key is created.
+	for _, ttl := range []string{"", "1d", "-1h", "0s", "87601h"} {
+		req.Parameters[provisioner.ParamKeyTTL] = ttl
+
+		_, err := srv.DriverGrantBucketAccess(t.Context(), req)
+		if code := status.Code(err); code != grpccodes.InvalidArgument {
+			t.Errorf("expected key TTL %q to be rejected with %q, got %q: %v", ttl, grpccodes.InvalidArgument, code, err)
+		}
+	}
+}
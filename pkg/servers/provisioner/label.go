@@ -0,0 +1,106 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+const (
+	minLabelLength  = 3
+	maxLabelLength  = 63
+	labelHashLength = 8
+)
+
+// LabelTemplateParams are the fields available in the label template.
+type LabelTemplateParams struct {
+	// Cluster is the cluster ID configured for the driver.
+	Cluster string
+	// Name is the name of the bucket requested by COSI.
+	Name string
+	// Hash is a short, stable hash of the cluster ID and the name.
+	Hash string
+}
+
+// bucketLabel returns the label of the bucket requested under the given name. Without the
+// label template parameter the name is used verbatim. Templated labels longer than
+// the Linode limit are truncated, keeping a hash of the full label as a suffix.
+func (s *Server) bucketLabel(name string, params map[string]string) (string, error) {
+	text, ok := params[ParamLabelTemplate]
+	if !ok {
+		return name, nil
+	}
+
+	tmpl, err := template.New("label").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidLabelTemplate, err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, LabelTemplateParams{
+		Cluster: s.clusterID,
+		Name:    name,
+		Hash:    shortHash(s.clusterID + "/" + name),
+	}); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidLabelTemplate, err)
+	}
+
+	label := truncateLabel(strings.TrimSpace(sb.String()))
+	if err := validateLabel(label); err != nil {
+		return "", err
+	}
+
+	return label, nil
+}
+
+func truncateLabel(label string) string {
+	if len(label) <= maxLabelLength {
+		return label
+	}
+
+	prefix := strings.TrimRight(label[:maxLabelLength-labelHashLength-1], "-")
+
+	return prefix + "-" + shortHash(label)
+}
+
+// validateLabel checks the label against S3 and Linode bucket naming rules. Dots are
+// rejected, as they break TLS for virtual-hosted-style bucket hostnames.
+func validateLabel(label string) error {
+	if len(label) < minLabelLength || len(label) > maxLabelLength {
+		return fmt.Errorf("%w: %q must be between %d and %d characters long",
+			ErrInvalidLabel, label, minLabelLength, maxLabelLength)
+	}
+
+	for _, r := range label {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return fmt.Errorf("%w: %q may contain only lowercase letters, numbers and hyphens", ErrInvalidLabel, label)
+		}
+	}
+
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return fmt.Errorf("%w: %q must start and end with a letter or number", ErrInvalidLabel, label)
+	}
+
+	return nil
+}
+
+func shortHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:labelHashLength]
+}
@@ -0,0 +1,136 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBucketLabel(t *testing.T) {
+	t.Parallel()
+
+	const name = "bucketclaim-5f9c3a2e-0d4b-4a86-9f3e-6f1b2c8d7e90"
+
+	longName := strings.Repeat("a", 80)
+
+	for _, tc := range []struct {
+		testName string
+		template string
+		name     string
+		expected string
+		err      error
+	}{
+		{
+			testName: "no template",
+			name:     "Verbatim.Name",
+			expected: "Verbatim.Name",
+		},
+		{
+			testName: "prefix, cluster and name",
+			template: "cosi-{{ .Cluster }}-{{ .Name }}",
+			name:     "photos",
+			expected: "cosi-prod-photos",
+		},
+		{
+			testName: "hash",
+			template: "app-{{ .Hash }}",
+			name:     name,
+			expected: "app-" + shortHash("prod/"+name),
+		},
+		{
+			testName: "overlong label is truncated",
+			template: "{{ .Name }}",
+			name:     longName,
+			expected: strings.Repeat("a", maxLabelLength-labelHashLength-1) + "-" + shortHash(longName),
+		},
+		{
+			testName: "uppercase",
+			template: "{{ .Name }}",
+			name:     "Photos",
+			err:      ErrInvalidLabel,
+		},
+		{
+			testName: "dots",
+			template: "{{ .Name }}",
+			name:     "photos.example",
+			err:      ErrInvalidLabel,
+		},
+		{
+			testName: "too short",
+			template: "{{ .Name }}",
+			name:     "ab",
+			err:      ErrInvalidLabel,
+		},
+		{
+			testName: "leading hyphen",
+			template: "-{{ .Name }}",
+			name:     "photos",
+			err:      ErrInvalidLabel,
+		},
+		{
+			testName: "unknown field",
+			template: "{{ .Namespace }}",
+			name:     "photos",
+			err:      ErrInvalidLabelTemplate,
+		},
+		{
+			testName: "malformed template",
+			template: "{{ .Name",
+			name:     "photos",
+			err:      ErrInvalidLabelTemplate,
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			t.Parallel()
+
+			srv := &Server{clusterID: "prod"}
+
+			params := map[string]string{}
+			if tc.template != "" {
+				params[ParamLabelTemplate] = tc.template
+			}
+
+			label, err := srv.bucketLabel(tc.name, params)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got: %v", tc.err, err)
+			}
+			if label != tc.expected {
+				t.Fatalf("expected label %q, got %q", tc.expected, label)
+			}
+			if tc.err == nil && tc.template != "" && validateLabel(label) != nil {
+				t.Fatalf("expected label %q to be valid", label)
+			}
+		})
+	}
+}
+
+func TestTruncateLabelIsDeterministic(t *testing.T) {
+	t.Parallel()
+
+	a := truncateLabel(strings.Repeat("a", 70) + "-one")
+	b := truncateLabel(strings.Repeat("a", 70) + "-two")
+
+	if len(a) != maxLabelLength || len(b) != maxLabelLength {
+		t.Fatalf("expected truncated labels to be %d characters long, got %d and %d", maxLabelLength, len(a), len(b))
+	}
+	if a == b {
+		t.Fatalf("expected distinct names to keep distinct labels, got %q", a)
+	}
+	if again := truncateLabel(strings.Repeat("a", 70) + "-one"); again != a {
+		t.Fatalf("expected truncation to be deterministic, got %q and %q", a, again)
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/linode/linodego/v2"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/pkg/s3"
+)
+
+// MigrateOptions select the target of a bucket migration. Empty fields keep the value of the
+// source bucket, except for the endpoint type of buckets moved to another region, which is
+// selected as for new buckets.
+type MigrateOptions struct {
+	Region       string
+	EndpointType linodego.ObjectStorageEndpointType
+	Label        string
+}
+
+// MigrateBucket copies a bucket, identified by its bucket ID or an import reference, to the
+// target of the options, and returns the bucket ID of the target. The target is created unless
+// it exists, the objects are copied with checksum verification, and the ACL, CORS setting and
+// policy of the source are replicated. Interrupted migrations resume when run again. The source
+// bucket is left as is.
+func (s *Server) MigrateBucket(ctx context.Context, id string, opts MigrateOptions) (string, error) {
+	ref, err := s.parseBucketID(id)
+	if err != nil {
+		return "", err
+	}
+
+	ref, err = s.resolveBucketRef(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+
+	log := s.logAttr(
+		slog.String(KeyBucketRegion, ref.Region),
+		slog.String(KeyBucketLabel, ref.Label),
+	).WithGroup("MigrateBucket")
+
+	source, err := s.client.GetObjectStorageBucket(ctx, ref.Region, ref.Label)
+	if err != nil {
+		return "", fmt.Errorf("failed to get bucket: %w", err)
+	}
+
+	access, err := s.client.GetObjectStorageBucketAccess(ctx, ref.Region, ref.Label)
+	if err != nil {
+		return "", fmt.Errorf("failed to get bucket access: %w", err)
+	}
+
+	cors := ParamCORSValueDisabled
+	if bucketAccessCORSEnabled(access) {
+		cors = ParamCORSValueEnabled
+	}
+
+	candidate, label, err := s.migrationCandidate(ctx, log, source, cors, opts)
+	if err != nil {
+		return "", err
+	}
+
+	log = log.With(
+		slog.String("target_"+KeyBucketRegion, candidate.region),
+		slog.String("target_"+KeyBucketLabel, label),
+	)
+
+	src, srcCleanup, err := s.s3ClientForBucket(ctx, bucketRef{
+		Region:       source.Region,
+		Label:        source.Label,
+		EndpointType: source.EndpointType,
+	}, linodeclient.KeyPermissionsReadWrite)
+	if err != nil {
+		return "", fmt.Errorf("failed to create bucket-scoped credentials: %w", err)
+	}
+	defer cleanupWithTimeout(ctx, log, srcCleanup)
+
+	if err := s.checkOwnership(ctx, log, src, source.Region, source.Label, ownershipCheck); err != nil {
+		return "", err
+	}
+
+	tags, err := src.GetBucketTags(ctx, source.Region, source.Label)
+	if err != nil {
+		return "", fmt.Errorf("failed to get bucket tags: %w", err)
+	}
+	if deleteAfter, pending, _ := pendingDeletion(tags); pending {
+		return "", fmt.Errorf("%w until %s, restore it first", ErrBucketPendingDeletion, deleteAfter.Format(time.RFC3339))
+	}
+
+	policy, err := src.GetBucketPolicy(ctx, source.Region, source.Label)
+	if err != nil {
+		return "", fmt.Errorf("failed to get bucket policy: %w", err)
+	}
+
+	target, err := s.migrationTarget(ctx, log, candidate, label, access.ACL, cors)
+	if err != nil {
+		return "", err
+	}
+
+	dst, dstCleanup, err := s.s3ClientForBucket(ctx, bucketRef{
+		Region:       target.Region,
+		Label:        target.Label,
+		EndpointType: target.EndpointType,
+	}, linodeclient.KeyPermissionsReadWrite)
+	if err != nil {
+		return "", fmt.Errorf("failed to create bucket-scoped credentials for target: %w", err)
+	}
+	defer cleanupWithTimeout(ctx, log, dstCleanup)
+
+	result, err := s3.Copy(ctx,
+		s3.Location{Client: src, Region: source.Region, Bucket: source.Label},
+		s3.Location{Client: dst, Region: target.Region, Bucket: target.Label},
+		s3.WithCopyLogger(log),
+		s3.WithCopyChecksums(),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to copy objects: %w", err)
+	}
+
+	if err := s.client.UpdateObjectStorageBucketAccess(ctx, target.Region, target.Label,
+		linodego.ObjectStorageBucketUpdateAccessOptions{ACL: access.ACL, CorsEnabled: cors.BoolP()}); err != nil {
+		return "", fmt.Errorf("failed to update bucket access: %w", err)
+	}
+
+	policy, err = s3.RenameBucket(policy, source.Label, target.Label)
+	if err != nil {
+		return "", err
+	}
+
+	if policy != "" {
+		if err := dst.SetBucketPolicy(ctx, target.Region, target.Label, policy); err != nil {
+			return "", fmt.Errorf("failed to set bucket policy: %w", err)
+		}
+	}
+
+	log.InfoContext(ctx, "Bucket migrated",
+		slog.Int64("objects", result.Objects),
+		slog.Int64("bytes", result.Bytes),
+		slog.Int64("skipped", result.Skipped),
+	)
+
+	cleanup := ParamCleanupValue("")
+	if ref.Cleanup {
+		cleanup = ParamCleanupForce
+	}
+
+	return s.bucketID(target, deletionParams{
+		cleanup:          cleanup,
+		softDeletePeriod: ref.SoftDeletePeriod,
+		maxObjects:       ref.MaxObjects,
+		maxBytes:         ref.MaxBytes,
+		archiveTo:        ref.ArchiveTo,
+	}), nil
+}
+
+// migrationCandidate returns the region and endpoint type of the migration target, along with
+// its label.
+func (s *Server) migrationCandidate(
+	ctx context.Context,
+	log *slog.Logger,
+	source *linodego.ObjectStorageBucket,
+	cors ParamCORSValue,
+	opts MigrateOptions,
+) (regionCandidate, string, error) {
+	region, err := s.resolveRegion(ctx, log, cmp.Or(opts.Region, source.Region))
+	if err != nil {
+		return regionCandidate{}, "", err
+	}
+
+	label := cmp.Or(opts.Label, source.Label)
+	if region == source.Region && label == source.Label {
+		return regionCandidate{}, "", fmt.Errorf("%w: bucket cannot be migrated into itself, change the region or label",
+			ErrInvalidMigrationTarget)
+	}
+
+	if err := validateLabel(label); err != nil {
+		return regionCandidate{}, "", fmt.Errorf("%w: %w", ErrInvalidMigrationTarget, err)
+	}
+
+	endpointType := opts.EndpointType
+	if endpointType == "" && region == source.Region {
+		endpointType = source.EndpointType
+	}
+
+	endpointType, err = s.selectEndpointType(ctx, region, map[string]string{
+		ParamEndpointType: string(endpointType),
+		ParamCORS:         string(cors),
+	})
+	if err != nil {
+		return regionCandidate{}, "", fmt.Errorf("%w: %w", ErrInvalidMigrationTarget, err)
+	}
+
+	return regionCandidate{region: region, endpointType: endpointType}, label, nil
+}
+
+// migrationTarget creates the target bucket, or returns it when it exists already, e.g. when
+// resuming a migration. Targets owned by other clusters are refused.
+func (s *Server) migrationTarget(
+	ctx context.Context,
+	log *slog.Logger,
+	candidate regionCandidate,
+	label string,
+	acl linodego.ObjectStorageACL,
+	cors ParamCORSValue,
+) (*linodego.ObjectStorageBucket, error) {
+	bucket, err := s.client.GetObjectStorageBucket(ctx, candidate.region, label)
+	mode := ownershipAdopt
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		bucket, _, err = s.createBucketInCandidateRegions(ctx, log, []regionCandidate{candidate}, label, acl, cors)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create bucket: %w", err)
+		}
+		mode = ownershipStamp
+	case err != nil:
+		return nil, fmt.Errorf("failed to check if bucket exists: %w", err)
+	case candidate.endpointType != "" && bucket.EndpointType != candidate.endpointType:
+		return nil, fmt.Errorf("%w: bucket exists with endpoint type %s", ErrInvalidMigrationTarget, bucket.EndpointType)
+	default:
+		log.InfoContext(ctx, "Resuming migration into existing bucket")
+	}
+
+	if err := s.ensureOwnership(ctx, log, bucket, mode); err != nil {
+		return nil, err
+	}
+
+	return bucket, nil
+}
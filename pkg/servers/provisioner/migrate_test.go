@@ -0,0 +1,150 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/linode/linodego/v2"
+	"go.uber.org/mock/gomock"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient/cache"
+	"github.com/linode/linode-cosi-driver/pkg/servers/provisioner"
+	"github.com/linode/linode-cosi-driver/testing/mock"
+)
+
+const (
+	testMigratedBucketName = "migrated-bucket"
+	testMigratedBucketID   = "v2:cleanup=force&label=" + testMigratedBucketName + "&region=" + testRegion + "&type=E0"
+)
+
+func newMigrateServer(t *testing.T, mockLinode *mock.MockLinodeClient, mockS3 *mock.MockS3Client) *provisioner.Server {
+	t.Helper()
+
+	mockLinode.EXPECT().
+		ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+		AnyTimes()
+	mockLinode.EXPECT().
+		GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		Return(defaultLinodegoBucket, nil).
+		AnyTimes()
+	mockLinode.EXPECT().
+		GetObjectStorageBucketAccess(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		Return(defaultLinodegoBucketAccess, nil).
+		AnyTimes()
+
+	epc := cache.New(discardLog, mockLinode, 0)
+	if err := epc.Refresh(t.Context()); err != nil {
+		t.Fatalf("failed to refresh cache: %v", err)
+	}
+
+	srv, err := provisioner.New(nil, mockLinode, epc, mockS3, true)
+	if err != nil {
+		t.Fatalf("failed to create provisioner server: %v", err)
+	}
+
+	return srv
+}
+
+func TestMigrateBucket(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockLinode := mock.NewMockLinodeClient(ctrl)
+	mockLinode.EXPECT().
+		GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testMigratedBucketName)).
+		Return(nil, provisioner.ErrNotFound)
+	expectCreateBucket(t, mockLinode, linodego.ObjectStorageEndpointE0, nil, &linodego.ObjectStorageBucket{
+		Label:        testMigratedBucketName,
+		Region:       testRegion,
+		EndpointType: linodego.ObjectStorageEndpointE0,
+	})
+	mockLinode.EXPECT().
+		UpdateObjectStorageBucketAccess(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testMigratedBucketName),
+			gomock.Eq(linodego.ObjectStorageBucketUpdateAccessOptions{
+				ACL:         defaultLinodegoBucketAccess.ACL,
+				CorsEnabled: defaultLinodegoBucketAccess.CorsEnabled,
+			})).
+		Return(nil)
+
+	// An object copied by an earlier attempt is skipped.
+	objects := &fakeObjects{buckets: map[string]map[string]int64{
+		testBucketName:         {"a": 1, "dir/b": 2},
+		testMigratedBucketName: {"a": 1},
+	}}
+	metadata := &fakeBucketMetadata{
+		tags:   map[string]string{},
+		policy: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::test-bucket/*"}]}`,
+	}
+
+	var policy string
+
+	mockS3 := mock.NewMockS3Client(ctrl)
+	objects.expect(mockS3)
+	metadata.expect(mockS3)
+	mockS3.EXPECT().
+		SetBucketPolicy(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testMigratedBucketName), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _, p string) error {
+			policy = p
+			return nil
+		})
+
+	srv := newMigrateServer(t, mockLinode, mockS3)
+
+	id, err := srv.MigrateBucket(t.Context(), testBucketIDV2Force, provisioner.MigrateOptions{Label: testMigratedBucketName})
+	if err != nil {
+		t.Fatalf("failed to migrate bucket: %v", err)
+	}
+
+	if id != testMigratedBucketID {
+		t.Errorf("expected bucket ID %q, got %q", testMigratedBucketID, id)
+	}
+
+	expected := []string{"a", "dir/b"}
+	if keys := objects.keys(testMigratedBucketName); !slices.Equal(keys, expected) {
+		t.Errorf("expected migrated keys %v, got %v", expected, keys)
+	}
+
+	if !strings.Contains(policy, "arn:aws:s3:::"+testMigratedBucketName+"/*") || strings.Contains(policy, testBucketName) {
+		t.Errorf("expected policy to refer to the migrated bucket, got %s", policy)
+	}
+}
+
+func TestMigrateBucketInvalidTarget(t *testing.T) {
+	t.Parallel()
+
+	for name, opts := range map[string]provisioner.MigrateOptions{
+		"same bucket":           {Region: testRegion},
+		"unknown endpoint type": {Label: testMigratedBucketName, EndpointType: "E9"},
+		"invalid label":         {Label: "Invalid_Label"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			srv := newMigrateServer(t, mock.NewMockLinodeClient(ctrl), mock.NewMockS3Client(ctrl))
+
+			_, err := srv.MigrateBucket(t.Context(), testBucketIDV2, opts)
+			if !errors.Is(err, provisioner.ErrInvalidMigrationTarget) {
+				t.Errorf("expected error %v, got %v", provisioner.ErrInvalidMigrationTarget, err)
+			}
+		})
+	}
+}
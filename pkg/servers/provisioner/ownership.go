@@ -0,0 +1,133 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/linode/linodego/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/pkg/s3"
+)
+
+// WithClusterID enables ownership markers. Buckets are tagged with the cluster ID on creation,
+// and buckets tagged by other clusters are neither adopted, pruned nor deleted. Existing buckets
+// without a marker are not adopted either, unless enabled with WithUnmarkedBucketAdoption.
+func WithClusterID(clusterID string) Option {
+	return func(s *Server) {
+		s.clusterID = clusterID
+	}
+}
+
+// WithUnmarkedBucketAdoption allows the driver to adopt existing buckets without an ownership
+// marker, e.g. created before markers were enabled, and to stamp them with its marker.
+func WithUnmarkedBucketAdoption(allowed bool) Option {
+	return func(s *Server) {
+		s.unmarkedBucketAdoption = allowed
+	}
+}
+
+// ownershipMode selects how checkOwnership handles buckets without an ownership marker.
+type ownershipMode int
+
+const (
+	// ownershipCheck lets unmarked buckets pass, e.g. when deleting buckets created before
+	// markers were enabled.
+	ownershipCheck ownershipMode = iota
+	// ownershipStamp adds the marker to unmarked buckets, which were created by the driver.
+	ownershipStamp
+	// ownershipAdopt adds the marker to unmarked existing buckets, when the adoption of
+	// unmarked buckets is enabled, and refuses them otherwise.
+	ownershipAdopt
+)
+
+// checkOwnership ensures that the bucket is not owned by another cluster. Buckets that no
+// longer exist pass the check. Buckets without an ownership marker are handled by mode.
+func (s *Server) checkOwnership(
+	ctx context.Context,
+	log *slog.Logger,
+	s3cli s3.Client,
+	region, label string,
+	mode ownershipMode,
+) error {
+	if s.clusterID == "" {
+		return nil
+	}
+
+	tags, err := s3cli.GetBucketTags(ctx, region, label)
+	if s3.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return status.Error(codes.Internal, fmt.Sprintf("failed to get bucket tags: %v", err))
+	}
+
+	owner, ok := tags[TagClusterID]
+	if ok && owner != s.clusterID {
+		log.ErrorContext(ctx, "Bucket is owned by another cluster", KeyBucketOwner, owner)
+
+		return status.Error(codes.FailedPrecondition, fmt.Sprintf("%v: %s", ErrBucketOwnedByOtherCluster, owner))
+	}
+
+	if ok || mode == ownershipCheck {
+		return nil
+	}
+
+	if mode == ownershipAdopt && !s.unmarkedBucketAdoption {
+		log.ErrorContext(ctx, "Refusing to adopt bucket without ownership marker")
+
+		return status.Error(codes.FailedPrecondition, fmt.Sprintf(
+			"%v, it may be owned by another cluster, enable the adoption of unmarked buckets to adopt it",
+			ErrBucketNotMarked))
+	}
+
+	tags[TagClusterID] = s.clusterID
+	if err := s3cli.SetBucketTags(ctx, region, label, tags); err != nil {
+		return status.Error(codes.Internal, fmt.Sprintf("failed to set bucket ownership marker: %v", err))
+	}
+
+	log.InfoContext(ctx, "Bucket ownership marker set")
+
+	return nil
+}
+
+// ensureOwnership stamps the bucket with the ownership marker, unless it is owned by another
+// cluster. Unmarked buckets are handled by mode.
+func (s *Server) ensureOwnership(
+	ctx context.Context,
+	log *slog.Logger,
+	bucket *linodego.ObjectStorageBucket,
+	mode ownershipMode,
+) error {
+	if s.clusterID == "" {
+		return nil
+	}
+
+	s3cli, cleanup, err := s.s3ClientForBucket(ctx, bucketRef{
+		Region:       bucket.Region,
+		Label:        bucket.Label,
+		EndpointType: bucket.EndpointType,
+	}, linodeclient.KeyPermissionsReadWrite)
+	if err != nil {
+		return status.Error(codes.Internal, fmt.Sprintf("failed to create bucket-scoped credentials: %v", err))
+	}
+	defer cleanupWithTimeout(ctx, log, cleanup)
+
+	return s.checkOwnership(ctx, log, s3cli, bucket.Region, bucket.Label, mode)
+}
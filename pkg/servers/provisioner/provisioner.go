@@ -20,15 +20,16 @@ import (
 	"fmt"
 	"log/slog"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/linode/linodego/v2"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	cosi "sigs.k8s.io/container-object-storage-interface-spec"
 
+	"github.com/linode/linode-cosi-driver/pkg/deletion"
 	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
 	"github.com/linode/linode-cosi-driver/pkg/linodeclient/cache"
 	"github.com/linode/linode-cosi-driver/pkg/s3"
@@ -39,15 +40,103 @@ type Server struct {
 	log  *slog.Logger
 	once sync.Once
 
-	client linodeclient.Client
-	cache  cache.Cache
-	s3cli  s3.Client
-	s3SSL  bool
+	client  linodeclient.Client
+	cache   cache.Cache
+	regions cache.Regions
+	s3cli   s3.Client
+	s3SSL   bool
+
+	keys      *linodeclient.KeyPool
+	s3clients *s3.Clients
+	s3opts    []s3.Option
+
+	deletions *deletion.Queue
+
+	maxDeleteObjects int
+	maxDeleteBytes   int
+	limitOverrides   map[string]struct{}
+
+	account string
+
+	signingKey []byte
+	allowlist  map[string]struct{}
+
+	importedBucketDeletion bool
+
+	regionFailures regionFailures
+
+	clusterID              string
+	unmarkedBucketAdoption bool
+
+	keyRotation bool
+}
+
+// Option configures optional behavior of the Server.
+type Option func(*Server)
+
+// WithAccount sets the account recorded in minted bucket IDs. Bucket IDs recorded
+// for a different account are rejected.
+func WithAccount(account string) Option {
+	return func(s *Server) {
+		s.account = account
+	}
+}
+
+// WithRegions enables validation of requested regions. Legacy Object Storage cluster IDs
+// are resolved to their regions.
+func WithRegions(regions cache.Regions) Option {
+	return func(s *Server) {
+		s.regions = regions
+	}
+}
+
+// WithSigningKey enables signing of minted bucket IDs. Once set, bucket access is granted
+// and buckets are deleted only for signed bucket IDs or buckets on the allowlist.
+func WithSigningKey(key []byte) Option {
+	return func(s *Server) {
+		s.signingKey = key
+	}
+}
+
+// WithBucketAllowlist allows unsigned bucket IDs for the listed buckets, e.g. imported buckets.
+// Entries have the "region/label" form.
+func WithBucketAllowlist(buckets ...string) Option {
+	return func(s *Server) {
+		if s.allowlist == nil {
+			s.allowlist = make(map[string]struct{}, len(buckets))
+		}
+		for _, bucket := range buckets {
+			s.allowlist[bucket] = struct{}{}
+		}
+	}
 }
 
 // Interface guards.
 var _ cosi.ProvisionerServer = (*Server)(nil)
 
+// WithKeyPool sets the pool ephemeral S3 credentials are leased from. By default every
+// operation creates its own credentials.
+func WithKeyPool(pool *linodeclient.KeyPool) Option {
+	return func(s *Server) {
+		s.keys = pool
+	}
+}
+
+// WithS3ClientOptions sets the options of the S3 clients using ephemeral credentials.
+func WithS3ClientOptions(opts ...s3.Option) Option {
+	return func(s *Server) {
+		s.s3opts = append(s.s3opts, opts...)
+	}
+}
+
+// WithKeyRotation enables the deletion of rotated keys when access is revoked, see pkg/rotation.
+// Without it, revoking access only deletes the granted key.
+func WithKeyRotation(enabled bool) Option {
+	return func(s *Server) {
+		s.keyRotation = enabled
+	}
+}
+
 // New returns provisioner.Server with default values.
 func New(
 	logger *slog.Logger,
@@ -55,6 +144,7 @@ func New(
 	cache cache.Cache,
 	s3cli s3.Client,
 	s3SSL bool,
+	opts ...Option,
 ) (*Server, error) {
 	srv := &Server{
 		log:    logger,
@@ -64,46 +154,79 @@ func New(
 		s3SSL:  s3SSL,
 	}
 
+	for _, opt := range opts {
+		opt(srv)
+	}
+
+	if srv.keys == nil {
+		srv.keys = linodeclient.NewKeyPool(srv.logAttr(), client, 0)
+	}
+
+	srv.s3clients = s3.NewClients(s3SSL, append([]s3.Option{s3.WithLogger(srv.logAttr())}, srv.s3opts...)...)
+	srv.keys.OnRevoke(func(key *linodego.ObjectStorageKey) {
+		srv.s3clients.Forget(key.AccessKey)
+	})
+
 	return srv, nil
 }
 
-func (s *Server) s3ClientForBucket(ctx context.Context, region, label string) (s3.Client, func(context.Context) error, error) {
+// s3ClientForBucket returns a client using ephemeral credentials limited to the bucket and permissions,
+// or the static client bound to the bucket endpoint.
+func (s *Server) s3ClientForBucket(
+	ctx context.Context,
+	ref bucketRef,
+	permissions string,
+) (s3.Client, func(context.Context) error, error) {
 	if s.s3cli != nil {
-		return s.s3cli, func(context.Context) error { return nil }, nil
+		return s.staticS3Client(ref.Region, func() (string, linodego.ObjectStorageEndpointType, error) {
+			return s.endpointForRef(ctx, ref)
+		})
 	}
 
-	bucket, err := s.client.GetObjectStorageBucket(ctx, region, label)
+	region, label := ref.Region, ref.Label
+
+	endpoint, _, err := s.endpointForRef(ctx, ref)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get bucket for S3 client: %w", err)
+		return nil, nil, fmt.Errorf("failed to resolve bucket endpoint for S3 client: %w", err)
 	}
 
-	endpoint, err := s.endpointForBucket(ctx, region, bucket)
+	lease, err := s.keys.Acquire(ctx, linodeclient.KeyScope{Region: region, Bucket: label, Permissions: permissions})
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to resolve bucket endpoint for S3 client: %w", err)
+		// Bucket IDs carrying the endpoint type skip the bucket lookup, so check
+		// here whether the key creation failed because the bucket is gone.
+		if _, gerr := s.client.GetObjectStorageBucket(ctx, region, label); errors.Is(gerr, ErrNotFound) {
+			return nil, nil, fmt.Errorf("failed to get bucket for S3 client: %w", gerr)
+		}
+		return nil, nil, fmt.Errorf("failed to create object storage key for bucket: %w", err)
 	}
 
-	keyLabel := fmt.Sprintf("cosi-bucket-%s", uuid.NewString())
-	opts := linodego.ObjectStorageKeyCreateOptions{
-		Label: keyLabel,
-		BucketAccess: []linodego.ObjectStorageKeyBucketAccessCreateOptions{
-			{
-				Region:      region,
-				BucketName:  label,
-				Permissions: string(ParamPermissionsValueReadWrite),
-			},
-		},
+	return s.s3clients.Get(endpoint, lease.Key.AccessKey, lease.Key.SecretKey), lease.Release, nil
+}
+
+// staticS3Client returns the client using static credentials. Clients able to serve
+// specific endpoints are bound to the bucket endpoint returned by resolve.
+func (s *Server) staticS3Client(
+	region string,
+	resolve func() (string, linodego.ObjectStorageEndpointType, error),
+) (s3.Client, func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+
+	binder, ok := s.s3cli.(s3.EndpointBinder)
+	if !ok {
+		return s.s3cli, noop, nil
 	}
 
-	key, err := s.client.CreateObjectStorageKey(ctx, opts)
+	endpoint, endpointType, err := resolve()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create object storage key for bucket: %w", err)
+		return nil, nil, fmt.Errorf("failed to resolve bucket endpoint for S3 client: %w", err)
 	}
 
-	cleanup := func(cctx context.Context) error {
-		return s.client.DeleteObjectStorageKey(cctx, key.ID)
+	cli, err := binder.ForEndpoint(region, endpoint, endpointType)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create S3 client for bucket endpoint: %w", err)
 	}
 
-	return s3.NewWithEndpoint(endpoint, key.AccessKey, key.SecretKey, s.s3SSL), cleanup, nil
+	return cli, noop, nil
 }
 
 func (s *Server) s3ClientForPolicy(
@@ -111,7 +234,10 @@ func (s *Server) s3ClientForPolicy(
 	bucket *linodego.ObjectStorageBucket,
 ) (s3.Client, func(context.Context) error, error) {
 	if s.s3cli != nil {
-		return s.s3cli, func(context.Context) error { return nil }, nil
+		return s.staticS3Client(bucket.Region, func() (string, linodego.ObjectStorageEndpointType, error) {
+			endpoint, err := s.endpointForBucket(ctx, bucket.Region, bucket)
+			return endpoint, bucket.EndpointType, err
+		})
 	}
 
 	endpoint, err := s.endpointForBucket(ctx, bucket.Region, bucket)
@@ -119,12 +245,16 @@ func (s *Server) s3ClientForPolicy(
 		return nil, nil, fmt.Errorf("failed to resolve bucket endpoint for policy updates: %w", err)
 	}
 
-	key, cleanup, err := linodeclient.NewEphemeralS3Credentials(ctx, s.logAttr(), s.client, bucket.Region)
+	lease, err := s.keys.Acquire(ctx, linodeclient.KeyScope{
+		Region:      bucket.Region,
+		Bucket:      bucket.Label,
+		Permissions: linodeclient.KeyPermissionsReadWrite,
+	})
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create object storage key for policy updates: %w", err)
 	}
 
-	return s3.NewWithEndpoint(endpoint, key.AccessKey, key.SecretKey, s.s3SSL), cleanup, nil
+	return s.s3clients.Get(endpoint, lease.Key.AccessKey, lease.Key.SecretKey), lease.Release, nil
 }
 
 func (s *Server) logAttr(attr ...slog.Attr) *slog.Logger {
@@ -154,10 +284,8 @@ func cleanupWithTimeout(ctx context.Context, log *slog.Logger, cleanup func(cont
 //  1. If a bucket that matches both name and parameters already exists, then OK (success) must be returned.
 //  2. If a bucket by same name, but different parameters is provided, then the appropriate error code ALREADY_EXISTS must be returned.
 func (s *Server) DriverCreateBucket(ctx context.Context, req *cosi.DriverCreateBucketRequest) (*cosi.DriverCreateBucketResponse, error) {
-	label := req.GetName()
-	region := req.GetParameters()[ParamRegion]
+	name := req.GetName()
 	cors := ParamCORSValue(req.GetParameters()[ParamCORS])
-	cleanup := ParamCleanupValue(req.GetParameters()[ParamCleanup])
 	policyTemplate := req.GetParameters()[ParamPolicy]
 
 	acl := linodego.ObjectStorageACL(req.GetParameters()[ParamACL])
@@ -166,27 +294,43 @@ func (s *Server) DriverCreateBucket(ctx context.Context, req *cosi.DriverCreateB
 	}
 
 	log := s.logAttr(
-		slog.String(KeyBucketRegion, region),
-		slog.String(KeyBucketLabel, label),
+		slog.String(KeyBucketName, name),
 	).WithGroup("DriverCreateBucket")
 
 	log.InfoContext(ctx, "Bucket creation initiated")
 
-	if region == "" {
-		log.ErrorContext(ctx, "Required parameter was not provided in the request", "error", ErrMissingRegion)
-		return nil, status.Error(codes.InvalidArgument, "region was not provided")
+	label, err := s.bucketLabel(name, req.GetParameters())
+	if err != nil {
+		log.ErrorContext(ctx, "Invalid bucket label", "error", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
+	log = log.With(slog.String(KeyBucketLabel, label))
 
-	endpointType, err := s.selectEndpointType(ctx, region, req.GetParameters())
+	onDelete, err := parseDeletionParams(req.GetParameters())
 	if err != nil {
-		log.ErrorContext(ctx, "Failed to select endpoint", "error", err)
-		if _, ok := status.FromError(err); ok {
-			return nil, err
-		}
+		log.ErrorContext(ctx, "Invalid deletion parameters", "error", err)
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
-	if endpointType != "" {
-		log = log.With(slog.String(KeyBucketEndpointType, string(endpointType)))
+
+	if onDelete.softDeletePeriod > 0 && s.deletions == nil {
+		log.ErrorContext(ctx, "Soft delete unavailable", "error", ErrSoftDeleteUnavailable)
+		return nil, status.Error(codes.FailedPrecondition, ErrSoftDeleteUnavailable.Error())
+	}
+
+	cloneFrom, err := parseCloneSource(req.GetParameters())
+	if err != nil {
+		log.ErrorContext(ctx, "Invalid clone source", "error", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	regions, err := s.candidateRegions(ctx, log, req.GetParameters())
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.selectCandidates(ctx, log, regions, req.GetParameters())
+	if err != nil {
+		return nil, err
 	}
 
 	policy, err := s.buildBucketPolicy(policyTemplate, label)
@@ -195,19 +339,78 @@ func (s *Server) DriverCreateBucket(ctx context.Context, req *cosi.DriverCreateB
 		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to generate bucket policy: %v", err))
 	}
 
-	bucket, err := s.client.GetObjectStorageBucket(ctx, region, label)
-	if err != nil && !errors.Is(err, ErrNotFound) {
-		log.ErrorContext(ctx, "Failed to check if bucket exists", "error", err)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to check if bucket exists: %v", err))
+	resp, err := s.createOrAdoptBucket(ctx, log, candidates, label, acl, cors, onDelete, policy)
+	if err != nil || cloneFrom == nil {
+		return resp, err
 	}
 
-	if bucket == nil {
-		// Create the bucket if it doesn't exist, then apply policy if provided.
-		return s.createBucketAndApplyPolicy(ctx, log, region, label, endpointType, acl, cors, cleanup, policy)
+	if err := s.cloneBucket(ctx, log, resp.GetBucketId(), *cloneFrom); err != nil {
+		return nil, err
 	}
 
-	// Bucket exists: validate parameters and re-apply policy for idempotency.
-	return s.ensureExistingBucket(ctx, log, bucket, region, label, endpointType, acl, cors, cleanup, policy)
+	return resp, status.Error(codes.OK, "bucket cloned")
+}
+
+// createOrAdoptBucket creates the bucket in the first available candidate region, unless it
+// exists in any of them already.
+func (s *Server) createOrAdoptBucket(
+	ctx context.Context,
+	log *slog.Logger,
+	candidates []regionCandidate,
+	label string,
+	acl linodego.ObjectStorageACL,
+	cors ParamCORSValue,
+	onDelete deletionParams,
+	policy string,
+) (*cosi.DriverCreateBucketResponse, error) {
+	// The bucket may already exist in any of the candidate regions, e.g. after a fallback.
+	for _, candidate := range candidates {
+		log := candidate.logger(log)
+
+		bucket, err := s.client.GetObjectStorageBucket(ctx, candidate.region, label)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			log.ErrorContext(ctx, "Failed to check if bucket exists", "error", err)
+			return nil, status.Error(codes.Internal, fmt.Sprintf("failed to check if bucket exists: %v", err))
+		}
+
+		if bucket == nil {
+			continue
+		}
+
+		if candidate.err != nil {
+			log.ErrorContext(ctx, "Bucket exists in region without requested endpoint type", "error", candidate.err)
+			return nil, status.Error(codes.AlreadyExists, "bucket exists with different parameters")
+		}
+
+		// Bucket exists: validate parameters and re-apply policy for idempotency.
+		return s.ensureExistingBucket(ctx, log, bucket, candidate.region, label, candidate.endpointType, acl, cors, onDelete, policy)
+	}
+
+	// Create the bucket if it doesn't exist, then apply policy if provided.
+	return s.createBucketAndApplyPolicy(ctx, log, candidates, label, acl, cors, onDelete, policy)
+}
+
+// resolveRegion validates the requested region, resolving legacy cluster IDs to regions.
+// When the regions cannot be listed, the region is used as provided.
+func (s *Server) resolveRegion(ctx context.Context, log *slog.Logger, region string) (string, error) {
+	if s.regions == nil {
+		return region, nil
+	}
+
+	resolved, err := s.regions.Resolve(ctx, region)
+	if errors.Is(err, cache.ErrUnknownRegion) {
+		log.ErrorContext(ctx, "Unknown region", "error", err)
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	} else if err != nil {
+		log.WarnContext(ctx, "Failed to resolve region, using region as provided", "error", err)
+		return region, nil
+	}
+
+	if resolved != region {
+		log.InfoContext(ctx, "Resolved legacy cluster ID to region", "resolved_"+KeyBucketRegion, resolved)
+	}
+
+	return resolved, nil
 }
 
 func (s *Server) buildBucketPolicy(policyTemplate, label string) (string, error) {
@@ -222,36 +425,33 @@ func (s *Server) buildBucketPolicy(policyTemplate, label string) (string, error)
 func (s *Server) createBucketAndApplyPolicy(
 	ctx context.Context,
 	log *slog.Logger,
-	region, label string,
-	endpointType linodego.ObjectStorageEndpointType,
+	candidates []regionCandidate,
+	label string,
 	acl linodego.ObjectStorageACL,
 	cors ParamCORSValue,
-	cleanup ParamCleanupValue,
+	onDelete deletionParams,
 	policy string,
 ) (*cosi.DriverCreateBucketResponse, error) {
-	opts := linodego.ObjectStorageBucketCreateOptions{
-		Region: region,
-		Label:  label,
-		ACL:    acl,
-	}
-	if endpointType != "" {
-		opts.EndpointType = endpointType
+	bucket, log, err := s.createBucketInCandidateRegions(ctx, log, candidates, label, acl, cors)
+	if _, ok := status.FromError(err); ok && err != nil {
+		return nil, err
 	}
-	if cors.Bool() {
-		opts.CorsEnabled = cors.BoolP()
-	}
-
-	log.InfoContext(ctx, "Creating bucket")
-
-	bucket, err := s.client.CreateObjectStorageBucket(ctx, opts)
 	if err != nil {
-		log.ErrorContext(ctx, "Failed to create bucket", "error", err)
 		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to create bucket: %v", err))
 	}
 
-	log.InfoContext(ctx, "Bucket created")
-	if endpointType != "" && bucket.EndpointType == "" {
-		bucket.EndpointType = endpointType
+	if err := s.ensureOwnership(ctx, log, bucket, ownershipStamp); err != nil {
+		log.ErrorContext(ctx, "Failed to set bucket ownership marker", "error", err)
+
+		// The new bucket is deleted, as retries would refuse to adopt it without the marker.
+		// Buckets found to be owned by another cluster are left alone.
+		if status.Code(err) != codes.FailedPrecondition {
+			if derr := s.client.DeleteObjectStorageBucket(context.WithoutCancel(ctx), bucket.Region, bucket.Label); derr != nil {
+				log.ErrorContext(ctx, "Failed to delete bucket without ownership marker", "error", derr)
+			}
+		}
+
+		return nil, err
 	}
 
 	if policy != "" {
@@ -263,7 +463,7 @@ func (s *Server) createBucketAndApplyPolicy(
 		}
 	}
 	return &cosi.DriverCreateBucketResponse{
-		BucketId:   bucketID(bucket.Region, bucket.Label, cleanup),
+		BucketId:   s.bucketID(bucket, onDelete),
 		BucketInfo: bucketInfo(bucket.Region),
 	}, status.Error(codes.OK, "bucket created")
 }
@@ -276,7 +476,7 @@ func (s *Server) ensureExistingBucket(
 	endpointType linodego.ObjectStorageEndpointType,
 	acl linodego.ObjectStorageACL,
 	cors ParamCORSValue,
-	cleanup ParamCleanupValue,
+	onDelete deletionParams,
 	policy string,
 ) (*cosi.DriverCreateBucketResponse, error) {
 	access, err := s.client.GetObjectStorageBucketAccess(ctx, region, label)
@@ -297,6 +497,18 @@ func (s *Server) ensureExistingBucket(
 		return nil, status.Error(codes.AlreadyExists, "bucket exists with different parameters")
 	}
 
+	if err := s.ensureOwnership(ctx, log, bucket, ownershipAdopt); err != nil {
+		log.ErrorContext(ctx, "Failed to adopt bucket", "error", err)
+		return nil, err
+	}
+
+	if onDelete.softDeletePeriod > 0 {
+		if err := s.adoptSoftDeleted(ctx, log, bucket); err != nil {
+			log.ErrorContext(ctx, "Failed to adopt soft-deleted bucket", "error", err)
+			return nil, err
+		}
+	}
+
 	// Comparing policies is expensive and hard. If every other parameter is equal,
 	// we assume that bucket is valid, and apply policy only when one was provided.
 	if policy != "" {
@@ -308,11 +520,65 @@ func (s *Server) ensureExistingBucket(
 	log.InfoContext(ctx, "Bucket exists")
 
 	return &cosi.DriverCreateBucketResponse{
-		BucketId:   bucketID(region, label, cleanup),
+		BucketId:   s.bucketID(bucket, onDelete),
 		BucketInfo: bucketInfo(region),
 	}, status.Error(codes.OK, "bucket exists")
 }
 
+// bucketID returns the versioned bucket ID for the bucket.
+func (s *Server) bucketID(bucket *linodego.ObjectStorageBucket, onDelete deletionParams) string {
+	ref := bucketRef{
+		Region:           bucket.Region,
+		Label:            bucket.Label,
+		EndpointType:     bucket.EndpointType,
+		Cleanup:          onDelete.cleanup.Force(),
+		Account:          s.account,
+		SoftDeletePeriod: onDelete.softDeletePeriod,
+		MaxObjects:       onDelete.maxObjects,
+		MaxBytes:         onDelete.maxBytes,
+		ArchiveTo:        onDelete.archiveTo,
+	}
+	if len(s.signingKey) > 0 {
+		ref = ref.Signed(s.signingKey)
+	}
+
+	return ref.String()
+}
+
+// parseBucketID decodes the bucket ID and verifies that it belongs to the configured account.
+func (s *Server) parseBucketID(id string) (bucketRef, error) {
+	ref, err := parseBucketID(id)
+	if err != nil {
+		return bucketRef{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if ref.Account != "" && s.account != "" && ref.Account != s.account {
+		return bucketRef{}, status.Error(codes.FailedPrecondition,
+			fmt.Sprintf("bucket ID belongs to account %q, driver is configured for %q", ref.Account, s.account))
+	}
+
+	return ref, nil
+}
+
+// verifyBucketID ensures that the bucket ID was minted by this driver, or that the bucket is allowlisted.
+// The deletion policy of unsigned IDs of allowlisted buckets is not trusted, as anyone able to
+// create a Bucket object could set it, so allowlisted buckets are deleted without cleanup.
+func (s *Server) verifyBucketID(ref bucketRef) (bucketRef, error) {
+	if len(s.signingKey) == 0 || ref.Verify(s.signingKey) {
+		return ref, nil
+	}
+
+	if _, ok := s.allowlist[ref.Region+"/"+ref.Label]; ok {
+		return ref.withoutDeletionPolicy(), nil
+	}
+
+	if ref.signature == "" {
+		return bucketRef{}, status.Error(codes.PermissionDenied, ErrUnsignedBucketID.Error())
+	}
+
+	return bucketRef{}, status.Error(codes.PermissionDenied, ErrInvalidBucketIDSignature.Error())
+}
+
 func (s *Server) selectEndpointType(
 	ctx context.Context,
 	region string,
@@ -331,9 +597,9 @@ func (s *Server) selectEndpointType(
 		return "", nil
 	}
 
-	endpoints, err := s.client.ListObjectStorageEndpoints(ctx, nil)
+	endpoints, err := s.endpoints(ctx)
 	if err != nil {
-		return "", status.Error(codes.Internal, fmt.Sprintf("failed to list object storage endpoints: %v", err))
+		return "", status.Error(codes.Internal, err.Error())
 	}
 
 	endpoint, ok := selectEndpoint(endpoints, region, endpointTypes, params)
@@ -352,11 +618,11 @@ func (s *Server) selectEndpointType(
 }
 
 func selectEndpoint(
-	endpoints []linodego.ObjectStorageEndpoint,
+	endpoints []cache.Endpoint,
 	region string,
 	endpointTypes []linodego.ObjectStorageEndpointType,
 	params map[string]string,
-) (linodego.ObjectStorageEndpoint, bool) {
+) (cache.Endpoint, bool) {
 	if len(endpointTypes) == 0 {
 		return firstMatchingEndpoint(endpoints, region, params)
 	}
@@ -365,11 +631,11 @@ func selectEndpoint(
 }
 
 func firstPreferredEndpoint(
-	endpoints []linodego.ObjectStorageEndpoint,
+	endpoints []cache.Endpoint,
 	region string,
 	endpointTypes []linodego.ObjectStorageEndpointType,
 	params map[string]string,
-) (linodego.ObjectStorageEndpoint, bool) {
+) (cache.Endpoint, bool) {
 	for _, endpointType := range endpointTypes {
 		endpoint, ok := firstMatchingEndpointByType(endpoints, region, endpointType, params)
 		if ok {
@@ -377,15 +643,15 @@ func firstPreferredEndpoint(
 		}
 	}
 
-	return linodego.ObjectStorageEndpoint{}, false
+	return cache.Endpoint{}, false
 }
 
 func firstMatchingEndpointByType(
-	endpoints []linodego.ObjectStorageEndpoint,
+	endpoints []cache.Endpoint,
 	region string,
 	endpointType linodego.ObjectStorageEndpointType,
 	params map[string]string,
-) (linodego.ObjectStorageEndpoint, bool) {
+) (cache.Endpoint, bool) {
 	for _, endpoint := range endpoints {
 		if endpoint.EndpointType != endpointType ||
 			!endpointMatchesParams(endpoint, region, params) {
@@ -395,14 +661,14 @@ func firstMatchingEndpointByType(
 		return endpoint, true
 	}
 
-	return linodego.ObjectStorageEndpoint{}, false
+	return cache.Endpoint{}, false
 }
 
 func firstMatchingEndpoint(
-	endpoints []linodego.ObjectStorageEndpoint,
+	endpoints []cache.Endpoint,
 	region string,
 	params map[string]string,
-) (linodego.ObjectStorageEndpoint, bool) {
+) (cache.Endpoint, bool) {
 	for _, endpoint := range endpoints {
 		if !endpointMatchesParams(endpoint, region, params) {
 			continue
@@ -411,23 +677,44 @@ func firstMatchingEndpoint(
 		return endpoint, true
 	}
 
-	return linodego.ObjectStorageEndpoint{}, false
+	return cache.Endpoint{}, false
+}
+
+// endpoints returns the endpoint catalog. The catalog is answered from the cache, and
+// listed from the Linode API only when the cache is not populated yet.
+func (s *Server) endpoints(ctx context.Context) ([]cache.Endpoint, error) {
+	if s.cache != nil {
+		if endpoints := s.cache.Endpoints(); len(endpoints) > 0 {
+			return endpoints, nil
+		}
+	}
+
+	eps, err := s.client.ListObjectStorageEndpoints(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list object storage endpoints: %w", err)
+	}
+
+	endpoints := make([]cache.Endpoint, 0, len(eps))
+	for _, ep := range eps {
+		endpoints = append(endpoints, cache.NewEndpoint(ep))
+	}
+
+	return endpoints, nil
 }
 
 func (s *Server) endpointForType(
 	ctx context.Context,
 	region string,
 	endpointType linodego.ObjectStorageEndpointType,
-) (linodego.ObjectStorageEndpoint, error) {
-	endpoints, err := s.client.ListObjectStorageEndpoints(ctx, nil)
+) (cache.Endpoint, error) {
+	endpoints, err := s.endpoints(ctx)
 	if err != nil {
-		return linodego.ObjectStorageEndpoint{}, fmt.Errorf("list object storage endpoints: %w", err)
+		return cache.Endpoint{}, err
 	}
 
 	for _, endpoint := range endpoints {
 		if endpoint.Region != region ||
-			endpoint.S3Endpoint == nil ||
-			*endpoint.S3Endpoint == "" ||
+			endpoint.Hostname() == "" ||
 			endpoint.EndpointType != endpointType {
 			continue
 		}
@@ -435,7 +722,36 @@ func (s *Server) endpointForType(
 		return endpoint, nil
 	}
 
-	return linodego.ObjectStorageEndpoint{}, fmt.Errorf("object storage endpoint type %s is not available for region: %s", endpointType, region)
+	return cache.Endpoint{}, fmt.Errorf("object storage endpoint type %s is not available for region: %s", endpointType, region)
+}
+
+// endpointForRef resolves the S3 endpoint of the bucket. When the bucket ID carries the
+// endpoint type, the endpoint is resolved without looking up the bucket.
+func (s *Server) endpointForRef(
+	ctx context.Context,
+	ref bucketRef,
+) (string, linodego.ObjectStorageEndpointType, error) {
+	bucket := &linodego.ObjectStorageBucket{
+		Region:       ref.Region,
+		Label:        ref.Label,
+		EndpointType: ref.EndpointType,
+	}
+
+	if ref.EndpointType == "" {
+		var err error
+
+		bucket, err = s.client.GetObjectStorageBucket(ctx, ref.Region, ref.Label)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get bucket: %w", err)
+		}
+	}
+
+	endpoint, err := s.endpointForBucket(ctx, ref.Region, bucket)
+	if err != nil {
+		return "", "", err
+	}
+
+	return endpoint, bucket.EndpointType, nil
 }
 
 func (s *Server) endpointForBucket(ctx context.Context, region string, bucket *linodego.ObjectStorageBucket) (string, error) {
@@ -443,8 +759,10 @@ func (s *Server) endpointForBucket(ctx context.Context, region string, bucket *l
 		return bucket.S3Endpoint, nil
 	}
 
-	if endpoint, ok := s.cache.Get(cache.Key(region, bucket.EndpointType)); ok && endpoint != "" {
-		return endpoint, nil
+	if s.cache != nil {
+		if endpoint, ok := s.cache.Endpoint(region, bucket.EndpointType); ok {
+			return endpoint.Hostname(), nil
+		}
 	}
 
 	endpoint, err := s.endpointForType(ctx, region, bucket.EndpointType)
@@ -452,17 +770,15 @@ func (s *Server) endpointForBucket(ctx context.Context, region string, bucket *l
 		return "", err
 	}
 
-	return *endpoint.S3Endpoint, nil
+	return endpoint.Hostname(), nil
 }
 
-func endpointMatchesParams(endpoint linodego.ObjectStorageEndpoint, region string, params map[string]string) bool {
-	if endpoint.Region != region ||
-		endpoint.S3Endpoint == nil ||
-		*endpoint.S3Endpoint == "" {
+func endpointMatchesParams(endpoint cache.Endpoint, region string, params map[string]string) bool {
+	if endpoint.Region != region || endpoint.Hostname() == "" {
 		return false
 	}
 
-	if corsEnabled(params) && !endpointTypeSupportsCORS(endpoint.EndpointType) {
+	if corsEnabled(params) && !endpoint.CORS {
 		return false
 	}
 
@@ -471,7 +787,7 @@ func endpointMatchesParams(endpoint linodego.ObjectStorageEndpoint, region strin
 
 func validateExplicitEndpointTypeParams(params map[string]string) error {
 	endpointType := linodego.ObjectStorageEndpointType(params[ParamEndpointType])
-	if endpointType == "" || endpointTypeSupportsCORS(endpointType) || !corsEnabled(params) {
+	if endpointType == "" || cache.SupportsCORS(endpointType) || !corsEnabled(params) {
 		return nil
 	}
 
@@ -482,11 +798,6 @@ func corsEnabled(params map[string]string) bool {
 	return ParamCORSValue(params[ParamCORS]).Bool()
 }
 
-func endpointTypeSupportsCORS(endpointType linodego.ObjectStorageEndpointType) bool {
-	return endpointType != linodego.ObjectStorageEndpointE2 &&
-		endpointType != linodego.ObjectStorageEndpointE3
-}
-
 func bucketAccessCORSEnabled(access *linodego.ObjectStorageBucketAccess) bool {
 	return access.CorsEnabled != nil && *access.CorsEnabled
 }
@@ -515,10 +826,11 @@ func (s *Server) applyBucketPolicy(
 // NOTE: this call needs to be idempotent.
 // If the bucket has already been deleted, then no error should be returned.
 func (s *Server) DriverDeleteBucket(ctx context.Context, req *cosi.DriverDeleteBucketRequest) (*cosi.DriverDeleteBucketResponse, error) {
-	region, label, cleanup, err := parseBucketID(req.GetBucketId())
+	ref, err := s.parseBucketID(req.GetBucketId())
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, err
 	}
+	region, label := ref.Region, ref.Label
 
 	log := s.logAttr(
 		slog.String(KeyBucketID, req.GetBucketId()),
@@ -528,8 +840,51 @@ func (s *Server) DriverDeleteBucket(ctx context.Context, req *cosi.DriverDeleteB
 
 	log.InfoContext(ctx, "Bucket deletion initiated")
 
-	if cleanup {
-		s3cli, keyCleanup, err := s.s3ClientForBucket(ctx, region, label)
+	ref, err = s.resolveBucketRef(ctx, ref)
+	if status.Code(err) == codes.NotFound {
+		log.InfoContext(ctx, "Bucket already deleted")
+		return &cosi.DriverDeleteBucketResponse{}, status.Error(codes.OK, "bucket deleted")
+	}
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to resolve bucket", "error", err)
+		return nil, err
+	}
+	region, label = ref.Region, ref.Label
+
+	if ref.imported && !s.importedBucketDeletion {
+		log.InfoContext(ctx, "Imported bucket retained",
+			slog.String(KeyBucketRegion, region),
+			slog.String(KeyBucketLabel, label),
+		)
+		return &cosi.DriverDeleteBucketResponse{}, status.Error(codes.OK, "imported bucket retained")
+	}
+
+	ref, err = s.verifyBucketID(ref)
+	if err != nil {
+		log.ErrorContext(ctx, "Bucket ID verification failed", "error", err)
+		return nil, err
+	}
+
+	// Soft-deleted buckets may be restored until the end of the grace period.
+	if task, ok := s.queuedDeletion(region, label); ok && !inGracePeriod(task) {
+		return nil, deletionInProgress(task)
+	}
+
+	// Buckets with a soft delete period are kept rather than deleted right away without a queue.
+	softDelete := ref.SoftDeletePeriod > 0
+	if softDelete && s.deletions == nil {
+		log.ErrorContext(ctx, "Soft delete unavailable", "error", ErrSoftDeleteUnavailable)
+		return nil, status.Error(codes.FailedPrecondition, ErrSoftDeleteUnavailable.Error())
+	}
+
+	if ref.Cleanup || softDelete || s.clusterID != "" {
+		// Checking ownership only reads the bucket tags, pruning and soft deletion need write access.
+		permissions := linodeclient.KeyPermissionsReadOnly
+		if ref.Cleanup || softDelete {
+			permissions = linodeclient.KeyPermissionsReadWrite
+		}
+
+		s3cli, keyCleanup, err := s.s3ClientForBucket(ctx, ref, permissions)
 		if errors.Is(err, ErrNotFound) {
 			log.InfoContext(ctx, "Bucket already deleted")
 			return &cosi.DriverDeleteBucketResponse{}, status.Error(codes.OK, "bucket deleted")
@@ -540,13 +895,58 @@ func (s *Server) DriverDeleteBucket(ctx context.Context, req *cosi.DriverDeleteB
 		}
 		defer cleanupWithTimeout(ctx, log, keyCleanup)
 
-		if err := s3cli.Prune(ctx, region, label); err != nil && !s3.IsNotFound(err) {
-			return nil, status.Error(codes.Internal, fmt.Sprintf("failed to cleanup bucket: %v", err))
+		if err := s.checkOwnership(ctx, log, s3cli, region, label, ownershipCheck); err != nil {
+			return nil, err
+		}
+
+		if ref.Cleanup {
+			if err := s.checkDeletionLimits(ctx, log, ref); err != nil {
+				return nil, err
+			}
+		}
+
+		if softDelete {
+			return s.softDelete(ctx, log, s3cli, ref)
+		}
+
+		if ref.Cleanup && s.deletions != nil {
+			return s.queueDeletion(ctx, log, ref)
+		}
+
+		if ref.Cleanup && ref.ArchiveTo != "" {
+			if err := s.archiveBucket(ctx, log, s3cli, ref, nil); err != nil {
+				log.ErrorContext(ctx, "Failed to archive bucket", "error", err)
+
+				// Interrupted archives resume with the missing objects when the deletion is retried.
+				if errors.Is(err, s3.ErrCopyInterrupted) {
+					return nil, status.Error(codes.Unavailable, fmt.Sprintf("bucket archive interrupted: %v", err))
+				}
+
+				return nil, status.Error(codes.Internal, fmt.Sprintf("failed to archive bucket: %v", err))
+			}
+		}
+
+		if ref.Cleanup {
+			if err := s3cli.Prune(ctx, region, label, nil); err != nil && !s3.IsNotFound(err) {
+				log.ErrorContext(ctx, "Failed to cleanup bucket", "error", err)
+
+				// Interrupted cleanups resume with the remaining objects when the deletion is retried.
+				if errors.Is(err, s3.ErrPruneInterrupted) {
+					return nil, status.Error(codes.Unavailable, fmt.Sprintf("bucket cleanup interrupted: %v", err))
+				}
+
+				return nil, status.Error(codes.Internal, fmt.Sprintf("failed to cleanup bucket: %v", err))
+			}
 		}
 	}
 
 	err = s.client.DeleteObjectStorageBucket(ctx, region, label)
 	if err == nil || errors.Is(err, ErrNotFound) {
+		// Keys scoped to the deleted bucket are useless, even if a bucket with the same label is created later.
+		if err := s.keys.Expire(ctx, region, label); err != nil {
+			log.ErrorContext(ctx, "Failed to revoke bucket-scoped credentials", "error", err)
+		}
+
 		log.InfoContext(ctx, "Bucket deleted")
 		return &cosi.DriverDeleteBucketResponse{}, status.Error(codes.OK, "bucket deleted")
 	}
@@ -563,10 +963,16 @@ func (s *Server) DriverDeleteBucket(ctx context.Context, req *cosi.DriverDeleteB
 // The account_id returned in the response will be used as the unique identifier for deleting this access when calling DriverRevokeBucketAccess.
 // The returned secret does not need to be the same each call to achieve idempotency.
 func (s *Server) DriverGrantBucketAccess(ctx context.Context, req *cosi.DriverGrantBucketAccessRequest) (*cosi.DriverGrantBucketAccessResponse, error) {
-	region, label, _, err := parseBucketID(req.GetBucketId())
+	ref, err := s.parseBucketID(req.GetBucketId())
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, err
 	}
+
+	ref, err = s.resolveBucketRef(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	region, label := ref.Region, ref.Label
 	name := req.GetName()
 	auth := req.GetAuthenticationType()
 	perms := ParamPermissionsValue(req.GetParameters()[ParamPermissions])
@@ -584,6 +990,17 @@ func (s *Server) DriverGrantBucketAccess(ctx context.Context, req *cosi.DriverGr
 		slog.Any(KeyBucketAccessPermissions, perms),
 	).WithGroup("DriverGrantBucketAccess")
 
+	ref, err = s.verifyBucketID(ref)
+	if err != nil {
+		log.ErrorContext(ctx, "Bucket ID verification failed", "error", err)
+		return nil, err
+	}
+
+	if _, ok := s.queuedDeletion(region, label); ok {
+		log.ErrorContext(ctx, "Bucket is being deleted")
+		return nil, status.Error(codes.FailedPrecondition, ErrBucketDeletionInProgress.Error())
+	}
+
 	if auth != cosi.AuthenticationType_Key {
 		log.ErrorContext(ctx, "Unsupported authentication type")
 
@@ -594,18 +1011,23 @@ func (s *Server) DriverGrantBucketAccess(ctx context.Context, req *cosi.DriverGr
 		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("%v: %s", ErrUnknownPermsissions, perms))
 	}
 
-	bucket, err := s.client.GetObjectStorageBucket(ctx, region, label)
+	ttl, err := parseKeyTTL(req.GetParameters())
 	if err != nil {
-		log.ErrorContext(ctx, "Failed to get bucket", "error", err)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to get bucket: %v", err))
+		log.ErrorContext(ctx, "Invalid key TTL", "error", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	endpoint, err := s.endpointForBucket(ctx, region, bucket)
+	endpoint, endpointType, err := s.endpointForRef(ctx, ref)
 	if err != nil {
 		log.ErrorContext(ctx, "Failed to select endpoint", "error", err)
 		return nil, status.Error(codes.Internal, err.Error())
 	}
-	log = log.With(slog.String(KeyBucketEndpointType, string(bucket.EndpointType)))
+	log = log.With(slog.String(KeyBucketEndpointType, string(endpointType)))
+
+	if err := s.checkFrozen(ctx, log, ref); err != nil {
+		log.ErrorContext(ctx, "Bucket is frozen", "error", err)
+		return nil, err
+	}
 
 	opts := linodego.ObjectStorageKeyCreateOptions{
 		Label: name,
@@ -618,6 +1040,15 @@ func (s *Server) DriverGrantBucketAccess(ctx context.Context, req *cosi.DriverGr
 		},
 	}
 
+	// The expiry is recorded in the label, so that the key is deleted by the reaper of any
+	// replica, even after a restart.
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl).UTC().Truncate(time.Second)
+		opts.Label = linodeclient.KeyLabelWithExpiry(name, expiresAt)
+		log = log.With(slog.Time(KeyBucketAccessExpiresAt, expiresAt))
+	}
+
 	log.InfoContext(ctx, "Creating object storage key")
 
 	key, err := s.client.CreateObjectStorageKey(ctx, opts)
@@ -628,9 +1059,14 @@ func (s *Server) DriverGrantBucketAccess(ctx context.Context, req *cosi.DriverGr
 
 	log.InfoContext(ctx, "Object storage key created")
 
+	creds := credentials(region, endpoint, label, key.AccessKey, key.SecretKey)
+	if !expiresAt.IsZero() {
+		creds[S3].Secrets[S3SecretExpiresAt] = expiresAt.Format(time.RFC3339)
+	}
+
 	return &cosi.DriverGrantBucketAccessResponse{
 		AccountId:   fmt.Sprintf("%d", key.ID),
-		Credentials: credentials(region, endpoint, label, key.AccessKey, key.SecretKey),
+		Credentials: creds,
 	}, status.Error(codes.OK, "bucket access granted")
 }
 
@@ -638,10 +1074,11 @@ func (s *Server) DriverGrantBucketAccess(ctx context.Context, req *cosi.DriverGr
 //
 // NOTE: this call needs to be idempotent.
 func (s *Server) DriverRevokeBucketAccess(ctx context.Context, req *cosi.DriverRevokeBucketAccessRequest) (*cosi.DriverRevokeBucketAccessResponse, error) {
-	region, label, _, err := parseBucketID(req.GetBucketId())
+	ref, err := s.parseBucketID(req.GetBucketId())
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, err
 	}
+	region, label := ref.Region, ref.Label
 	id, err := strconv.Atoi(req.GetAccountId())
 
 	log := s.logAttr(
@@ -657,6 +1094,15 @@ func (s *Server) DriverRevokeBucketAccess(ctx context.Context, req *cosi.DriverR
 		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("account id is invalid: %v", err))
 	}
 
+	// Keys replaced by a rotation are deleted first, so that retries still find their label.
+	if s.keyRotation {
+		if err := s.deleteRotatedKeys(ctx, log, id); err != nil {
+			log.ErrorContext(ctx, "Failed to delete rotated keys", "error", err)
+			return nil, status.Error(codes.Internal, fmt.Sprintf("failed to delete rotated keys: %v", err))
+		}
+	}
+
+	// Time-limited keys deleted by the reaper once they expired are not found.
 	err = s.client.DeleteObjectStorageKey(ctx, id)
 	if err == nil || errors.Is(err, ErrNotFound) {
 		log.InfoContext(ctx, "Key deleted")
@@ -667,3 +1113,43 @@ func (s *Server) DriverRevokeBucketAccess(ctx context.Context, req *cosi.DriverR
 
 	return nil, status.Error(codes.Internal, fmt.Sprintf("failed to delete key: %v", err))
 }
+
+// deleteRotatedKeys deletes the other keys sharing the label of the granted key id. Rotated
+// keys keep the label of the key they replace, which stays valid during the rotation overlap,
+// see pkg/rotation. Only limited keys granted for BucketAccess objects are considered.
+func (s *Server) deleteRotatedKeys(ctx context.Context, log *slog.Logger, id int) error {
+	key, err := s.client.GetObjectStorageKey(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get key: %w", err)
+	}
+
+	if _, _, ok := linodeclient.KeyExpiry(key.Label); ok || !key.Limited ||
+		!strings.HasPrefix(key.Label, linodeclient.GrantKeyLabelPrefix) {
+		return nil
+	}
+
+	keys, err := s.client.ListObjectStorageKeys(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to list keys: %w", err)
+	}
+
+	var errs error
+
+	for _, other := range keys {
+		if other.ID == id || other.Label != key.Label || !other.Limited {
+			continue
+		}
+
+		if err := s.client.DeleteObjectStorageKey(ctx, other.ID); err != nil && !errors.Is(err, ErrNotFound) {
+			errs = errors.Join(errs, fmt.Errorf("failed to delete key %d: %w", other.ID, err))
+			continue
+		}
+
+		log.InfoContext(ctx, "Rotated key deleted", slog.Int("key_id", other.ID))
+	}
+
+	return errs
+}
@@ -18,9 +18,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/linode/linodego/v2"
@@ -29,6 +33,7 @@ import (
 	"google.golang.org/grpc/status"
 	cosi "sigs.k8s.io/container-object-storage-interface-spec"
 
+	"github.com/linode/linode-cosi-driver/pkg/deletion"
 	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
 	"github.com/linode/linode-cosi-driver/pkg/linodeclient/cache"
 	"github.com/linode/linode-cosi-driver/pkg/s3"
@@ -68,6 +73,12 @@ const (
 	testRegion           = "test-region"
 	testBucketName       = "test-bucket"
 	testBucketID         = testRegion + "/" + testBucketName
+	testBucketIDV2       = "v2:label=" + testBucketName + "&region=" + testRegion + "&type=E0"
+	testBucketIDV2E1     = "v2:label=" + testBucketName + "&region=" + testRegion + "&type=E1"
+	testBucketIDV2Force  = "v2:cleanup=force&label=" + testBucketName + "&region=" + testRegion + "&type=E0"
+	testImportURI        = "s3://" + testBucketName + "?region=" + testRegion
+	testImportHostname   = testBucketName + ".test-region-1.linodeobjects.com"
+	testImportClusterID  = "test-region-1/" + testBucketName
 	testBucketAccessName = "test-bucket-access"
 	testBucketAccessID   = "0"
 	testAccessKey        = "TEST_ACCESS_KEY"
@@ -148,6 +159,17 @@ func credentialsWithEndpoint(endpoint string) map[string]*cosi.CredentialDetails
 	}
 }
 
+// bucketScopedKey matches keys limited to a single bucket. Every key the driver creates
+// must be limited to a single bucket.
+func bucketScopedKey() gomock.Matcher {
+	return gomock.Cond(func(opts linodego.ObjectStorageKeyCreateOptions) bool {
+		return len(opts.Regions) == 0 &&
+			len(opts.BucketAccess) == 1 &&
+			opts.BucketAccess[0].Region == testRegion &&
+			opts.BucketAccess[0].BucketName != ""
+	})
+}
+
 func expectGetBucket(t *testing.T, mockLinode *mock.MockLinodeClient, endpointType linodego.ObjectStorageEndpointType) {
 	t.Helper()
 
@@ -248,7 +270,7 @@ func TestDriverCreateBucket(t *testing.T) {
 				},
 			},
 			expectedResponse: &cosi.DriverCreateBucketResponse{
-				BucketId:   testBucketID + "/force",
+				BucketId:   testBucketIDV2Force,
 				BucketInfo: defaultBucketInfo,
 			},
 			setupMockS3: func(t *testing.T) s3.Client {
@@ -296,7 +318,7 @@ func TestDriverCreateBucket(t *testing.T) {
 				},
 			},
 			expectedResponse: &cosi.DriverCreateBucketResponse{
-				BucketId:   testBucketID,
+				BucketId:   testBucketIDV2E1,
 				BucketInfo: defaultBucketInfo,
 			},
 			setupMockS3: func(t *testing.T) s3.Client {
@@ -346,7 +368,7 @@ func TestDriverCreateBucket(t *testing.T) {
 				},
 			},
 			expectedResponse: &cosi.DriverCreateBucketResponse{
-				BucketId:   testBucketID,
+				BucketId:   testBucketIDV2E1,
 				BucketInfo: defaultBucketInfo,
 			},
 			setupMockS3: func(t *testing.T) s3.Client {
@@ -432,7 +454,7 @@ func TestDriverCreateBucket(t *testing.T) {
 				Parameters: defaultBucketParameters,
 			},
 			expectedResponse: &cosi.DriverCreateBucketResponse{
-				BucketId:   testBucketID,
+				BucketId:   testBucketIDV2,
 				BucketInfo: defaultBucketInfo,
 			},
 			setupMockS3: func(t *testing.T) s3.Client {
@@ -474,7 +496,7 @@ func TestDriverCreateBucket(t *testing.T) {
 				},
 			},
 			expectedResponse: &cosi.DriverCreateBucketResponse{
-				BucketId:   testBucketID,
+				BucketId:   testBucketIDV2,
 				BucketInfo: defaultBucketInfo,
 			},
 			setupMockS3: func(t *testing.T) s3.Client {
@@ -530,7 +552,7 @@ func TestDriverCreateBucket(t *testing.T) {
 				},
 			},
 			expectedResponse: &cosi.DriverCreateBucketResponse{
-				BucketId:   testBucketID,
+				BucketId:   testBucketIDV2,
 				BucketInfo: defaultBucketInfo,
 			},
 			setupMockS3: func(t *testing.T) s3.Client {
@@ -795,7 +817,7 @@ func TestDriverCreateBucketWithPolicyTemplate(t *testing.T) {
 	}
 
 	expected := &cosi.DriverCreateBucketResponse{
-		BucketId:   testBucketID,
+		BucketId:   testBucketIDV2,
 		BucketInfo: defaultBucketInfo,
 	}
 
@@ -868,7 +890,7 @@ func TestDriverDeleteBucket(t *testing.T) {
 				ctrl := gomock.NewController(t)
 				mockS3 := mock.NewMockS3Client(ctrl)
 				mockS3.EXPECT().
-					Prune(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Prune(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName), gomock.Any()).
 					Return(nil).
 					Times(2)
 				return mockS3
@@ -890,6 +912,33 @@ func TestDriverDeleteBucket(t *testing.T) {
 				return mockLinode
 			},
 		},
+		{
+			testName: "retains bucket when cleanup is interrupted",
+			request: &cosi.DriverDeleteBucketRequest{
+				BucketId: testBucketID + "/force",
+			},
+			expectedCode: grpccodes.Unavailable,
+			setupMockS3: func(t *testing.T) s3.Client {
+				t.Helper()
+				ctrl := gomock.NewController(t)
+				mockS3 := mock.NewMockS3Client(ctrl)
+				mockS3.EXPECT().
+					Prune(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName), gomock.Any()).
+					Return(fmt.Errorf("%w after removing 10 objects: %w", s3.ErrPruneInterrupted, context.DeadlineExceeded)).
+					Times(2)
+				return mockS3
+			},
+			setupMockLinode: func(t *testing.T) linodeclient.Client {
+				t.Helper()
+				ctrl := gomock.NewController(t)
+				mockLinode := mock.NewMockLinodeClient(ctrl)
+				mockLinode.EXPECT().
+					ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+					Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+					AnyTimes()
+				return mockLinode
+			},
+		},
 		{
 			testName: "rejects malformed bucket ID",
 			request: &cosi.DriverDeleteBucketRequest{
@@ -936,6 +985,35 @@ func TestDriverDeleteBucket(t *testing.T) {
 				return mockLinode
 			},
 		},
+		{
+			testName: "versioned bucket ID skips bucket lookup before cleanup",
+			request: &cosi.DriverDeleteBucketRequest{
+				BucketId: testBucketIDV2Force,
+			},
+			setupMockS3: func(t *testing.T) s3.Client {
+				t.Helper()
+				return nil
+			},
+			setupMockLinode: func(t *testing.T) linodeclient.Client {
+				t.Helper()
+				ctrl := gomock.NewController(t)
+				mockLinode := mock.NewMockLinodeClient(ctrl)
+				// Key creation fails for deleted buckets, only then the bucket is looked up.
+				mockLinode.EXPECT().
+					CreateObjectStorageKey(gomock.Any(), bucketScopedKey()).
+					Return(nil, linodego.Error{Code: http.StatusBadRequest}).
+					Times(2)
+				mockLinode.EXPECT().
+					GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(nil, provisioner.ErrNotFound).
+					Times(2)
+				mockLinode.EXPECT().
+					ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+					Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+					AnyTimes()
+				return mockLinode
+			},
+		},
 	} {
 		tc := tc
 
@@ -995,7 +1073,7 @@ func TestDriverGrantBucketAccess(t *testing.T) {
 				t.Helper()
 				ctrl := gomock.NewController(t)
 				mockS3 := mock.NewMockS3Client(ctrl)
-				// No S3 calls expected - GrantBucketAccess only uses Linode API
+				expectNotFrozen(mockS3)
 				return mockS3
 			},
 			setupMockLinode: func(t *testing.T) linodeclient.Client {
@@ -1005,7 +1083,7 @@ func TestDriverGrantBucketAccess(t *testing.T) {
 				expectGetBucket(t, mockLinode, linodego.ObjectStorageEndpointE0)
 				// Both calls: CreateObjectStorageKey creates the key
 				mockLinode.EXPECT().
-					CreateObjectStorageKey(gomock.Any(), gomock.Any()).
+					CreateObjectStorageKey(gomock.Any(), bucketScopedKey()).
 					Return(&linodego.ObjectStorageKey{
 						ID:        0,
 						AccessKey: testAccessKey,
@@ -1020,6 +1098,126 @@ func TestDriverGrantBucketAccess(t *testing.T) {
 				return mockLinode
 			},
 		},
+		{
+			testName: "imported bucket URI",
+			request: &cosi.DriverGrantBucketAccessRequest{
+				BucketId:           testImportURI,
+				Name:               testBucketAccessName,
+				AuthenticationType: cosi.AuthenticationType_Key,
+				Parameters:         defaultBucketAccessParameters,
+			},
+			expectedResponse: &cosi.DriverGrantBucketAccessResponse{
+				AccountId:   testBucketAccessID,
+				Credentials: defaultCredentials,
+			},
+			setupMockS3: func(t *testing.T) s3.Client {
+				t.Helper()
+				ctrl := gomock.NewController(t)
+				mockS3 := mock.NewMockS3Client(ctrl)
+				expectNotFrozen(mockS3)
+				return mockS3
+			},
+			setupMockLinode: func(t *testing.T) linodeclient.Client {
+				t.Helper()
+				ctrl := gomock.NewController(t)
+				mockLinode := mock.NewMockLinodeClient(ctrl)
+				// Imported buckets are looked up to verify they exist
+				expectGetBucket(t, mockLinode, linodego.ObjectStorageEndpointE0)
+				mockLinode.EXPECT().
+					CreateObjectStorageKey(gomock.Any(), bucketScopedKey()).
+					Return(&linodego.ObjectStorageKey{
+						ID:        0,
+						AccessKey: testAccessKey,
+						SecretKey: testSecretKey,
+					}, nil).
+					Times(2)
+				mockLinode.EXPECT().
+					ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+					Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+					AnyTimes()
+				return mockLinode
+			},
+		},
+		{
+			testName: "imported bucket hostname",
+			request: &cosi.DriverGrantBucketAccessRequest{
+				BucketId:           testImportHostname,
+				Name:               testBucketAccessName,
+				AuthenticationType: cosi.AuthenticationType_Key,
+				Parameters:         defaultBucketAccessParameters,
+			},
+			expectedResponse: &cosi.DriverGrantBucketAccessResponse{
+				AccountId:   testBucketAccessID,
+				Credentials: defaultCredentials,
+			},
+			setupMockS3: func(t *testing.T) s3.Client {
+				t.Helper()
+				ctrl := gomock.NewController(t)
+				mockS3 := mock.NewMockS3Client(ctrl)
+				expectNotFrozen(mockS3)
+				return mockS3
+			},
+			setupMockLinode: func(t *testing.T) linodeclient.Client {
+				t.Helper()
+				ctrl := gomock.NewController(t)
+				mockLinode := mock.NewMockLinodeClient(ctrl)
+				// Imported buckets are looked up to verify they exist
+				expectGetBucket(t, mockLinode, linodego.ObjectStorageEndpointE0)
+				mockLinode.EXPECT().
+					CreateObjectStorageKey(gomock.Any(), bucketScopedKey()).
+					Return(&linodego.ObjectStorageKey{
+						ID:        0,
+						AccessKey: testAccessKey,
+						SecretKey: testSecretKey,
+					}, nil).
+					Times(2)
+				mockLinode.EXPECT().
+					ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+					Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+					AnyTimes()
+				return mockLinode
+			},
+		},
+		{
+			testName: "imported bucket with cluster ID",
+			request: &cosi.DriverGrantBucketAccessRequest{
+				BucketId:           testImportClusterID,
+				Name:               testBucketAccessName,
+				AuthenticationType: cosi.AuthenticationType_Key,
+				Parameters:         defaultBucketAccessParameters,
+			},
+			expectedResponse: &cosi.DriverGrantBucketAccessResponse{
+				AccountId:   testBucketAccessID,
+				Credentials: defaultCredentials,
+			},
+			setupMockS3: func(t *testing.T) s3.Client {
+				t.Helper()
+				ctrl := gomock.NewController(t)
+				mockS3 := mock.NewMockS3Client(ctrl)
+				expectNotFrozen(mockS3)
+				return mockS3
+			},
+			setupMockLinode: func(t *testing.T) linodeclient.Client {
+				t.Helper()
+				ctrl := gomock.NewController(t)
+				mockLinode := mock.NewMockLinodeClient(ctrl)
+				// Imported buckets are looked up to verify they exist
+				expectGetBucket(t, mockLinode, linodego.ObjectStorageEndpointE0)
+				mockLinode.EXPECT().
+					CreateObjectStorageKey(gomock.Any(), bucketScopedKey()).
+					Return(&linodego.ObjectStorageKey{
+						ID:        0,
+						AccessKey: testAccessKey,
+						SecretKey: testSecretKey,
+					}, nil).
+					Times(2)
+				mockLinode.EXPECT().
+					ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+					Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+					AnyTimes()
+				return mockLinode
+			},
+		},
 		{
 			testName: "uses bucket endpoint type despite access endpoint type parameter",
 			request: &cosi.DriverGrantBucketAccessRequest{
@@ -1039,7 +1237,7 @@ func TestDriverGrantBucketAccess(t *testing.T) {
 				t.Helper()
 				ctrl := gomock.NewController(t)
 				mockS3 := mock.NewMockS3Client(ctrl)
-				// No S3 calls expected - GrantBucketAccess only uses Linode API
+				expectNotFrozen(mockS3)
 				return mockS3
 			},
 			setupMockLinode: func(t *testing.T) linodeclient.Client {
@@ -1048,7 +1246,7 @@ func TestDriverGrantBucketAccess(t *testing.T) {
 				mockLinode := mock.NewMockLinodeClient(ctrl)
 				expectGetBucket(t, mockLinode, linodego.ObjectStorageEndpointE0)
 				mockLinode.EXPECT().
-					CreateObjectStorageKey(gomock.Any(), gomock.Any()).
+					CreateObjectStorageKey(gomock.Any(), bucketScopedKey()).
 					Return(&linodego.ObjectStorageKey{
 						ID:        0,
 						AccessKey: testAccessKey,
@@ -1081,7 +1279,7 @@ func TestDriverGrantBucketAccess(t *testing.T) {
 				t.Helper()
 				ctrl := gomock.NewController(t)
 				mockS3 := mock.NewMockS3Client(ctrl)
-				// No S3 calls expected - GrantBucketAccess only uses Linode API
+				expectNotFrozen(mockS3)
 				return mockS3
 			},
 			setupMockLinode: func(t *testing.T) linodeclient.Client {
@@ -1090,7 +1288,7 @@ func TestDriverGrantBucketAccess(t *testing.T) {
 				mockLinode := mock.NewMockLinodeClient(ctrl)
 				expectGetBucket(t, mockLinode, linodego.ObjectStorageEndpointE1)
 				mockLinode.EXPECT().
-					CreateObjectStorageKey(gomock.Any(), gomock.Any()).
+					CreateObjectStorageKey(gomock.Any(), bucketScopedKey()).
 					Return(&linodego.ObjectStorageKey{
 						ID:        0,
 						AccessKey: testAccessKey,
@@ -1123,7 +1321,7 @@ func TestDriverGrantBucketAccess(t *testing.T) {
 				t.Helper()
 				ctrl := gomock.NewController(t)
 				mockS3 := mock.NewMockS3Client(ctrl)
-				// No S3 calls expected - endpoint selection fails first
+				expectNotFrozen(mockS3)
 				return mockS3
 			},
 			setupMockLinode: func(t *testing.T) linodeclient.Client {
@@ -1132,7 +1330,7 @@ func TestDriverGrantBucketAccess(t *testing.T) {
 				mockLinode := mock.NewMockLinodeClient(ctrl)
 				expectGetBucket(t, mockLinode, linodego.ObjectStorageEndpointE0)
 				mockLinode.EXPECT().
-					CreateObjectStorageKey(gomock.Any(), gomock.Any()).
+					CreateObjectStorageKey(gomock.Any(), bucketScopedKey()).
 					Return(&linodego.ObjectStorageKey{
 						ID:        0,
 						AccessKey: testAccessKey,
@@ -1162,7 +1360,7 @@ func TestDriverGrantBucketAccess(t *testing.T) {
 				t.Helper()
 				ctrl := gomock.NewController(t)
 				mockS3 := mock.NewMockS3Client(ctrl)
-				// No S3 calls expected - GrantBucketAccess only uses Linode API
+				expectNotFrozen(mockS3)
 				return mockS3
 			},
 			setupMockLinode: func(t *testing.T) linodeclient.Client {
@@ -1171,7 +1369,7 @@ func TestDriverGrantBucketAccess(t *testing.T) {
 				mockLinode := mock.NewMockLinodeClient(ctrl)
 				expectGetBucket(t, mockLinode, linodego.ObjectStorageEndpointE1)
 				mockLinode.EXPECT().
-					CreateObjectStorageKey(gomock.Any(), gomock.Any()).
+					CreateObjectStorageKey(gomock.Any(), bucketScopedKey()).
 					Return(&linodego.ObjectStorageKey{
 						ID:        0,
 						AccessKey: testAccessKey,
@@ -1185,6 +1383,45 @@ func TestDriverGrantBucketAccess(t *testing.T) {
 				return mockLinode
 			},
 		},
+		{
+			testName: "versioned bucket ID skips bucket lookup",
+			request: &cosi.DriverGrantBucketAccessRequest{
+				BucketId:           testBucketIDV2E1,
+				Name:               testBucketAccessName,
+				AuthenticationType: cosi.AuthenticationType_Key,
+				Parameters:         defaultBucketAccessParameters,
+			},
+			expectedResponse: &cosi.DriverGrantBucketAccessResponse{
+				AccountId:   testBucketAccessID,
+				Credentials: credentialsWithEndpoint(testEndpointE1),
+			},
+			setupMockS3: func(t *testing.T) s3.Client {
+				t.Helper()
+				ctrl := gomock.NewController(t)
+				mockS3 := mock.NewMockS3Client(ctrl)
+				expectNotFrozen(mockS3)
+				return mockS3
+			},
+			setupMockLinode: func(t *testing.T) linodeclient.Client {
+				t.Helper()
+				ctrl := gomock.NewController(t)
+				mockLinode := mock.NewMockLinodeClient(ctrl)
+				// No GetObjectStorageBucket call expected - endpoint type is encoded in the bucket ID
+				mockLinode.EXPECT().
+					CreateObjectStorageKey(gomock.Any(), bucketScopedKey()).
+					Return(&linodego.ObjectStorageKey{
+						ID:        0,
+						AccessKey: testAccessKey,
+						SecretKey: testSecretKey,
+					}, nil).
+					Times(2)
+				mockLinode.EXPECT().
+					ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+					Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint, defaultLinodegoEndpointE1}, nil).
+					AnyTimes()
+				return mockLinode
+			},
+		},
 		{
 			testName: "IAM Auth",
 			request: &cosi.DriverGrantBucketAccessRequest{
@@ -1293,12 +1530,15 @@ func TestDriverGrantBucketAccess(t *testing.T) {
 func TestDriverRevokeBucketAccess(t *testing.T) {
 	t.Parallel()
 
+	grantedKey := linodego.ObjectStorageKey{ID: 0, Label: "ba-test", Limited: true}
+
 	for _, tc := range []struct {
 		testName        string
 		request         *cosi.DriverRevokeBucketAccessRequest
 		expectedError   error
 		setupMockS3     func(*testing.T) s3.Client
 		setupMockLinode func(*testing.T) linodeclient.Client
+		options         []provisioner.Option
 	}{
 		{
 			testName: "base",
@@ -1317,7 +1557,7 @@ func TestDriverRevokeBucketAccess(t *testing.T) {
 				t.Helper()
 				ctrl := gomock.NewController(t)
 				mockLinode := mock.NewMockLinodeClient(ctrl)
-				// Both calls: DeleteObjectStorageKey deletes the key
+				// Both calls: without rotation, only DeleteObjectStorageKey is called
 				mockLinode.EXPECT().
 					DeleteObjectStorageKey(gomock.Any(), gomock.Eq(0)).
 					Return(nil).
@@ -1330,34 +1570,1127 @@ func TestDriverRevokeBucketAccess(t *testing.T) {
 				return mockLinode
 			},
 		},
-	} {
-		tc := tc
-
-		t.Run(tc.testName, func(t *testing.T) {
-			t.Parallel()
-
-			ctx, cancel := context.WithCancel(t.Context())
-			defer cancel()
-
-			linodeCli := tc.setupMockLinode(t)
-			epc := cache.New(discardLog, linodeCli, 0)
-			if err := epc.Refresh(ctx); err != nil {
-				t.Fatalf("failed to refresh cache: %v", err)
-			}
-
-			s3cli := tc.setupMockS3(t)
-
-			srv, err := provisioner.New(nil, linodeCli, epc, s3cli, true)
-			if err != nil {
-				t.Fatalf("failed to create provisioner server: %v", err)
-			}
-
-			for i := 0; i < 2; i++ { // run twice to check idempotency
-				_, err = srv.DriverRevokeBucketAccess(ctx, tc.request)
-				if !errors.Is(err, tc.expectedError) {
-					t.Errorf("call %d: expected error: %q, but got: %q", i, tc.expectedError, err)
-				}
-			}
-		})
+		{
+			testName: "rotation without rotated keys",
+			request: &cosi.DriverRevokeBucketAccessRequest{
+				BucketId:  testBucketID,
+				AccountId: testBucketAccessID,
+			},
+			setupMockS3: func(t *testing.T) s3.Client {
+				t.Helper()
+				ctrl := gomock.NewController(t)
+				return mock.NewMockS3Client(ctrl)
+			},
+			setupMockLinode: func(t *testing.T) linodeclient.Client {
+				t.Helper()
+				ctrl := gomock.NewController(t)
+				mockLinode := mock.NewMockLinodeClient(ctrl)
+				// Both calls: the key has no rotated keys, DeleteObjectStorageKey deletes the key
+				mockLinode.EXPECT().
+					GetObjectStorageKey(gomock.Any(), gomock.Eq(0)).
+					Return(&grantedKey, nil).
+					Times(2)
+				mockLinode.EXPECT().
+					ListObjectStorageKeys(gomock.Any(), gomock.Any()).
+					Return([]linodego.ObjectStorageKey{grantedKey}, nil).
+					Times(2)
+				mockLinode.EXPECT().
+					DeleteObjectStorageKey(gomock.Any(), gomock.Eq(0)).
+					Return(nil).
+					Times(2)
+				mockLinode.EXPECT().
+					ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+					Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+					AnyTimes()
+				return mockLinode
+			},
+			options: []provisioner.Option{provisioner.WithKeyRotation(true)},
+		},
+		{
+			testName: "rotation overlap",
+			request: &cosi.DriverRevokeBucketAccessRequest{
+				BucketId:  testBucketID,
+				AccountId: testBucketAccessID,
+			},
+			setupMockS3: func(t *testing.T) s3.Client {
+				t.Helper()
+				ctrl := gomock.NewController(t)
+				return mock.NewMockS3Client(ctrl)
+			},
+			setupMockLinode: func(t *testing.T) linodeclient.Client {
+				t.Helper()
+				ctrl := gomock.NewController(t)
+				mockLinode := mock.NewMockLinodeClient(ctrl)
+				// Both calls: the key replaced by the rotation is still valid, and deleted with the granted key
+				mockLinode.EXPECT().
+					GetObjectStorageKey(gomock.Any(), gomock.Eq(0)).
+					Return(&grantedKey, nil).
+					Times(2)
+				mockLinode.EXPECT().
+					ListObjectStorageKeys(gomock.Any(), gomock.Any()).
+					Return([]linodego.ObjectStorageKey{
+						grantedKey,
+						{ID: 7, Label: grantedKey.Label, Limited: true},
+						{ID: 8, Label: "ba-other", Limited: true},
+						{ID: 9, Label: grantedKey.Label},
+					}, nil).
+					Times(2)
+				mockLinode.EXPECT().
+					DeleteObjectStorageKey(gomock.Any(), gomock.Eq(7)).
+					Return(nil).
+					Times(2)
+				mockLinode.EXPECT().
+					DeleteObjectStorageKey(gomock.Any(), gomock.Eq(0)).
+					Return(nil).
+					Times(2)
+				mockLinode.EXPECT().
+					ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+					Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+					AnyTimes()
+				return mockLinode
+			},
+			options: []provisioner.Option{provisioner.WithKeyRotation(true)},
+		},
+		{
+			testName: "expired key",
+			request: &cosi.DriverRevokeBucketAccessRequest{
+				BucketId:  testBucketID,
+				AccountId: testBucketAccessID,
+			},
+			setupMockS3: func(t *testing.T) s3.Client {
+				t.Helper()
+				ctrl := gomock.NewController(t)
+				return mock.NewMockS3Client(ctrl)
+			},
+			setupMockLinode: func(t *testing.T) linodeclient.Client {
+				t.Helper()
+				ctrl := gomock.NewController(t)
+				mockLinode := mock.NewMockLinodeClient(ctrl)
+				// Both calls: the key was already deleted by the reaper once it expired
+				mockLinode.EXPECT().
+					GetObjectStorageKey(gomock.Any(), gomock.Eq(0)).
+					Return(nil, &linodego.Error{Code: http.StatusNotFound}).
+					Times(2)
+				mockLinode.EXPECT().
+					DeleteObjectStorageKey(gomock.Any(), gomock.Eq(0)).
+					Return(&linodego.Error{Code: http.StatusNotFound}).
+					Times(2)
+				mockLinode.EXPECT().
+					ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+					Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+					AnyTimes()
+				return mockLinode
+			},
+			options: []provisioner.Option{provisioner.WithKeyRotation(true)},
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.testName, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+
+			linodeCli := tc.setupMockLinode(t)
+			epc := cache.New(discardLog, linodeCli, 0)
+			if err := epc.Refresh(ctx); err != nil {
+				t.Fatalf("failed to refresh cache: %v", err)
+			}
+
+			s3cli := tc.setupMockS3(t)
+
+			srv, err := provisioner.New(nil, linodeCli, epc, s3cli, true, tc.options...)
+			if err != nil {
+				t.Fatalf("failed to create provisioner server: %v", err)
+			}
+
+			for i := 0; i < 2; i++ { // run twice to check idempotency
+				_, err = srv.DriverRevokeBucketAccess(ctx, tc.request)
+				if !errors.Is(err, tc.expectedError) {
+					t.Errorf("call %d: expected error: %q, but got: %q", i, tc.expectedError, err)
+				}
+			}
+		})
+	}
+}
+
+func TestBucketIDAccountMismatch(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockLinode := mock.NewMockLinodeClient(ctrl)
+	// No Linode calls expected - the bucket ID is rejected before any API operations
+
+	srv, err := provisioner.New(nil, mockLinode, nil, nil, true, provisioner.WithAccount("acme"))
+	if err != nil {
+		t.Fatalf("failed to create provisioner server: %v", err)
+	}
+
+	const bucketID = "v2:account=other&label=" + testBucketName + "&region=" + testRegion + "&type=E0"
+
+	_, err = srv.DriverGrantBucketAccess(t.Context(), &cosi.DriverGrantBucketAccessRequest{
+		BucketId:           bucketID,
+		Name:               testBucketAccessName,
+		AuthenticationType: cosi.AuthenticationType_Key,
+	})
+	if code := status.Code(err); code != grpccodes.FailedPrecondition {
+		t.Errorf("expected grant status code %q, but got %q: %v", grpccodes.FailedPrecondition, code, err)
+	}
+
+	_, err = srv.DriverDeleteBucket(t.Context(), &cosi.DriverDeleteBucketRequest{BucketId: bucketID})
+	if code := status.Code(err); code != grpccodes.FailedPrecondition {
+		t.Errorf("expected delete status code %q, but got %q: %v", grpccodes.FailedPrecondition, code, err)
+	}
+}
+
+func TestBucketIDSigning(t *testing.T) {
+	t.Parallel()
+
+	signingKey := []byte("0123456789abcdef0123456789abcdef")
+
+	ctrl := gomock.NewController(t)
+	mockLinode := mock.NewMockLinodeClient(ctrl)
+	mockLinode.EXPECT().
+		GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		Return(nil, provisioner.ErrNotFound).
+		Times(1)
+	expectCreateBucket(t, mockLinode, "", nil, defaultLinodegoBucket)
+	mockLinode.EXPECT().
+		CreateObjectStorageKey(gomock.Any(), bucketScopedKey()).
+		Return(&linodego.ObjectStorageKey{
+			ID:        0,
+			AccessKey: testAccessKey,
+			SecretKey: testSecretKey,
+		}, nil).
+		Times(2)
+	mockLinode.EXPECT().
+		GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq("allowlisted")).
+		Return(&linodego.ObjectStorageBucket{
+			Label:        "allowlisted",
+			Region:       testRegion,
+			EndpointType: linodego.ObjectStorageEndpointE0,
+		}, nil).
+		Times(1)
+	mockLinode.EXPECT().
+		ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+		AnyTimes()
+
+	epc := cache.New(discardLog, mockLinode, 0)
+	if err := epc.Refresh(t.Context()); err != nil {
+		t.Fatalf("failed to refresh cache: %v", err)
+	}
+
+	mockS3 := mock.NewMockS3Client(ctrl)
+	expectNotFrozen(mockS3)
+
+	srv, err := provisioner.New(nil, mockLinode, epc, mockS3, true,
+		provisioner.WithSigningKey(signingKey),
+		provisioner.WithBucketAllowlist(testRegion+"/allowlisted"),
+	)
+	if err != nil {
+		t.Fatalf("failed to create provisioner server: %v", err)
+	}
+
+	created, err := srv.DriverCreateBucket(t.Context(), &cosi.DriverCreateBucketRequest{
+		Name:       testBucketName,
+		Parameters: defaultBucketParameters,
+	})
+	if err != nil {
+		t.Fatalf("failed to create bucket: %v", err)
+	}
+
+	grant := func(bucketID string) error {
+		_, err := srv.DriverGrantBucketAccess(t.Context(), &cosi.DriverGrantBucketAccessRequest{
+			BucketId:           bucketID,
+			Name:               testBucketAccessName,
+			AuthenticationType: cosi.AuthenticationType_Key,
+		})
+		return err
+	}
+
+	if err := grant(created.GetBucketId()); err != nil {
+		t.Errorf("expected signed bucket ID %q to be accepted, got: %v", created.GetBucketId(), err)
+	}
+	if err := grant(testRegion + "/allowlisted"); err != nil {
+		t.Errorf("expected allowlisted bucket to be accepted, got: %v", err)
+	}
+
+	for _, bucketID := range []string{
+		testBucketID,
+		testBucketIDV2,
+		created.GetBucketId() + "&cleanup=force",
+	} {
+		if code := status.Code(grant(bucketID)); code != grpccodes.PermissionDenied {
+			t.Errorf("expected grant for bucket ID %q to be denied, got %q", bucketID, code)
+		}
+
+		_, err := srv.DriverDeleteBucket(t.Context(), &cosi.DriverDeleteBucketRequest{BucketId: bucketID})
+		if code := status.Code(err); code != grpccodes.PermissionDenied {
+			t.Errorf("expected delete for bucket ID %q to be denied, got %q", bucketID, code)
+		}
+	}
+}
+
+func TestAllowlistedBucketIDDeletionPolicy(t *testing.T) {
+	t.Parallel()
+
+	for _, bucketID := range []string{
+		testRegion + "/allowlisted/force",
+		"v2:archive-to=" + testRegion + "%2Fattacker&cleanup=force&label=allowlisted&region=" + testRegion,
+		"v2:cleanup=force&label=allowlisted&region=" + testRegion + "&soft-delete=1h",
+	} {
+		t.Run(bucketID, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockLinode := mock.NewMockLinodeClient(ctrl)
+			mockLinode.EXPECT().
+				ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+				Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+				AnyTimes()
+			mockLinode.EXPECT().
+				GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq("allowlisted")).
+				Return(&linodego.ObjectStorageBucket{
+					Label:        "allowlisted",
+					Region:       testRegion,
+					EndpointType: linodego.ObjectStorageEndpointE0,
+				}, nil).
+				AnyTimes()
+			// The bucket is deleted as is, the API refuses to delete non-empty buckets.
+			mockLinode.EXPECT().
+				DeleteObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq("allowlisted")).
+				Return(nil)
+
+			epc := cache.New(discardLog, mockLinode, 0)
+			if err := epc.Refresh(t.Context()); err != nil {
+				t.Fatalf("failed to refresh cache: %v", err)
+			}
+
+			// No S3 calls expected - the bucket is neither pruned, archived nor soft-deleted.
+			mockS3 := mock.NewMockS3Client(ctrl)
+
+			srv, err := provisioner.New(nil, mockLinode, epc, mockS3, true,
+				provisioner.WithSigningKey([]byte("0123456789abcdef0123456789abcdef")),
+				provisioner.WithBucketAllowlist(testRegion+"/allowlisted"),
+				provisioner.WithDeletionQueue(deletion.New(discardLog, &deletion.MemoryStore{})),
+			)
+			if err != nil {
+				t.Fatalf("failed to create provisioner server: %v", err)
+			}
+
+			if _, err := srv.DriverDeleteBucket(t.Context(), &cosi.DriverDeleteBucketRequest{BucketId: bucketID}); err != nil {
+				t.Errorf("expected allowlisted bucket to be deleted, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestImportedBucketDeletion(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		testName string
+		bucketID string
+		allowed  bool
+	}{
+		{testName: "URI retained", bucketID: testImportURI},
+		{testName: "hostname retained", bucketID: testImportHostname},
+		{testName: "cluster ID retained", bucketID: testImportClusterID},
+		{testName: "URI deleted when allowed", bucketID: testImportURI, allowed: true},
+		{testName: "cluster ID deleted when allowed", bucketID: testImportClusterID, allowed: true},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockLinode := mock.NewMockLinodeClient(ctrl)
+			mockLinode.EXPECT().
+				ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+				Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+				AnyTimes()
+			expectGetBucket(t, mockLinode, linodego.ObjectStorageEndpointE0)
+			if tc.allowed {
+				mockLinode.EXPECT().
+					DeleteObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(nil).
+					Times(2)
+			}
+
+			epc := cache.New(discardLog, mockLinode, 0)
+			if err := epc.Refresh(t.Context()); err != nil {
+				t.Fatalf("failed to refresh cache: %v", err)
+			}
+
+			srv, err := provisioner.New(nil, mockLinode, epc, nil, true,
+				provisioner.WithImportedBucketDeletion(tc.allowed),
+			)
+			if err != nil {
+				t.Fatalf("failed to create provisioner server: %v", err)
+			}
+
+			for i := 0; i < 2; i++ { // run twice to check idempotency
+				_, err = srv.DriverDeleteBucket(t.Context(), &cosi.DriverDeleteBucketRequest{BucketId: tc.bucketID})
+				if err != nil {
+					t.Errorf("call %d: expected no error, got: %v", i, err)
+				}
+			}
+		})
+	}
+}
+
+func TestClusterOwnership(t *testing.T) {
+	t.Parallel()
+
+	const (
+		clusterID = "cluster-a"
+		otherID   = "cluster-b"
+	)
+
+	otherOwner := map[string]string{provisioner.TagClusterID: otherID}
+	owner := map[string]string{"team": "storage", provisioner.TagClusterID: clusterID}
+
+	createRequest := &cosi.DriverCreateBucketRequest{
+		Name:       testBucketName,
+		Parameters: defaultBucketParameters,
+	}
+
+	for _, tc := range []struct {
+		testName        string
+		call            func(context.Context, *provisioner.Server) error
+		options         []provisioner.Option
+		expectedCode    grpccodes.Code
+		setupMockS3     func(*testing.T) s3.Client
+		setupMockLinode func(*testing.T) linodeclient.Client
+	}{
+		{
+			testName: "stamps created bucket",
+			call: func(ctx context.Context, srv *provisioner.Server) error {
+				_, err := srv.DriverCreateBucket(ctx, createRequest)
+				return err
+			},
+			setupMockS3: func(t *testing.T) s3.Client {
+				t.Helper()
+				mockS3 := mock.NewMockS3Client(gomock.NewController(t))
+				mockS3.EXPECT().
+					GetBucketTags(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(map[string]string{}, nil)
+				mockS3.EXPECT().
+					SetBucketTags(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName),
+						gomock.Eq(map[string]string{provisioner.TagClusterID: clusterID})).
+					Return(nil)
+				return mockS3
+			},
+			setupMockLinode: func(t *testing.T) linodeclient.Client {
+				t.Helper()
+				mockLinode := mock.NewMockLinodeClient(gomock.NewController(t))
+				mockLinode.EXPECT().
+					GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(nil, provisioner.ErrNotFound)
+				expectCreateBucket(t, mockLinode, "", nil, defaultLinodegoBucket)
+				return mockLinode
+			},
+		},
+		{
+			testName: "deletes created bucket when stamping fails",
+			call: func(ctx context.Context, srv *provisioner.Server) error {
+				_, err := srv.DriverCreateBucket(ctx, createRequest)
+				return err
+			},
+			expectedCode: grpccodes.Internal,
+			setupMockS3: func(t *testing.T) s3.Client {
+				t.Helper()
+				mockS3 := mock.NewMockS3Client(gomock.NewController(t))
+				mockS3.EXPECT().
+					GetBucketTags(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(map[string]string{}, nil)
+				mockS3.EXPECT().
+					SetBucketTags(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName), gomock.Any()).
+					Return(errors.New("tagging failed"))
+				return mockS3
+			},
+			setupMockLinode: func(t *testing.T) linodeclient.Client {
+				t.Helper()
+				mockLinode := mock.NewMockLinodeClient(gomock.NewController(t))
+				mockLinode.EXPECT().
+					GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(nil, provisioner.ErrNotFound)
+				expectCreateBucket(t, mockLinode, "", nil, defaultLinodegoBucket)
+				mockLinode.EXPECT().
+					DeleteObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(nil)
+				return mockLinode
+			},
+		},
+		{
+			testName: "refuses to adopt unmarked bucket",
+			call: func(ctx context.Context, srv *provisioner.Server) error {
+				_, err := srv.DriverCreateBucket(ctx, createRequest)
+				return err
+			},
+			expectedCode: grpccodes.FailedPrecondition,
+			setupMockS3: func(t *testing.T) s3.Client {
+				t.Helper()
+				mockS3 := mock.NewMockS3Client(gomock.NewController(t))
+				mockS3.EXPECT().
+					GetBucketTags(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(map[string]string{"team": "storage"}, nil)
+				return mockS3
+			},
+			setupMockLinode: func(t *testing.T) linodeclient.Client {
+				t.Helper()
+				mockLinode := mock.NewMockLinodeClient(gomock.NewController(t))
+				mockLinode.EXPECT().
+					GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(defaultLinodegoBucket, nil)
+				mockLinode.EXPECT().
+					GetObjectStorageBucketAccess(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(defaultLinodegoBucketAccess, nil)
+				return mockLinode
+			},
+		},
+		{
+			testName: "stamps adopted unmarked bucket when enabled and keeps existing tags",
+			call: func(ctx context.Context, srv *provisioner.Server) error {
+				_, err := srv.DriverCreateBucket(ctx, createRequest)
+				return err
+			},
+			options: []provisioner.Option{provisioner.WithUnmarkedBucketAdoption(true)},
+			setupMockS3: func(t *testing.T) s3.Client {
+				t.Helper()
+				mockS3 := mock.NewMockS3Client(gomock.NewController(t))
+				mockS3.EXPECT().
+					GetBucketTags(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(map[string]string{"team": "storage"}, nil)
+				mockS3.EXPECT().
+					SetBucketTags(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName), gomock.Eq(owner)).
+					Return(nil)
+				return mockS3
+			},
+			setupMockLinode: func(t *testing.T) linodeclient.Client {
+				t.Helper()
+				mockLinode := mock.NewMockLinodeClient(gomock.NewController(t))
+				mockLinode.EXPECT().
+					GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(defaultLinodegoBucket, nil)
+				mockLinode.EXPECT().
+					GetObjectStorageBucketAccess(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(defaultLinodegoBucketAccess, nil)
+				return mockLinode
+			},
+		},
+		{
+			testName: "refuses to adopt bucket owned by another cluster",
+			call: func(ctx context.Context, srv *provisioner.Server) error {
+				_, err := srv.DriverCreateBucket(ctx, createRequest)
+				return err
+			},
+			expectedCode: grpccodes.FailedPrecondition,
+			setupMockS3: func(t *testing.T) s3.Client {
+				t.Helper()
+				mockS3 := mock.NewMockS3Client(gomock.NewController(t))
+				mockS3.EXPECT().
+					GetBucketTags(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(otherOwner, nil)
+				return mockS3
+			},
+			setupMockLinode: func(t *testing.T) linodeclient.Client {
+				t.Helper()
+				mockLinode := mock.NewMockLinodeClient(gomock.NewController(t))
+				mockLinode.EXPECT().
+					GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(defaultLinodegoBucket, nil)
+				mockLinode.EXPECT().
+					GetObjectStorageBucketAccess(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(defaultLinodegoBucketAccess, nil)
+				return mockLinode
+			},
+		},
+		{
+			testName: "prunes and deletes owned bucket",
+			call: func(ctx context.Context, srv *provisioner.Server) error {
+				_, err := srv.DriverDeleteBucket(ctx, &cosi.DriverDeleteBucketRequest{BucketId: testBucketIDV2Force})
+				return err
+			},
+			setupMockS3: func(t *testing.T) s3.Client {
+				t.Helper()
+				mockS3 := mock.NewMockS3Client(gomock.NewController(t))
+				mockS3.EXPECT().
+					GetBucketTags(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(owner, nil)
+				mockS3.EXPECT().
+					Prune(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName), gomock.Any()).
+					Return(nil)
+				return mockS3
+			},
+			setupMockLinode: func(t *testing.T) linodeclient.Client {
+				t.Helper()
+				mockLinode := mock.NewMockLinodeClient(gomock.NewController(t))
+				mockLinode.EXPECT().
+					DeleteObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(nil)
+				return mockLinode
+			},
+		},
+		{
+			testName: "refuses to prune or delete bucket owned by another cluster",
+			call: func(ctx context.Context, srv *provisioner.Server) error {
+				_, err := srv.DriverDeleteBucket(ctx, &cosi.DriverDeleteBucketRequest{BucketId: testBucketIDV2Force})
+				return err
+			},
+			expectedCode: grpccodes.FailedPrecondition,
+			setupMockS3: func(t *testing.T) s3.Client {
+				t.Helper()
+				mockS3 := mock.NewMockS3Client(gomock.NewController(t))
+				mockS3.EXPECT().
+					GetBucketTags(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+					Return(otherOwner, nil)
+				return mockS3
+			},
+			setupMockLinode: func(t *testing.T) linodeclient.Client {
+				t.Helper()
+				// No Linode calls expected - the bucket is neither pruned nor deleted
+				return mock.NewMockLinodeClient(gomock.NewController(t))
+			},
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			t.Parallel()
+
+			srv, err := provisioner.New(nil, tc.setupMockLinode(t), nil, tc.setupMockS3(t), true,
+				append([]provisioner.Option{provisioner.WithClusterID(clusterID)}, tc.options...)...,
+			)
+			if err != nil {
+				t.Fatalf("failed to create provisioner server: %v", err)
+			}
+
+			if code := status.Code(tc.call(t.Context(), srv)); code != tc.expectedCode {
+				t.Errorf("expected status code %q, but got %q", tc.expectedCode, code)
+			}
+		})
+	}
+}
+
+func TestDriverCreateBucketLabelTemplate(t *testing.T) {
+	t.Parallel()
+
+	const label = "cosi-prod-" + testBucketName
+
+	ctrl := gomock.NewController(t)
+	mockLinode := mock.NewMockLinodeClient(ctrl)
+	mockLinode.EXPECT().
+		GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(label)).
+		Return(nil, provisioner.ErrNotFound)
+	mockLinode.EXPECT().
+		CreateObjectStorageBucket(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, opts linodego.ObjectStorageBucketCreateOptions) (*linodego.ObjectStorageBucket, error) {
+			if opts.Label != label {
+				t.Errorf("expected bucket label %q, got %q", label, opts.Label)
+			}
+			return &linodego.ObjectStorageBucket{
+				Label:        opts.Label,
+				Region:       opts.Region,
+				EndpointType: linodego.ObjectStorageEndpointE0,
+			}, nil
+		})
+	// No further calls expected - invalid labels are rejected before any API operations
+
+	mockS3 := mock.NewMockS3Client(ctrl)
+	mockS3.EXPECT().
+		GetBucketTags(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(label)).
+		Return(map[string]string{}, nil)
+	mockS3.EXPECT().
+		SetBucketTags(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(label), gomock.Any()).
+		Return(nil)
+
+	srv, err := provisioner.New(nil, mockLinode, nil, mockS3, true, provisioner.WithClusterID("prod"))
+	if err != nil {
+		t.Fatalf("failed to create provisioner server: %v", err)
+	}
+
+	resp, err := srv.DriverCreateBucket(t.Context(), &cosi.DriverCreateBucketRequest{
+		Name: testBucketName,
+		Parameters: map[string]string{
+			provisioner.ParamRegion:        testRegion,
+			provisioner.ParamLabelTemplate: "cosi-{{ .Cluster }}-{{ .Name }}",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create bucket: %v", err)
+	}
+
+	expectedID := "v2:label=" + label + "&region=" + testRegion + "&type=E0"
+	if resp.GetBucketId() != expectedID {
+		t.Errorf("expected bucket ID %q, got %q", expectedID, resp.GetBucketId())
+	}
+
+	_, err = srv.DriverCreateBucket(t.Context(), &cosi.DriverCreateBucketRequest{
+		Name: "Invalid.Name",
+		Parameters: map[string]string{
+			provisioner.ParamRegion:        testRegion,
+			provisioner.ParamLabelTemplate: "{{ .Name }}",
+		},
+	})
+	if code := status.Code(err); code != grpccodes.InvalidArgument {
+		t.Errorf("expected status code %q, but got %q: %v", grpccodes.InvalidArgument, code, err)
+	}
+}
+
+type staticRegions map[string]string
+
+func (r staticRegions) Resolve(_ context.Context, region string) (string, error) {
+	if resolved, ok := r[region]; ok {
+		return resolved, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", cache.ErrUnknownRegion, region)
+}
+
+func TestDriverCreateBucketResolvesRegion(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockLinode := mock.NewMockLinodeClient(ctrl)
+	mockLinode.EXPECT().
+		GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		Return(nil, provisioner.ErrNotFound)
+	expectCreateBucket(t, mockLinode, "", nil, defaultLinodegoBucket)
+	// No further calls expected - unknown regions are rejected before any API operations
+
+	srv, err := provisioner.New(nil, mockLinode, nil, nil, true,
+		provisioner.WithRegions(staticRegions{testRegion: testRegion, "test-region-1": testRegion}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create provisioner server: %v", err)
+	}
+
+	resp, err := srv.DriverCreateBucket(t.Context(), &cosi.DriverCreateBucketRequest{
+		Name:       testBucketName,
+		Parameters: map[string]string{provisioner.ParamRegion: "test-region-1"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create bucket: %v", err)
+	}
+	if resp.GetBucketId() != testBucketIDV2 {
+		t.Errorf("expected bucket ID %q, got %q", testBucketIDV2, resp.GetBucketId())
+	}
+
+	_, err = srv.DriverCreateBucket(t.Context(), &cosi.DriverCreateBucketRequest{
+		Name:       testBucketName,
+		Parameters: map[string]string{provisioner.ParamRegion: "test-regoin"},
+	})
+	if code := status.Code(err); code != grpccodes.InvalidArgument {
+		t.Errorf("expected status code %q, but got %q: %v", grpccodes.InvalidArgument, code, err)
+	}
+}
+
+func TestDriverCreateBucketRegionPreference(t *testing.T) {
+	t.Parallel()
+
+	const (
+		regionA = "region-a"
+		regionB = "region-b"
+	)
+
+	endpointA := "region-a-1.linodeobjects.com"
+	endpointB := "region-b-1.linodeobjects.com"
+	endpoints := []linodego.ObjectStorageEndpoint{
+		{Region: regionA, S3Endpoint: &endpointA, EndpointType: linodego.ObjectStorageEndpointE0},
+		{Region: regionB, S3Endpoint: &endpointB, EndpointType: linodego.ObjectStorageEndpointE1},
+	}
+
+	bucketIn := func(region string, endpointType linodego.ObjectStorageEndpointType) *linodego.ObjectStorageBucket {
+		return &linodego.ObjectStorageBucket{Label: testBucketName, Region: region, EndpointType: endpointType}
+	}
+
+	expectGet := func(mockLinode *mock.MockLinodeClient, region string, bucket *linodego.ObjectStorageBucket) {
+		if bucket == nil {
+			mockLinode.EXPECT().
+				GetObjectStorageBucket(gomock.Any(), gomock.Eq(region), gomock.Eq(testBucketName)).
+				Return(nil, provisioner.ErrNotFound)
+			return
+		}
+		mockLinode.EXPECT().
+			GetObjectStorageBucket(gomock.Any(), gomock.Eq(region), gomock.Eq(testBucketName)).
+			Return(bucket, nil)
+	}
+
+	// regionRejected is the error of the API rejecting the region of the bucket.
+	regionRejected := func() error {
+		return &linodego.Error{
+			Code:    http.StatusBadRequest,
+			Message: "Object Storage is at capacity in this region",
+			Response: &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Body: io.NopCloser(strings.NewReader(
+					`{"errors":[{"field":"region","reason":"Object Storage is at capacity in this region"}]}`)),
+			},
+		}
+	}
+
+	expectCreate := func(mockLinode *mock.MockLinodeClient, region string, err error) {
+		mockLinode.EXPECT().
+			CreateObjectStorageBucket(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, opts linodego.ObjectStorageBucketCreateOptions) (*linodego.ObjectStorageBucket, error) {
+				if opts.Region != region {
+					t.Errorf("expected bucket to be created in %q, got %q", region, opts.Region)
+				}
+				if err != nil {
+					return nil, err
+				}
+				return bucketIn(opts.Region, opts.EndpointType), nil
+			})
+	}
+
+	for _, tc := range []struct {
+		testName         string
+		params           map[string]string
+		expectedBucketID string
+		expectedCode     grpccodes.Code
+		// retries is the number of attempts expected to fail with codes.Unavailable first.
+		retries         int
+		setupMockLinode func(*mock.MockLinodeClient)
+	}{
+		{
+			testName: "falls back to next region when region is unavailable",
+			params: map[string]string{
+				provisioner.ParamRegion:           regionA,
+				provisioner.ParamRegionPreference: regionB,
+			},
+			expectedBucketID: "v2:label=" + testBucketName + "&region=" + regionB,
+			setupMockLinode: func(mockLinode *mock.MockLinodeClient) {
+				expectGet(mockLinode, regionA, nil)
+				expectGet(mockLinode, regionB, nil)
+				expectCreate(mockLinode, regionA, regionRejected())
+				expectCreate(mockLinode, regionB, nil)
+			},
+		},
+		{
+			testName: "falls back to next region after repeated outages",
+			params: map[string]string{
+				provisioner.ParamRegionPreference: regionA + "," + regionB,
+			},
+			retries:          2,
+			expectedBucketID: "v2:label=" + testBucketName + "&region=" + regionB,
+			setupMockLinode: func(mockLinode *mock.MockLinodeClient) {
+				// Every attempt looks up the bucket in all candidate regions first.
+				for range 3 {
+					expectGet(mockLinode, regionA, nil)
+					expectGet(mockLinode, regionB, nil)
+					expectCreate(mockLinode, regionA, &linodego.Error{Code: http.StatusServiceUnavailable})
+				}
+				expectCreate(mockLinode, regionB, nil)
+			},
+		},
+		{
+			testName: "does not fall back on transient errors",
+			params: map[string]string{
+				provisioner.ParamRegionPreference: regionA + "," + regionB,
+			},
+			expectedCode: grpccodes.Unavailable,
+			setupMockLinode: func(mockLinode *mock.MockLinodeClient) {
+				expectGet(mockLinode, regionA, nil)
+				expectGet(mockLinode, regionB, nil)
+				expectCreate(mockLinode, regionA, &linodego.Error{Code: http.StatusServiceUnavailable})
+			},
+		},
+		{
+			testName: "does not fall back on client errors",
+			params: map[string]string{
+				provisioner.ParamRegionPreference: regionA + "," + regionB,
+			},
+			expectedCode: grpccodes.Internal,
+			setupMockLinode: func(mockLinode *mock.MockLinodeClient) {
+				expectGet(mockLinode, regionA, nil)
+				expectGet(mockLinode, regionB, nil)
+				expectCreate(mockLinode, regionA, &linodego.Error{
+					Code:    http.StatusBadRequest,
+					Message: "Label is unavailable",
+					Response: &http.Response{
+						StatusCode: http.StatusBadRequest,
+						Body:       io.NopCloser(strings.NewReader(`{"errors":[{"field":"label","reason":"Label is unavailable"}]}`)),
+					},
+				})
+			},
+		},
+		{
+			testName: "skips regions without requested endpoint type",
+			params: map[string]string{
+				provisioner.ParamRegionPreference: regionA + "," + regionB,
+				provisioner.ParamEndpointType:     string(linodego.ObjectStorageEndpointE1),
+			},
+			expectedBucketID: "v2:label=" + testBucketName + "&region=" + regionB + "&type=E1",
+			setupMockLinode: func(mockLinode *mock.MockLinodeClient) {
+				expectGet(mockLinode, regionA, nil)
+				expectGet(mockLinode, regionB, nil)
+				expectCreate(mockLinode, regionB, nil)
+			},
+		},
+		{
+			testName: "finds existing bucket in any candidate region",
+			params: map[string]string{
+				provisioner.ParamRegionPreference: regionA + "," + regionB,
+			},
+			expectedBucketID: "v2:label=" + testBucketName + "&region=" + regionB + "&type=E1",
+			setupMockLinode: func(mockLinode *mock.MockLinodeClient) {
+				expectGet(mockLinode, regionA, nil)
+				expectGet(mockLinode, regionB, bucketIn(regionB, linodego.ObjectStorageEndpointE1))
+				mockLinode.EXPECT().
+					GetObjectStorageBucketAccess(gomock.Any(), gomock.Eq(regionB), gomock.Eq(testBucketName)).
+					Return(defaultLinodegoBucketAccess, nil)
+			},
+		},
+	} {
+		t.Run(tc.testName, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			mockLinode := mock.NewMockLinodeClient(ctrl)
+			mockLinode.EXPECT().
+				ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+				Return(endpoints, nil).
+				AnyTimes()
+			tc.setupMockLinode(mockLinode)
+
+			srv, err := provisioner.New(nil, mockLinode, nil, nil, true)
+			if err != nil {
+				t.Fatalf("failed to create provisioner server: %v", err)
+			}
+
+			req := &cosi.DriverCreateBucketRequest{
+				Name:       testBucketName,
+				Parameters: tc.params,
+			}
+
+			for i := range tc.retries {
+				if _, err := srv.DriverCreateBucket(t.Context(), req); status.Code(err) != grpccodes.Unavailable {
+					t.Fatalf("attempt %d: expected status code %q, but got: %v", i, grpccodes.Unavailable, err)
+				}
+			}
+
+			resp, err := srv.DriverCreateBucket(t.Context(), req)
+			if code := status.Code(err); code != tc.expectedCode {
+				t.Fatalf("expected status code %q, but got %q: %v", tc.expectedCode, code, err)
+			}
+			if resp.GetBucketId() != tc.expectedBucketID {
+				t.Errorf("expected bucket ID %q, got %q", tc.expectedBucketID, resp.GetBucketId())
+			}
+		})
+	}
+}
+
+func TestEndpointSelectionUsesCache(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	mockLinode := mock.NewMockLinodeClient(ctrl)
+	// Only the cache refresh lists the endpoints
+	mockLinode.EXPECT().
+		ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint, defaultLinodegoEndpointE1}, nil).
+		Times(1)
+	mockLinode.EXPECT().
+		GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		Return(nil, provisioner.ErrNotFound)
+	expectCreateBucket(t, mockLinode, linodego.ObjectStorageEndpointE1, nil, &linodego.ObjectStorageBucket{
+		Label:        testBucketName,
+		Region:       testRegion,
+		EndpointType: linodego.ObjectStorageEndpointE1,
+	})
+
+	epc := cache.New(discardLog, mockLinode, 0)
+	if err := epc.Refresh(t.Context()); err != nil {
+		t.Fatalf("failed to refresh cache: %v", err)
+	}
+
+	srv, err := provisioner.New(nil, mockLinode, epc, nil, true)
+	if err != nil {
+		t.Fatalf("failed to create provisioner server: %v", err)
+	}
+
+	resp, err := srv.DriverCreateBucket(t.Context(), &cosi.DriverCreateBucketRequest{
+		Name: testBucketName,
+		Parameters: map[string]string{
+			provisioner.ParamRegion:                 testRegion,
+			provisioner.ParamEndpointTypePreference: "E3,E1",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create bucket: %v", err)
+	}
+	if resp.GetBucketId() != testBucketIDV2E1 {
+		t.Errorf("expected bucket ID %q, got %q", testBucketIDV2E1, resp.GetBucketId())
+	}
+}
+
+// newFakeS3Server returns the host of an S3 server accepting every request. Buckets have no tags.
+func newFakeS3Server(t *testing.T) string {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Query().Has("tagging") {
+			fmt.Fprint(w, `<Tagging><TagSet></TagSet></Tagging>`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestEphemeralKeyScopes(t *testing.T) {
+	t.Parallel()
+
+	s3Host := newFakeS3Server(t)
+
+	ctrl := gomock.NewController(t)
+	mockLinode := mock.NewMockLinodeClient(ctrl)
+	mockLinode.EXPECT().
+		ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageEndpoint{{
+			Region:       testRegion,
+			S3Endpoint:   &s3Host,
+			EndpointType: linodego.ObjectStorageEndpointE0,
+		}}, nil).
+		AnyTimes()
+	mockLinode.EXPECT().
+		GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		Return(nil, provisioner.ErrNotFound)
+	expectCreateBucket(t, mockLinode, "", nil, defaultLinodegoBucket)
+	mockLinode.EXPECT().
+		DeleteObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		Return(nil)
+
+	var scopes []linodego.ObjectStorageKeyBucketAccessCreateOptions
+	mockLinode.EXPECT().
+		CreateObjectStorageKey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, opts linodego.ObjectStorageKeyCreateOptions) (*linodego.ObjectStorageKey, error) {
+			if len(opts.Regions) != 0 {
+				t.Errorf("expected no region-wide access, got %v", opts.Regions)
+			}
+			scopes = append(scopes, opts.BucketAccess...)
+
+			return &linodego.ObjectStorageKey{ID: len(scopes), AccessKey: testAccessKey, SecretKey: testSecretKey}, nil
+		}).
+		Times(3)
+	mockLinode.EXPECT().
+		DeleteObjectStorageKey(gomock.Any(), gomock.Any()).
+		Return(nil).
+		Times(3)
+
+	epc := cache.New(discardLog, mockLinode, 0)
+	if err := epc.Refresh(t.Context()); err != nil {
+		t.Fatalf("failed to refresh cache: %v", err)
+	}
+
+	srv, err := provisioner.New(nil, mockLinode, epc, nil, false, provisioner.WithClusterID("prod"))
+	if err != nil {
+		t.Fatalf("failed to create provisioner server: %v", err)
+	}
+
+	resp, err := srv.DriverCreateBucket(t.Context(), &cosi.DriverCreateBucketRequest{
+		Name: testBucketName,
+		Parameters: map[string]string{
+			provisioner.ParamRegion: testRegion,
+			provisioner.ParamPolicy: testBucketPolicyTemplate,
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create bucket: %v", err)
+	}
+
+	if _, err := srv.DriverDeleteBucket(t.Context(), &cosi.DriverDeleteBucketRequest{BucketId: resp.GetBucketId()}); err != nil {
+		t.Fatalf("failed to delete bucket: %v", err)
+	}
+
+	// Stamping the ownership tag and applying the policy write to the bucket, checking ownership
+	// before deletion only reads from it.
+	expected := []linodego.ObjectStorageKeyBucketAccessCreateOptions{
+		{Region: testRegion, BucketName: testBucketName, Permissions: linodeclient.KeyPermissionsReadWrite},
+		{Region: testRegion, BucketName: testBucketName, Permissions: linodeclient.KeyPermissionsReadWrite},
+		{Region: testRegion, BucketName: testBucketName, Permissions: linodeclient.KeyPermissionsReadOnly},
+	}
+	if !reflect.DeepEqual(scopes, expected) {
+		t.Errorf("expected key scopes %+v, got %+v", expected, scopes)
+	}
+}
+
+func TestStaticCredentialsUseBucketEndpoint(t *testing.T) {
+	t.Parallel()
+
+	defaultHost := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to default endpoint: %s %s", r.Method, r.URL)
+	}))
+	t.Cleanup(defaultHost.Close)
+
+	var (
+		mu         sync.Mutex
+		accessKeys []string
+	)
+	bucketHost := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		credential := strings.TrimPrefix(strings.Fields(r.Header.Get("Authorization"))[1], "Credential=")
+		accessKeys = append(accessKeys, strings.Split(credential, "/")[0])
+	}))
+	t.Cleanup(bucketHost.Close)
+
+	defaultEndpoint := strings.TrimPrefix(defaultHost.URL, "http://")
+	bucketEndpoint := strings.TrimPrefix(bucketHost.URL, "http://")
+
+	ctrl := gomock.NewController(t)
+	mockLinode := mock.NewMockLinodeClient(ctrl)
+	mockLinode.EXPECT().
+		ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageEndpoint{
+			{Region: testRegion, S3Endpoint: &defaultEndpoint, EndpointType: linodego.ObjectStorageEndpointE0},
+			{Region: testRegion, S3Endpoint: &bucketEndpoint, EndpointType: linodego.ObjectStorageEndpointE2},
+		}, nil).
+		AnyTimes()
+	mockLinode.EXPECT().
+		GetObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		Return(nil, provisioner.ErrNotFound)
+	expectCreateBucket(t, mockLinode, linodego.ObjectStorageEndpointE2, nil, &linodego.ObjectStorageBucket{
+		Region:       testRegion,
+		Label:        testBucketName,
+		EndpointType: linodego.ObjectStorageEndpointE2,
+	})
+
+	epc := cache.New(discardLog, mockLinode, 0)
+	if err := epc.Refresh(t.Context()); err != nil {
+		t.Fatalf("failed to refresh cache: %v", err)
+	}
+
+	s3cli := s3.New(epc, "default", "secret", false,
+		s3.WithEndpointTypeCredentials(linodego.ObjectStorageEndpointE2, s3.Credentials{AccessKey: "e2", SecretKey: "secret"}))
+
+	srv, err := provisioner.New(nil, mockLinode, epc, s3cli, false)
+	if err != nil {
+		t.Fatalf("failed to create provisioner server: %v", err)
+	}
+
+	if _, err := srv.DriverCreateBucket(t.Context(), &cosi.DriverCreateBucketRequest{
+		Name: testBucketName,
+		Parameters: map[string]string{
+			provisioner.ParamRegion:       testRegion,
+			provisioner.ParamEndpointType: string(linodego.ObjectStorageEndpointE2),
+			provisioner.ParamPolicy:       testBucketPolicyTemplate,
+		},
+	}); err != nil {
+		t.Fatalf("failed to create bucket: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(accessKeys) == 0 {
+		t.Fatalf("expected requests to the bucket endpoint")
+	}
+
+	for _, key := range accessKeys {
+		if key != "e2" {
+			t.Errorf("expected E2 credentials, got access key %q", key)
+		}
 	}
 }
@@ -0,0 +1,287 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/linode/linodego/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// regionCandidate is a region the bucket may be created in, along with the endpoint type
+// selected for the region. Regions without a matching endpoint type carry the selection error.
+type regionCandidate struct {
+	region       string
+	endpointType linodego.ObjectStorageEndpointType
+	err          error
+}
+
+func (c regionCandidate) logger(log *slog.Logger) *slog.Logger {
+	log = log.With(slog.String(KeyBucketRegion, c.region))
+	if c.endpointType != "" {
+		log = log.With(slog.String(KeyBucketEndpointType, string(c.endpointType)))
+	}
+
+	return log
+}
+
+// parseRegionPreference returns the requested regions in preference order. The region
+// parameter, when set, comes first, followed by the regions of the region preference.
+func parseRegionPreference(params map[string]string) []string {
+	var regions []string
+
+	if region := strings.TrimSpace(params[ParamRegion]); region != "" {
+		regions = append(regions, region)
+	}
+
+	for part := range strings.SplitSeq(params[ParamRegionPreference], ",") {
+		region := strings.TrimSpace(part)
+		if region != "" && !slices.Contains(regions, region) {
+			regions = append(regions, region)
+		}
+	}
+
+	return regions
+}
+
+// candidateRegions returns the resolved regions in preference order.
+func (s *Server) candidateRegions(ctx context.Context, log *slog.Logger, params map[string]string) ([]string, error) {
+	regions := parseRegionPreference(params)
+	if len(regions) == 0 {
+		log.ErrorContext(ctx, "Required parameter was not provided in the request", "error", ErrMissingRegion)
+		return nil, status.Error(codes.InvalidArgument, "region was not provided")
+	}
+
+	resolved := make([]string, 0, len(regions))
+	for _, region := range regions {
+		region, err := s.resolveRegion(ctx, log, region)
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(resolved, region) {
+			resolved = append(resolved, region)
+		}
+	}
+
+	return resolved, nil
+}
+
+// selectCandidates selects the endpoint type for every region. Regions where the requested
+// endpoint type is unavailable are skipped when creating the bucket. It fails only when
+// no region has a matching endpoint type.
+func (s *Server) selectCandidates(
+	ctx context.Context,
+	log *slog.Logger,
+	regions []string,
+	params map[string]string,
+) ([]regionCandidate, error) {
+	candidates := make([]regionCandidate, 0, len(regions))
+	available := false
+
+	for _, region := range regions {
+		endpointType, err := s.selectEndpointType(ctx, region, params)
+		if _, ok := status.FromError(err); ok && err != nil {
+			log.ErrorContext(ctx, "Failed to select endpoint", "error", err)
+			return nil, err
+		}
+
+		candidates = append(candidates, regionCandidate{region: region, endpointType: endpointType, err: err})
+		available = available || err == nil
+	}
+
+	if !available {
+		err := candidates[0].err
+		log.ErrorContext(ctx, "Failed to select endpoint", "error", err)
+
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return candidates, nil
+}
+
+// regionOutageAttempts is the number of consecutive transient failures to create a bucket in
+// a region before the next candidate region is tried.
+const regionOutageAttempts = 3
+
+// regionFieldName is the field of API errors rejecting the region of a request.
+const regionFieldName = "region"
+
+// regionFailures counts the consecutive transient failures to create buckets, by region and label.
+type regionFailures struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// add records a failure to create the bucket in the region, and returns the number of
+// consecutive failures.
+func (f *regionFailures) add(region, label string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.counts == nil {
+		f.counts = make(map[string]int)
+	}
+
+	f.counts[region+"/"+label]++
+
+	return f.counts[region+"/"+label]
+}
+
+// reset forgets the failures to create the bucket in the candidate regions.
+func (f *regionFailures) reset(candidates []regionCandidate, label string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, candidate := range candidates {
+		delete(f.counts, candidate.region+"/"+label)
+	}
+}
+
+// createBucketInCandidateRegions creates the bucket in the first available candidate region.
+// When the API reports that a region is unavailable, the bucket is created in the next one.
+// Transient errors, e.g. transport errors or server errors, are returned as codes.Unavailable,
+// until creating the bucket in the region failed regionOutageAttempts times in a row. The next
+// region is tried then, as the region is likely down. Retries look up the bucket in every
+// candidate region before creating it, so a bucket created despite an error is not duplicated.
+func (s *Server) createBucketInCandidateRegions(
+	ctx context.Context,
+	log *slog.Logger,
+	candidates []regionCandidate,
+	label string,
+	acl linodego.ObjectStorageACL,
+	cors ParamCORSValue,
+) (*linodego.ObjectStorageBucket, *slog.Logger, error) {
+	var (
+		errs      error
+		transient bool
+	)
+
+	for _, candidate := range candidates {
+		if candidate.err != nil {
+			continue
+		}
+
+		log := candidate.logger(log)
+
+		opts := linodego.ObjectStorageBucketCreateOptions{
+			Region: candidate.region,
+			Label:  label,
+			ACL:    acl,
+		}
+		if candidate.endpointType != "" {
+			opts.EndpointType = candidate.endpointType
+		}
+		if cors.Bool() {
+			opts.CorsEnabled = cors.BoolP()
+		}
+
+		log.InfoContext(ctx, "Creating bucket")
+
+		bucket, err := s.client.CreateObjectStorageBucket(ctx, opts)
+		if err == nil {
+			log.InfoContext(ctx, "Bucket created")
+			if candidate.endpointType != "" && bucket.EndpointType == "" {
+				bucket.EndpointType = candidate.endpointType
+			}
+			s.regionFailures.reset(candidates, label)
+
+			return bucket, log, nil
+		}
+
+		log.ErrorContext(ctx, "Failed to create bucket", "error", err)
+
+		errs = errors.Join(errs, err)
+		transient = transientError(err)
+
+		if transient {
+			failures := s.regionFailures.add(candidate.region, label)
+			if failures < regionOutageAttempts {
+				return nil, log, status.Error(codes.Unavailable, fmt.Sprintf("failed to create bucket: %v", errs))
+			}
+
+			log.WarnContext(ctx, "Region keeps failing, trying next region", slog.Int("failures", failures))
+
+			continue
+		}
+
+		if !regionUnavailable(err) {
+			break
+		}
+	}
+
+	if transient {
+		return nil, log, status.Error(codes.Unavailable, fmt.Sprintf("failed to create bucket: %v", errs))
+	}
+
+	return nil, log, errs
+}
+
+// regionUnavailable reports whether the API rejected the bucket because of its region, e.g.
+// because the region is at capacity. Only client errors whose reasons all refer to the region
+// field are considered, as other errors do not tell whether another region would do.
+func regionUnavailable(err error) bool {
+	var apiErr *linodego.Error
+	if !errors.As(err, &apiErr) || apiErr.Response == nil || apiErr.Response.Body == nil {
+		return false
+	}
+
+	if apiErr.Code < http.StatusBadRequest || apiErr.Code >= http.StatusInternalServerError {
+		return false
+	}
+
+	body, err := io.ReadAll(apiErr.Response.Body)
+	if err != nil {
+		return false
+	}
+	apiErr.Response.Body = io.NopCloser(bytes.NewReader(body))
+
+	var reasons linodego.APIError
+	if err := json.Unmarshal(body, &reasons); err != nil || len(reasons.Errors) == 0 {
+		return false
+	}
+
+	return !slices.ContainsFunc(reasons.Errors, func(reason linodego.APIErrorReason) bool {
+		return reason.Field != regionFieldName
+	})
+}
+
+// transientError reports whether the error may go away when retried: transport errors,
+// rate limits and server errors.
+func transientError(err error) bool {
+	var apiErr interface{ StatusCode() int }
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	code := apiErr.StatusCode()
+
+	// Codes below 100 are transport errors, wrapped by linodego.
+	return code < http.StatusContinue ||
+		code == http.StatusTooManyRequests ||
+		code >= http.StatusInternalServerError
+}
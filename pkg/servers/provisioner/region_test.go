@@ -0,0 +1,108 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/linode/linodego/v2"
+)
+
+func TestParseRegionPreference(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		params   map[string]string
+		expected []string
+	}{
+		{params: map[string]string{}},
+		{params: map[string]string{ParamRegion: "us-ord"}, expected: []string{"us-ord"}},
+		{
+			params:   map[string]string{ParamRegionPreference: "us-ord, us-iad,,us-ord"},
+			expected: []string{"us-ord", "us-iad"},
+		},
+		{
+			params:   map[string]string{ParamRegion: "us-iad", ParamRegionPreference: "us-ord,us-iad"},
+			expected: []string{"us-iad", "us-ord"},
+		},
+	} {
+		if actual := parseRegionPreference(tc.params); !slices.Equal(actual, tc.expected) {
+			t.Errorf("%v: expected %v, got %v", tc.params, tc.expected, actual)
+		}
+	}
+}
+
+func TestRegionUnavailable(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		err      error
+		expected bool
+	}{
+		{err: apiError(http.StatusBadRequest, "region", "Region is at capacity"), expected: true},
+		{err: fmt.Errorf("wrapped: %w", apiError(http.StatusBadRequest, "region", "Not available")), expected: true},
+		{err: apiError(http.StatusBadRequest, "label", "Label is unavailable")},
+		{err: apiError(http.StatusBadRequest, "", "Region unavailable")},
+		{err: apiError(http.StatusServiceUnavailable, "region", "Region unavailable")},
+		{err: &linodego.Error{Code: http.StatusBadRequest, Message: "[region] Region is at capacity"}},
+		{err: linodego.NewError(errors.New("connection refused"))},
+		{err: errors.New("region unavailable")},
+		{err: context.Canceled},
+	} {
+		if actual := regionUnavailable(tc.err); actual != tc.expected {
+			t.Errorf("%v: expected %t, got %t", tc.err, tc.expected, actual)
+		}
+	}
+}
+
+// apiError returns the error of an API response rejecting the field for the reason.
+func apiError(code int, field, reason string) *linodego.Error {
+	body, _ := json.Marshal(linodego.APIError{Errors: []linodego.APIErrorReason{{Field: field, Reason: reason}}})
+
+	return &linodego.Error{
+		Code:     code,
+		Message:  reason,
+		Response: &http.Response{StatusCode: code, Body: io.NopCloser(bytes.NewReader(body))},
+	}
+}
+
+func TestTransientError(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		err      error
+		expected bool
+	}{
+		{err: &linodego.Error{Code: http.StatusServiceUnavailable}, expected: true},
+		{err: linodego.Error{Code: http.StatusInternalServerError}, expected: true},
+		{err: &linodego.Error{Code: http.StatusTooManyRequests}, expected: true},
+		{err: linodego.NewError(errors.New("connection refused")), expected: true},
+		{err: fmt.Errorf("wrapped: %w", &linodego.Error{Code: http.StatusBadGateway}), expected: true},
+		{err: &linodego.Error{Code: http.StatusBadRequest}},
+		{err: context.Canceled},
+	} {
+		if actual := transientError(tc.err); actual != tc.expected {
+			t.Errorf("%v: expected %t, got %t", tc.err, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/linode/linodego/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	cosi "sigs.k8s.io/container-object-storage-interface-spec"
+
+	"github.com/linode/linode-cosi-driver/pkg/deletion"
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient"
+	"github.com/linode/linode-cosi-driver/pkg/s3"
+)
+
+// parseSoftDeletePeriod parses the grace period of soft-deleted buckets, e.g. "168h".
+func parseSoftDeletePeriod(value string) (time.Duration, error) {
+	period, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidSoftDeletePeriod, err)
+	}
+
+	if period <= 0 {
+		return 0, fmt.Errorf("%w: %q must be positive", ErrInvalidSoftDeletePeriod, value)
+	}
+
+	return period, nil
+}
+
+// pendingDeletion returns the end of the grace period recorded in the bucket tags, and
+// whether the bucket is pending deletion.
+func pendingDeletion(tags map[string]string) (time.Time, bool, error) {
+	value, ok := tags[TagDeleteAfter]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	deleteAfter, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid pending deletion marker %q: %w", value, err)
+	}
+
+	return deleteAfter, true, nil
+}
+
+// inGracePeriod reports whether the bucket of the task is soft-deleted, and can still be restored.
+func inGracePeriod(task deletion.Task) bool {
+	return task.State == deletion.StatePending && time.Now().Before(task.DeleteAfter)
+}
+
+// softDelete revokes access to the bucket, denies all access through the bucket policy and
+// marks the bucket as pending deletion. The deletion is queued, and started at the end of
+// the grace period. Buckets restored in the meantime are retained.
+func (s *Server) softDelete(
+	ctx context.Context,
+	log *slog.Logger,
+	s3cli s3.Client,
+	ref bucketRef,
+) (*cosi.DriverDeleteBucketResponse, error) {
+	tags, err := s3cli.GetBucketTags(ctx, ref.Region, ref.Label)
+	if s3.IsNotFound(err) {
+		log.InfoContext(ctx, "Bucket already deleted")
+		return &cosi.DriverDeleteBucketResponse{}, status.Error(codes.OK, "bucket deleted")
+	}
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to get bucket tags", "error", err)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to get bucket tags: %v", err))
+	}
+
+	if _, ok := tags[TagRestored]; ok {
+		return s.retainRestored(ctx, log, ref)
+	}
+
+	deleteAfter, pending, err := pendingDeletion(tags)
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to read pending deletion marker", "error", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if !pending {
+		if err := s.revokeBucketAccess(ctx, log, ref.Region, ref.Label); err != nil {
+			log.ErrorContext(ctx, "Failed to revoke bucket access", "error", err)
+			return nil, status.Error(codes.Internal, fmt.Sprintf("failed to revoke bucket access: %v", err))
+		}
+
+		if err := updateBucketPolicy(ctx, s3cli, ref.Region, ref.Label, func(policy string) (string, error) {
+			return s3.DenyAll(policy, ref.Label)
+		}); err != nil {
+			log.ErrorContext(ctx, "Failed to deny bucket access", "error", err)
+			return nil, status.Error(codes.Internal, fmt.Sprintf("failed to deny bucket access: %v", err))
+		}
+
+		deleteAfter = time.Now().Add(ref.SoftDeletePeriod).UTC().Truncate(time.Second)
+		tags[TagDeleteAfter] = deleteAfter.Format(time.RFC3339)
+
+		if err := s3cli.SetBucketTags(ctx, ref.Region, ref.Label, tags); err != nil {
+			log.ErrorContext(ctx, "Failed to set pending deletion marker", "error", err)
+			return nil, status.Error(codes.Internal, fmt.Sprintf("failed to set pending deletion marker: %v", err))
+		}
+
+		log.InfoContext(ctx, "Bucket pending deletion", slog.Time("delete_after", deleteAfter))
+	}
+
+	task, _, err := s.deletions.Enqueue(deletion.Task{
+		Region:       ref.Region,
+		Label:        ref.Label,
+		EndpointType: ref.EndpointType,
+		Cleanup:      ref.Cleanup,
+		ArchiveTo:    ref.ArchiveTo,
+		DeleteAfter:  deleteAfter,
+	})
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to queue bucket deletion", "error", err)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to queue bucket deletion: %v", err))
+	}
+
+	return nil, deletionInProgress(task)
+}
+
+// retainRestored drops the queued deletion of a restored bucket, and reports the bucket as deleted
+// without deleting it.
+func (s *Server) retainRestored(ctx context.Context, log *slog.Logger, ref bucketRef) (*cosi.DriverDeleteBucketResponse, error) {
+	removed, err := s.deletions.Remove(ref.Region, ref.Label)
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to remove queued bucket deletion", "error", err)
+		return nil, status.Error(codes.Internal, fmt.Sprintf("failed to remove queued bucket deletion: %v", err))
+	}
+
+	// The running deletion finds the pending deletion marker removed, and retains the bucket.
+	if !removed {
+		return nil, status.Error(codes.Unavailable, fmt.Sprintf("%v: restored bucket is being retained", ErrBucketDeletionInProgress))
+	}
+
+	log.InfoContext(ctx, "Restored bucket retained")
+
+	return &cosi.DriverDeleteBucketResponse{}, status.Error(codes.OK, "restored bucket retained")
+}
+
+// endGracePeriod lifts the policy denying access to the soft-deleted bucket of the task, so
+// that it can be pruned. It reports whether the bucket was restored in the meantime. Frozen
+// buckets share the policy, so their deletion is refused until they are unfrozen.
+func (s *Server) endGracePeriod(ctx context.Context, log *slog.Logger, task deletion.Task) (bool, error) {
+	ref := bucketRef{Region: task.Region, Label: task.Label, EndpointType: task.EndpointType}
+
+	s3cli, cleanup, err := s.s3ClientForBucket(ctx, ref, linodeclient.KeyPermissionsReadWrite)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create bucket-scoped credentials: %w", err)
+	}
+	defer cleanupWithTimeout(ctx, log, cleanup)
+
+	tags, err := s3cli.GetBucketTags(ctx, task.Region, task.Label)
+	if s3.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get bucket tags: %w", err)
+	}
+
+	if _, pending, _ := pendingDeletion(tags); !pending {
+		return true, nil
+	}
+
+	// Buckets with a malformed marker are treated as frozen.
+	if frozenAt, frozen, _ := frozenSince(tags); frozen {
+		return false, fmt.Errorf("%w since %s, unfreeze it to delete it", ErrBucketFrozen, frozenAt.Format(time.RFC3339))
+	}
+
+	if err := updateBucketPolicy(ctx, s3cli, task.Region, task.Label, s3.AllowAll); err != nil {
+		return false, fmt.Errorf("failed to lift bucket access denial: %w", err)
+	}
+
+	return false, nil
+}
+
+// RestoreBucket cancels the pending deletion of a soft-deleted bucket, identified by its bucket ID
+// or an import reference. The policy denying all access is lifted, and the bucket is marked as
+// restored, so that it is retained. Keys revoked by the deletion are not restored.
+func (s *Server) RestoreBucket(ctx context.Context, id string) error {
+	ref, log, s3cli, cleanup, err := s.bucketForCommand(ctx, id, "RestoreBucket")
+	if err != nil {
+		return err
+	}
+	defer cleanupWithTimeout(ctx, log, cleanup)
+
+	tags, err := s3cli.GetBucketTags(ctx, ref.Region, ref.Label)
+	if err != nil {
+		return fmt.Errorf("failed to get bucket tags: %w", err)
+	}
+
+	deleteAfter, pending, err := pendingDeletion(tags)
+	if err != nil {
+		return err
+	}
+	if !pending {
+		return ErrNoPendingDeletion
+	}
+	if !time.Now().Before(deleteAfter) {
+		return fmt.Errorf("%w at %s", ErrGracePeriodExpired, deleteAfter.Format(time.RFC3339))
+	}
+
+	// Frozen buckets stay denied until they are unfrozen.
+	if _, frozen := tags[TagFrozen]; !frozen {
+		if err := updateBucketPolicy(ctx, s3cli, ref.Region, ref.Label, s3.AllowAll); err != nil {
+			return fmt.Errorf("failed to lift bucket access denial: %w", err)
+		}
+	}
+
+	delete(tags, TagDeleteAfter)
+	tags[TagRestored] = time.Now().UTC().Format(time.RFC3339)
+
+	if err := s3cli.SetBucketTags(ctx, ref.Region, ref.Label, tags); err != nil {
+		return fmt.Errorf("failed to set restored marker: %w", err)
+	}
+
+	log.InfoContext(ctx, "Bucket restored", slog.Time("delete_after", deleteAfter))
+
+	return nil
+}
+
+// adoptSoftDeleted refuses to adopt buckets pending deletion, and clears the marker of restored
+// buckets, so that they are soft-deleted again on their next deletion.
+func (s *Server) adoptSoftDeleted(ctx context.Context, log *slog.Logger, bucket *linodego.ObjectStorageBucket) error {
+	s3cli, cleanup, err := s.s3ClientForBucket(ctx, bucketRef{
+		Region:       bucket.Region,
+		Label:        bucket.Label,
+		EndpointType: bucket.EndpointType,
+	}, linodeclient.KeyPermissionsReadWrite)
+	if err != nil {
+		return status.Error(codes.Internal, fmt.Sprintf("failed to create bucket-scoped credentials: %v", err))
+	}
+	defer cleanupWithTimeout(ctx, log, cleanup)
+
+	tags, err := s3cli.GetBucketTags(ctx, bucket.Region, bucket.Label)
+	if err != nil {
+		return status.Error(codes.Internal, fmt.Sprintf("failed to get bucket tags: %v", err))
+	}
+
+	if deleteAfter, pending, _ := pendingDeletion(tags); pending {
+		return status.Error(codes.FailedPrecondition,
+			fmt.Sprintf("%v until %s, restore it first", ErrBucketPendingDeletion, deleteAfter.Format(time.RFC3339)))
+	}
+
+	if _, ok := tags[TagRestored]; !ok {
+		return nil
+	}
+
+	delete(tags, TagRestored)
+	if err := s3cli.SetBucketTags(ctx, bucket.Region, bucket.Label, tags); err != nil {
+		return status.Error(codes.Internal, fmt.Sprintf("failed to clear restored marker: %v", err))
+	}
+
+	log.InfoContext(ctx, "Restored bucket adopted")
+
+	return nil
+}
+
+// updateBucketPolicy replaces the bucket policy with the result of update.
+func updateBucketPolicy(
+	ctx context.Context,
+	s3cli s3.Client,
+	region, label string,
+	update func(policy string) (string, error),
+) error {
+	policy, err := s3cli.GetBucketPolicy(ctx, region, label)
+	if err != nil {
+		return err
+	}
+
+	updated, err := update(policy)
+	if err != nil {
+		return err
+	}
+
+	if updated == policy {
+		return nil
+	}
+
+	return s3cli.SetBucketPolicy(ctx, region, label, updated)
+}
@@ -0,0 +1,307 @@
+// Copyright 2025 Akamai Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provisioner_test
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/linode/linodego/v2"
+	"go.uber.org/mock/gomock"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	cosi "sigs.k8s.io/container-object-storage-interface-spec"
+
+	"github.com/linode/linode-cosi-driver/pkg/deletion"
+	"github.com/linode/linode-cosi-driver/pkg/linodeclient/cache"
+	"github.com/linode/linode-cosi-driver/pkg/s3"
+	"github.com/linode/linode-cosi-driver/pkg/servers/provisioner"
+	"github.com/linode/linode-cosi-driver/testing/mock"
+)
+
+const testBucketIDV2SoftDelete = "v2:label=" + testBucketName + "&region=" + testRegion + "&soft-delete=1h0m0s&type=E0"
+
+// fakeBucketMetadata records the tags and policy of a bucket set through the mock S3 client.
+type fakeBucketMetadata struct {
+	mu     sync.Mutex
+	tags   map[string]string
+	policy string
+}
+
+func (f *fakeBucketMetadata) expect(mockS3 *mock.MockS3Client) {
+	mockS3.EXPECT().
+		GetBucketTags(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		DoAndReturn(func(context.Context, string, string) (map[string]string, error) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			return maps.Clone(f.tags), nil
+		}).
+		AnyTimes()
+	mockS3.EXPECT().
+		SetBucketTags(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, tags map[string]string) error {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.tags = maps.Clone(tags)
+			return nil
+		}).
+		AnyTimes()
+	mockS3.EXPECT().
+		GetBucketPolicy(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		DoAndReturn(func(context.Context, string, string) (string, error) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			return f.policy, nil
+		}).
+		AnyTimes()
+	mockS3.EXPECT().
+		SetBucketPolicy(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, policy string) error {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.policy = policy
+			return nil
+		}).
+		AnyTimes()
+}
+
+func (f *fakeBucketMetadata) get() (map[string]string, string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return maps.Clone(f.tags), f.policy
+}
+
+func newSoftDeleteServer(t *testing.T, metadata *fakeBucketMetadata) (*provisioner.Server, *deletion.Queue, *mock.MockLinodeClient) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	mockLinode := mock.NewMockLinodeClient(ctrl)
+	mockLinode.EXPECT().
+		ListObjectStorageEndpoints(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageEndpoint{defaultLinodegoEndpoint}, nil).
+		AnyTimes()
+
+	mockS3 := mock.NewMockS3Client(ctrl)
+	metadata.expect(mockS3)
+
+	epc := cache.New(discardLog, mockLinode, 0)
+	if err := epc.Refresh(t.Context()); err != nil {
+		t.Fatalf("failed to refresh cache: %v", err)
+	}
+
+	queue := deletion.New(discardLog, &deletion.MemoryStore{})
+
+	srv, err := provisioner.New(nil, mockLinode, epc, mockS3, true, provisioner.WithDeletionQueue(queue))
+	if err != nil {
+		t.Fatalf("failed to create provisioner server: %v", err)
+	}
+
+	return srv, queue, mockLinode
+}
+
+func TestSoftDeletion(t *testing.T) {
+	t.Parallel()
+
+	const grantedKeyID = 10
+
+	metadata := &fakeBucketMetadata{
+		tags:   map[string]string{},
+		policy: `{"Statement":[{"Action":"s3:GetObject","Effect":"Allow","Principal":"*","Resource":"arn:aws:s3:::test-bucket/*"}],"Version":"2012-10-17"}`,
+	}
+	originalPolicy := metadata.policy
+
+	srv, queue, mockLinode := newSoftDeleteServer(t, metadata)
+
+	// Keys are revoked once, when the bucket is marked as pending deletion.
+	mockLinode.EXPECT().
+		ListObjectStorageKeys(gomock.Any(), gomock.Any()).
+		Return([]linodego.ObjectStorageKey{bucketKey(grantedKeyID, testBucketAccessName, testBucketName)}, nil)
+	mockLinode.EXPECT().
+		DeleteObjectStorageKey(gomock.Any(), gomock.Eq(grantedKeyID)).
+		Return(nil)
+
+	deleteRequest := &cosi.DriverDeleteBucketRequest{BucketId: testBucketIDV2SoftDelete}
+
+	for i := range 2 {
+		_, err := srv.DriverDeleteBucket(t.Context(), deleteRequest)
+		if status.Code(err) != grpccodes.Unavailable {
+			t.Fatalf("call %d: expected status code %q, but got %q: %v", i, grpccodes.Unavailable, status.Code(err), err)
+		}
+	}
+
+	tags, policy := metadata.get()
+	if _, ok := tags[provisioner.TagDeleteAfter]; !ok {
+		t.Fatalf("expected pending deletion marker, got tags %v", tags)
+	}
+	if !strings.Contains(policy, s3.DenyAllStatementID) {
+		t.Fatalf("expected policy denying all access, got %s", policy)
+	}
+
+	task, ok := queue.Get(testRegion, testBucketName)
+	if !ok || task.DeleteAfter.Before(time.Now().Add(50*time.Minute)) {
+		t.Fatalf("expected deletion to be queued after the grace period, got %+v", task)
+	}
+
+	if err := srv.RestoreBucket(t.Context(), testBucketIDV2SoftDelete); err != nil {
+		t.Fatalf("failed to restore bucket: %v", err)
+	}
+
+	tags, policy = metadata.get()
+	if _, ok := tags[provisioner.TagDeleteAfter]; ok {
+		t.Errorf("expected pending deletion marker to be removed, got tags %v", tags)
+	}
+	if policy != originalPolicy {
+		t.Errorf("expected original policy to be restored, got %s", policy)
+	}
+
+	if err := srv.RestoreBucket(t.Context(), testBucketIDV2SoftDelete); !errors.Is(err, provisioner.ErrNoPendingDeletion) {
+		t.Errorf("expected restoring a restored bucket to fail with %v, got %v", provisioner.ErrNoPendingDeletion, err)
+	}
+
+	// Deletions started after the restore retain the bucket.
+	if err := srv.ProcessDeletion(t.Context(), task, func(deletion.Task) {}); err != nil {
+		t.Errorf("expected deletion of restored bucket to be skipped, got %v", err)
+	}
+
+	// The restored bucket is retained, and its queued deletion dropped.
+	if _, err := srv.DriverDeleteBucket(t.Context(), deleteRequest); status.Code(err) != grpccodes.OK {
+		t.Fatalf("expected restored bucket to be retained, got %q: %v", status.Code(err), err)
+	}
+
+	if _, ok := queue.Get(testRegion, testBucketName); ok {
+		t.Errorf("expected queued deletion to be dropped")
+	}
+}
+
+func TestSoftDeletionAfterGracePeriod(t *testing.T) {
+	t.Parallel()
+
+	metadata := &fakeBucketMetadata{
+		tags: map[string]string{
+			provisioner.TagDeleteAfter: time.Now().Add(-time.Minute).UTC().Format(time.RFC3339),
+		},
+	}
+	metadata.policy, _ = s3.DenyAll("", testBucketName)
+
+	srv, _, mockLinode := newSoftDeleteServer(t, metadata)
+
+	mockLinode.EXPECT().
+		ListObjectStorageKeys(gomock.Any(), gomock.Any()).
+		Return(nil, nil)
+	mockLinode.EXPECT().
+		DeleteObjectStorageBucket(gomock.Any(), gomock.Eq(testRegion), gomock.Eq(testBucketName)).
+		Return(nil)
+
+	if err := srv.RestoreBucket(t.Context(), testBucketIDV2SoftDelete); !errors.Is(err, provisioner.ErrGracePeriodExpired) {
+		t.Errorf("expected restore after the grace period to fail with %v, got %v", provisioner.ErrGracePeriodExpired, err)
+	}
+
+	err := srv.ProcessDeletion(t.Context(), deletion.Task{
+		Region:       testRegion,
+		Label:        testBucketName,
+		EndpointType: linodego.ObjectStorageEndpointE0,
+		State:        deletion.StatePending,
+		DeleteAfter:  time.Now().Add(-time.Minute),
+	}, func(deletion.Task) {})
+	if err != nil {
+		t.Fatalf("failed to process deletion: %v", err)
+	}
+
+	if _, policy := metadata.get(); policy != "" {
+		t.Errorf("expected policy denying all access to be lifted before deletion, got %s", policy)
+	}
+}
+
+func TestSoftDeletionFrozen(t *testing.T) {
+	t.Parallel()
+
+	metadata := &fakeBucketMetadata{
+		tags: map[string]string{
+			provisioner.TagDeleteAfter: time.Now().Add(-time.Minute).UTC().Format(time.RFC3339),
+			provisioner.TagFrozen:      time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+		},
+	}
+	metadata.policy, _ = s3.DenyAll("", testBucketName)
+	deniedPolicy := metadata.policy
+
+	// No keys are revoked and the bucket is not deleted while it is frozen.
+	srv, _, _ := newSoftDeleteServer(t, metadata)
+
+	err := srv.ProcessDeletion(t.Context(), deletion.Task{
+		Region:       testRegion,
+		Label:        testBucketName,
+		EndpointType: linodego.ObjectStorageEndpointE0,
+		State:        deletion.StatePending,
+		DeleteAfter:  time.Now().Add(-time.Minute),
+	}, func(deletion.Task) {})
+	if !errors.Is(err, provisioner.ErrBucketFrozen) {
+		t.Fatalf("expected deletion of frozen bucket to fail with %v, got %v", provisioner.ErrBucketFrozen, err)
+	}
+
+	if _, policy := metadata.get(); policy != deniedPolicy {
+		t.Errorf("expected policy denying all access to be kept, got %s", policy)
+	}
+}
+
+func TestSoftDeletionWithoutQueue(t *testing.T) {
+	t.Parallel()
+
+	// Without a deletion queue, buckets with a soft delete period are refused rather than deleted right away.
+	ctrl := gomock.NewController(t)
+	srv, err := provisioner.New(discardLog, mock.NewMockLinodeClient(ctrl), nil, mock.NewMockS3Client(ctrl), true)
+	if err != nil {
+		t.Fatalf("failed to create provisioner server: %v", err)
+	}
+
+	_, err = srv.DriverCreateBucket(t.Context(), &cosi.DriverCreateBucketRequest{
+		Name: testBucketName,
+		Parameters: map[string]string{
+			provisioner.ParamRegion:           testRegion,
+			provisioner.ParamCleanup:          string(provisioner.ParamCleanupForce),
+			provisioner.ParamSoftDeletePeriod: "1h",
+		},
+	})
+	if status.Code(err) != grpccodes.FailedPrecondition {
+		t.Errorf("expected create to fail with %q, got %q: %v", grpccodes.FailedPrecondition, status.Code(err), err)
+	}
+
+	_, err = srv.DriverDeleteBucket(t.Context(), &cosi.DriverDeleteBucketRequest{BucketId: testBucketIDV2SoftDelete})
+	if status.Code(err) != grpccodes.FailedPrecondition {
+		t.Errorf("expected delete to fail with %q, got %q: %v", grpccodes.FailedPrecondition, status.Code(err), err)
+	}
+}
+
+func TestSoftDeletionRequiresCleanup(t *testing.T) {
+	t.Parallel()
+
+	srv, _, _ := newSoftDeleteServer(t, &fakeBucketMetadata{tags: map[string]string{}})
+
+	_, err := srv.DriverCreateBucket(t.Context(), &cosi.DriverCreateBucketRequest{
+		Name: testBucketName,
+		Parameters: map[string]string{
+			provisioner.ParamRegion:           testRegion,
+			provisioner.ParamSoftDeletePeriod: "1h",
+		},
+	})
+	if status.Code(err) != grpccodes.InvalidArgument || !strings.Contains(err.Error(), provisioner.ErrInvalidSoftDeletePeriod.Error()) {
+		t.Errorf("expected create to fail with %q, got %q: %v", grpccodes.InvalidArgument, status.Code(err), err)
+	}
+}
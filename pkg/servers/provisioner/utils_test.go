@@ -21,65 +21,6 @@ import (
 	cosi "sigs.k8s.io/container-object-storage-interface-spec"
 )
 
-func TestBucketIDCleanup(t *testing.T) {
-	t.Parallel()
-
-	tests := []struct {
-		name        string
-		cleanup     ParamCleanupValue
-		wantID      string
-		wantCleanup bool
-	}{
-		{name: "cleanup omitted", wantID: "pl-labkrk-2/rc-example"},
-		{
-			name:        "cleanup forced",
-			cleanup:     ParamCleanupForce,
-			wantID:      "pl-labkrk-2/rc-example/force",
-			wantCleanup: true,
-		},
-		{
-			name:    "unknown cleanup value",
-			cleanup: ParamCleanupValue("invalid"),
-			wantID:  "pl-labkrk-2/rc-example",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-
-			id := bucketID("pl-labkrk-2", "rc-example", tt.cleanup)
-			if id != tt.wantID {
-				t.Fatalf("expected bucket ID %q, got %q", tt.wantID, id)
-			}
-			region, label, cleanup, err := parseBucketID(id)
-			if err != nil {
-				t.Fatalf("expected valid bucket ID, got error: %v", err)
-			}
-			if region != "pl-labkrk-2" || label != "rc-example" {
-				t.Fatalf("expected bucket location pl-labkrk-2/rc-example, got %s/%s", region, label)
-			}
-			if cleanup != tt.wantCleanup {
-				t.Fatalf("expected cleanup forced to be %t, got %t", tt.wantCleanup, cleanup)
-			}
-		})
-	}
-}
-
-func TestParseBucketIDRejectsMalformedIDs(t *testing.T) {
-	t.Parallel()
-
-	for _, id := range []string{"", "region", "/label", "region/", "region/label/", "region/label/unknown", "region/label/force/extra"} {
-		t.Run(id, func(t *testing.T) {
-			t.Parallel()
-
-			if _, _, _, err := parseBucketID(id); err == nil {
-				t.Fatalf("expected bucket ID %q to be rejected", id)
-			}
-		})
-	}
-}
-
 const (
 	testCredentialsRegion    = "us-east"
 	testCredentialsEndpoint  = "us-east-1.linodeobjects.com"
@@ -312,6 +312,45 @@ func (c *MockLinodeClientGetObjectStorageKeyCall) DoAndReturn(f func(context.Con
 	return c
 }
 
+// ListObjectStorageBuckets mocks base method.
+func (m *MockLinodeClient) ListObjectStorageBuckets(arg0 context.Context, arg1 *linodego.ListOptions) ([]linodego.ObjectStorageBucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListObjectStorageBuckets", arg0, arg1)
+	ret0, _ := ret[0].([]linodego.ObjectStorageBucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListObjectStorageBuckets indicates an expected call of ListObjectStorageBuckets.
+func (mr *MockLinodeClientMockRecorder) ListObjectStorageBuckets(arg0, arg1 any) *MockLinodeClientListObjectStorageBucketsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListObjectStorageBuckets", reflect.TypeOf((*MockLinodeClient)(nil).ListObjectStorageBuckets), arg0, arg1)
+	return &MockLinodeClientListObjectStorageBucketsCall{Call: call}
+}
+
+// MockLinodeClientListObjectStorageBucketsCall wrap *gomock.Call
+type MockLinodeClientListObjectStorageBucketsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockLinodeClientListObjectStorageBucketsCall) Return(arg0 []linodego.ObjectStorageBucket, arg1 error) *MockLinodeClientListObjectStorageBucketsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockLinodeClientListObjectStorageBucketsCall) Do(f func(context.Context, *linodego.ListOptions) ([]linodego.ObjectStorageBucket, error)) *MockLinodeClientListObjectStorageBucketsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockLinodeClientListObjectStorageBucketsCall) DoAndReturn(f func(context.Context, *linodego.ListOptions) ([]linodego.ObjectStorageBucket, error)) *MockLinodeClientListObjectStorageBucketsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // ListObjectStorageEndpoints mocks base method.
 func (m *MockLinodeClient) ListObjectStorageEndpoints(arg0 context.Context, arg1 *linodego.ListOptions) ([]linodego.ObjectStorageEndpoint, error) {
 	m.ctrl.T.Helper()
@@ -390,6 +429,45 @@ func (c *MockLinodeClientListObjectStorageKeysCall) DoAndReturn(f func(context.C
 	return c
 }
 
+// ListRegions mocks base method.
+func (m *MockLinodeClient) ListRegions(arg0 context.Context, arg1 *linodego.ListOptions) ([]linodego.Region, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRegions", arg0, arg1)
+	ret0, _ := ret[0].([]linodego.Region)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRegions indicates an expected call of ListRegions.
+func (mr *MockLinodeClientMockRecorder) ListRegions(arg0, arg1 any) *MockLinodeClientListRegionsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRegions", reflect.TypeOf((*MockLinodeClient)(nil).ListRegions), arg0, arg1)
+	return &MockLinodeClientListRegionsCall{Call: call}
+}
+
+// MockLinodeClientListRegionsCall wrap *gomock.Call
+type MockLinodeClientListRegionsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockLinodeClientListRegionsCall) Return(arg0 []linodego.Region, arg1 error) *MockLinodeClientListRegionsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockLinodeClientListRegionsCall) Do(f func(context.Context, *linodego.ListOptions) ([]linodego.Region, error)) *MockLinodeClientListRegionsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockLinodeClientListRegionsCall) DoAndReturn(f func(context.Context, *linodego.ListOptions) ([]linodego.Region, error)) *MockLinodeClientListRegionsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // UpdateObjectStorageBucketAccess mocks base method.
 func (m *MockLinodeClient) UpdateObjectStorageBucketAccess(arg0 context.Context, arg1, arg2 string, arg3 linodego.ObjectStorageBucketUpdateAccessOptions) error {
 	m.ctrl.T.Helper()
@@ -11,8 +11,10 @@ package mock
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
+	minio "github.com/minio/minio-go/v7"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,6 +42,44 @@ func (m *MockS3Client) EXPECT() *MockS3ClientMockRecorder {
 	return m.recorder
 }
 
+// CopyObject mocks base method.
+func (m *MockS3Client) CopyObject(ctx context.Context, region, srcBucketName, srcKey, dstBucketName, dstKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyObject", ctx, region, srcBucketName, srcKey, dstBucketName, dstKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CopyObject indicates an expected call of CopyObject.
+func (mr *MockS3ClientMockRecorder) CopyObject(ctx, region, srcBucketName, srcKey, dstBucketName, dstKey any) *MockS3ClientCopyObjectCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyObject", reflect.TypeOf((*MockS3Client)(nil).CopyObject), ctx, region, srcBucketName, srcKey, dstBucketName, dstKey)
+	return &MockS3ClientCopyObjectCall{Call: call}
+}
+
+// MockS3ClientCopyObjectCall wrap *gomock.Call
+type MockS3ClientCopyObjectCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockS3ClientCopyObjectCall) Return(arg0 error) *MockS3ClientCopyObjectCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockS3ClientCopyObjectCall) Do(f func(context.Context, string, string, string, string, string) error) *MockS3ClientCopyObjectCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockS3ClientCopyObjectCall) DoAndReturn(f func(context.Context, string, string, string, string, string) error) *MockS3ClientCopyObjectCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // GetBucketPolicy mocks base method.
 func (m *MockS3Client) GetBucketPolicy(ctx context.Context, region, bucketName string) (string, error) {
 	m.ctrl.T.Helper()
@@ -79,18 +119,135 @@ func (c *MockS3ClientGetBucketPolicyCall) DoAndReturn(f func(context.Context, st
 	return c
 }
 
+// GetBucketTags mocks base method.
+func (m *MockS3Client) GetBucketTags(ctx context.Context, region, bucketName string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBucketTags", ctx, region, bucketName)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBucketTags indicates an expected call of GetBucketTags.
+func (mr *MockS3ClientMockRecorder) GetBucketTags(ctx, region, bucketName any) *MockS3ClientGetBucketTagsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBucketTags", reflect.TypeOf((*MockS3Client)(nil).GetBucketTags), ctx, region, bucketName)
+	return &MockS3ClientGetBucketTagsCall{Call: call}
+}
+
+// MockS3ClientGetBucketTagsCall wrap *gomock.Call
+type MockS3ClientGetBucketTagsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockS3ClientGetBucketTagsCall) Return(arg0 map[string]string, arg1 error) *MockS3ClientGetBucketTagsCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockS3ClientGetBucketTagsCall) Do(f func(context.Context, string, string) (map[string]string, error)) *MockS3ClientGetBucketTagsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockS3ClientGetBucketTagsCall) DoAndReturn(f func(context.Context, string, string) (map[string]string, error)) *MockS3ClientGetBucketTagsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// GetObject mocks base method.
+func (m *MockS3Client) GetObject(ctx context.Context, region, bucketName, key string) (io.ReadCloser, minio.ObjectInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetObject", ctx, region, bucketName, key)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(minio.ObjectInfo)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetObject indicates an expected call of GetObject.
+func (mr *MockS3ClientMockRecorder) GetObject(ctx, region, bucketName, key any) *MockS3ClientGetObjectCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetObject", reflect.TypeOf((*MockS3Client)(nil).GetObject), ctx, region, bucketName, key)
+	return &MockS3ClientGetObjectCall{Call: call}
+}
+
+// MockS3ClientGetObjectCall wrap *gomock.Call
+type MockS3ClientGetObjectCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockS3ClientGetObjectCall) Return(arg0 io.ReadCloser, arg1 minio.ObjectInfo, arg2 error) *MockS3ClientGetObjectCall {
+	c.Call = c.Call.Return(arg0, arg1, arg2)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockS3ClientGetObjectCall) Do(f func(context.Context, string, string, string) (io.ReadCloser, minio.ObjectInfo, error)) *MockS3ClientGetObjectCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockS3ClientGetObjectCall) DoAndReturn(f func(context.Context, string, string, string) (io.ReadCloser, minio.ObjectInfo, error)) *MockS3ClientGetObjectCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// ListObjects mocks base method.
+func (m *MockS3Client) ListObjects(ctx context.Context, region, bucketName, prefix string, fn func(minio.ObjectInfo) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListObjects", ctx, region, bucketName, prefix, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ListObjects indicates an expected call of ListObjects.
+func (mr *MockS3ClientMockRecorder) ListObjects(ctx, region, bucketName, prefix, fn any) *MockS3ClientListObjectsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListObjects", reflect.TypeOf((*MockS3Client)(nil).ListObjects), ctx, region, bucketName, prefix, fn)
+	return &MockS3ClientListObjectsCall{Call: call}
+}
+
+// MockS3ClientListObjectsCall wrap *gomock.Call
+type MockS3ClientListObjectsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockS3ClientListObjectsCall) Return(arg0 error) *MockS3ClientListObjectsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockS3ClientListObjectsCall) Do(f func(context.Context, string, string, string, func(minio.ObjectInfo) error) error) *MockS3ClientListObjectsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockS3ClientListObjectsCall) DoAndReturn(f func(context.Context, string, string, string, func(minio.ObjectInfo) error) error) *MockS3ClientListObjectsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
 // Prune mocks base method.
-func (m *MockS3Client) Prune(ctx context.Context, region, bucket string) error {
+func (m *MockS3Client) Prune(ctx context.Context, region, bucket string, progress func(int64, int64)) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Prune", ctx, region, bucket)
+	ret := m.ctrl.Call(m, "Prune", ctx, region, bucket, progress)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Prune indicates an expected call of Prune.
-func (mr *MockS3ClientMockRecorder) Prune(ctx, region, bucket any) *MockS3ClientPruneCall {
+func (mr *MockS3ClientMockRecorder) Prune(ctx, region, bucket, progress any) *MockS3ClientPruneCall {
 	mr.mock.ctrl.T.Helper()
-	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prune", reflect.TypeOf((*MockS3Client)(nil).Prune), ctx, region, bucket)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prune", reflect.TypeOf((*MockS3Client)(nil).Prune), ctx, region, bucket, progress)
 	return &MockS3ClientPruneCall{Call: call}
 }
 
@@ -106,13 +263,51 @@ func (c *MockS3ClientPruneCall) Return(arg0 error) *MockS3ClientPruneCall {
 }
 
 // Do rewrite *gomock.Call.Do
-func (c *MockS3ClientPruneCall) Do(f func(context.Context, string, string) error) *MockS3ClientPruneCall {
+func (c *MockS3ClientPruneCall) Do(f func(context.Context, string, string, func(int64, int64)) error) *MockS3ClientPruneCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockS3ClientPruneCall) DoAndReturn(f func(context.Context, string, string, func(int64, int64)) error) *MockS3ClientPruneCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// PutObject mocks base method.
+func (m *MockS3Client) PutObject(ctx context.Context, region, bucketName, key string, body io.Reader, info minio.ObjectInfo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutObject", ctx, region, bucketName, key, body, info)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutObject indicates an expected call of PutObject.
+func (mr *MockS3ClientMockRecorder) PutObject(ctx, region, bucketName, key, body, info any) *MockS3ClientPutObjectCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutObject", reflect.TypeOf((*MockS3Client)(nil).PutObject), ctx, region, bucketName, key, body, info)
+	return &MockS3ClientPutObjectCall{Call: call}
+}
+
+// MockS3ClientPutObjectCall wrap *gomock.Call
+type MockS3ClientPutObjectCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockS3ClientPutObjectCall) Return(arg0 error) *MockS3ClientPutObjectCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockS3ClientPutObjectCall) Do(f func(context.Context, string, string, string, io.Reader, minio.ObjectInfo) error) *MockS3ClientPutObjectCall {
 	c.Call = c.Call.Do(f)
 	return c
 }
 
 // DoAndReturn rewrite *gomock.Call.DoAndReturn
-func (c *MockS3ClientPruneCall) DoAndReturn(f func(context.Context, string, string) error) *MockS3ClientPruneCall {
+func (c *MockS3ClientPutObjectCall) DoAndReturn(f func(context.Context, string, string, string, io.Reader, minio.ObjectInfo) error) *MockS3ClientPutObjectCall {
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
@@ -154,3 +349,41 @@ func (c *MockS3ClientSetBucketPolicyCall) DoAndReturn(f func(context.Context, st
 	c.Call = c.Call.DoAndReturn(f)
 	return c
 }
+
+// SetBucketTags mocks base method.
+func (m *MockS3Client) SetBucketTags(ctx context.Context, region, bucketName string, tags map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBucketTags", ctx, region, bucketName, tags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBucketTags indicates an expected call of SetBucketTags.
+func (mr *MockS3ClientMockRecorder) SetBucketTags(ctx, region, bucketName, tags any) *MockS3ClientSetBucketTagsCall {
+	mr.mock.ctrl.T.Helper()
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBucketTags", reflect.TypeOf((*MockS3Client)(nil).SetBucketTags), ctx, region, bucketName, tags)
+	return &MockS3ClientSetBucketTagsCall{Call: call}
+}
+
+// MockS3ClientSetBucketTagsCall wrap *gomock.Call
+type MockS3ClientSetBucketTagsCall struct {
+	*gomock.Call
+}
+
+// Return rewrite *gomock.Call.Return
+func (c *MockS3ClientSetBucketTagsCall) Return(arg0 error) *MockS3ClientSetBucketTagsCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do rewrite *gomock.Call.Do
+func (c *MockS3ClientSetBucketTagsCall) Do(f func(context.Context, string, string, map[string]string) error) *MockS3ClientSetBucketTagsCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn rewrite *gomock.Call.DoAndReturn
+func (c *MockS3ClientSetBucketTagsCall) DoAndReturn(f func(context.Context, string, string, map[string]string) error) *MockS3ClientSetBucketTagsCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}